@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"math"
+	"runtime"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 	"vbom.ml/util/sortorder"
 
 	"github.com/prymitive/karma/internal/alertmanager"
@@ -18,17 +24,46 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func getFiltersFromQuery(filterStrings []string) ([]filters.FilterT, bool) {
+// filterLimit tracks the number of filter expressions parsed for a single
+// request, alternatives of OR filters are counted separately
+type filterLimit struct {
+	count int
+}
+
+// add returns an error once the number of parsed expressions is above the
+// filters.maxPerRequest limit, so callers can stop parsing
+func (fl *filterLimit) add(f filters.FilterT) error {
+	fl.count += filters.ExpressionCount(f)
+	if fl.count > config.Config.Filters.MaxPerRequest {
+		return fmt.Errorf("too many filters, only %d are allowed per request", config.Config.Filters.MaxPerRequest)
+	}
+	return nil
+}
+
+// getFiltersFromQuery parses all filter expressions, an error is returned
+// as soon as there are more filters than allowed, parsing stops if the
+// context is canceled
+func getFiltersFromQuery(ctx context.Context, filterStrings []string) ([]filters.FilterT, bool, error) {
+	limit := filterLimit{}
 	validFilters := false
 	matchFilters := []filters.FilterT{}
 	for _, filterExpression := range filterStrings {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
 		f := filters.NewFilter(filterExpression)
+		if err := limit.add(f); err != nil {
+			return nil, false, err
+		}
 		if f.GetIsValid() {
 			validFilters = true
+		} else {
+			log.Debugf("Invalid filter '%s': %s", filterExpression, f.GetInvalidReason())
+			filterParseErrors.Inc()
 		}
 		matchFilters = append(matchFilters, f)
 	}
-	return matchFilters, validFilters
+	return matchFilters, validFilters, nil
 }
 
 func countLabel(countStore map[string]map[string]int, key string, val string) {
@@ -42,7 +77,124 @@ func countLabel(countStore map[string]map[string]int, key string, val string) {
 	}
 }
 
-func countersToLabelStats(counters map[string]map[string]int) models.LabelNameStatsList {
+// parallelLabelCountThreshold is the minimal number of alerts for which label
+// counting will be split between multiple goroutines, for smaller sets the
+// overhead isn't worth it
+const parallelLabelCountThreshold = 10000
+
+// labelCountWorkers returns the number of goroutines that should be used to
+// count labels on given number of alerts
+func labelCountWorkers(alertCount int) int {
+	if alertCount < parallelLabelCountThreshold {
+		return 1
+	}
+	return runtime.NumCPU()
+}
+
+func countAlertLabels(countStore map[string]map[string]int, alert *models.Alert) {
+	countLabel(countStore, "@state", alert.State)
+	countLabel(countStore, "@receiver", alert.Receiver)
+	for key, value := range alert.Labels {
+		countLabel(countStore, key, value)
+	}
+}
+
+// countLabels returns the number of occurrences of every label value on given
+// alerts, if workers is more than 1 then alerts will be split into shards
+// counted in parallel and the results merged
+func countLabels(alerts []models.Alert, workers int) map[string]map[string]int {
+	counters := map[string]map[string]int{}
+
+	if workers <= 1 || len(alerts) < workers {
+		for i := range alerts {
+			countAlertLabels(counters, &alerts[i])
+		}
+		return counters
+	}
+
+	shardSize := (len(alerts) + workers - 1) / workers
+	results := make(chan map[string]map[string]int, workers)
+	shards := 0
+	for first := 0; first < len(alerts); first += shardSize {
+		last := first + shardSize
+		if last > len(alerts) {
+			last = len(alerts)
+		}
+		shards++
+		go func(shard []models.Alert) {
+			partial := map[string]map[string]int{}
+			for i := range shard {
+				countAlertLabels(partial, &shard[i])
+			}
+			results <- partial
+		}(alerts[first:last])
+	}
+
+	for i := 0; i < shards; i++ {
+		for key, values := range <-results {
+			if _, found := counters[key]; !found {
+				counters[key] = make(map[string]int, len(values))
+			}
+			for val, hits := range values {
+				counters[key][val] += hits
+			}
+		}
+	}
+	return counters
+}
+
+// crossTabLabelValue returns the value of given label on the alert, @state and
+// @receiver are supported the same way as in label counters
+func crossTabLabelValue(alert *models.Alert, name string) string {
+	switch name {
+	case "@state":
+		return alert.State
+	case "@receiver":
+		return alert.Receiver
+	default:
+		return alert.Labels[name]
+	}
+}
+
+// crossTabulateLabels counts alerts for every combination of row and column
+// label values, alerts missing any of those labels are counted with an empty
+// value so that the sum of all hits is always equal to the number of alerts
+func crossTabulateLabels(alerts []models.Alert, row, column string) models.LabelCrossTab {
+	counters := map[string]map[string]int{}
+	for i := range alerts {
+		countLabel(counters, crossTabLabelValue(&alerts[i], row), crossTabLabelValue(&alerts[i], column))
+	}
+
+	crossTab := models.LabelCrossTab{
+		Row:    row,
+		Column: column,
+		Rows:   models.LabelCrossTabRowList{},
+	}
+	for rowValue, columns := range counters {
+		crossTabRow := models.LabelCrossTabRow{
+			Value:   rowValue,
+			Columns: columns,
+		}
+		for _, hits := range columns {
+			crossTabRow.Hits += hits
+		}
+		crossTab.Hits += crossTabRow.Hits
+		crossTab.Rows = append(crossTab.Rows, crossTabRow)
+	}
+	sort.Sort(crossTab.Rows)
+
+	return crossTab
+}
+
+// otherLabelValue is used in label stats for values that didn't fit in the
+// maximum number of values per label name
+const otherLabelValue = "(other)"
+
+// countersToLabelStats converts label counters into stats with percentages,
+// if maxValues is > 0 then only that many values with the most hits are
+// returned for each label name and all remaining values are summed up as
+// otherLabelValue
+func countersToLabelStats(counters map[string]map[string]int, maxValues int) models.LabelNameStatsList {
 	data := models.LabelNameStatsList{}
 
 	for name, valueMap := range counters {
@@ -55,27 +207,56 @@ func countersToLabelStats(counters map[string]map[string]int) models.LabelNameSt
 			nameStats.Hits += hits
 			valueStats := models.LabelValueStats{
 				Value: value,
-				Raw:   fmt.Sprintf("%s=%s", name, value),
 				Hits:  hits,
 			}
 			nameStats.Values = append(nameStats.Values, valueStats)
 		}
+		sort.Sort(nameStats.Values)
+
+		// values are sorted by hits, so we can keep the first maxValues and
+		// sum up the rest, otherLabelValue is always the last value
+		if maxValues > 0 && len(nameStats.Values) > maxValues {
+			other := models.LabelValueStats{Value: otherLabelValue}
+			for _, value := range nameStats.Values[maxValues:] {
+				other.Hits += value.Hits
+			}
+			nameStats.Values = append(nameStats.Values[:maxValues], other)
+		}
+
+		// raw filter is only needed for values we return, otherLabelValue
+		// doesn't map to any filter so it's left empty
+		for i, value := range nameStats.Values {
+			if maxValues > 0 && i == maxValues {
+				break
+			}
+			nameStats.Values[i].Raw = fmt.Sprintf("%s=%s", name, value.Value)
+		}
 
 		// now that we have total hits we can calculate %
 		var totalPercent int
 		for i, value := range nameStats.Values {
-			nameStats.Values[i].Percent = int(math.Floor((float64(value.Hits) / float64(nameStats.Hits)) * 100.0))
+			nameStats.Values[i].Percent = value.Hits * 100 / nameStats.Hits
 			totalPercent += nameStats.Values[i].Percent
 		}
-		sort.Sort(nameStats.Values)
-		for totalPercent < 100 {
-			for i := range nameStats.Values {
-				nameStats.Values[i].Percent++
-				totalPercent++
-				if totalPercent >= 100 {
-					break
-				}
+
+		// % values are rounded down so they might not add up to 100, distribute
+		// what's left to values with the largest remainder, values with equal
+		// remainder will keep the order we just sorted them in
+		byRemainder := make([]int, len(nameStats.Values))
+		for i := range byRemainder {
+			byRemainder[i] = i
+		}
+		sort.SliceStable(byRemainder, func(i, j int) bool {
+			ri := nameStats.Values[byRemainder[i]].Hits * 100 % nameStats.Hits
+			rj := nameStats.Values[byRemainder[j]].Hits * 100 % nameStats.Hits
+			return ri > rj
+		})
+		for _, i := range byRemainder {
+			if totalPercent >= 100 {
+				break
 			}
+			nameStats.Values[i].Percent++
+			totalPercent++
 		}
 
 		// now that we have all % and values are sorted we can calculate offsets
@@ -84,6 +265,7 @@ func countersToLabelStats(counters map[string]map[string]int) models.LabelNameSt
 			nameStats.Values[i].Offset = offset
 			offset += value.Percent
 		}
+		nameStats.TotalValues = len(nameStats.Values)
 		data = append(data, nameStats)
 	}
 
@@ -92,11 +274,24 @@ func countersToLabelStats(counters map[string]map[string]int) models.LabelNameSt
 	return data
 }
 
+// sortAlertmanagers sorts Alertmanager instances by priority (highest first)
+// and then by name
+func sortAlertmanagers(upstreams []*alertmanager.Alertmanager) {
+	sort.Slice(upstreams, func(i, j int) bool {
+		if upstreams[i].Priority != upstreams[j].Priority {
+			return upstreams[i].Priority > upstreams[j].Priority
+		}
+		return upstreams[i].Name < upstreams[j].Name
+	})
+}
+
 func getUpstreams() models.AlertmanagerAPISummary {
 	summary := models.AlertmanagerAPISummary{}
 
 	clusters := map[string][]string{}
+	clusterHealth := map[string]models.AlertmanagerClusterSummary{}
 	upstreams := alertmanager.GetAlertmanagers()
+	sortAlertmanagers(upstreams)
 	for _, upstream := range upstreams {
 		members := upstream.ClusterMemberNames()
 		key, err := slices.StringSliceToSHA1(members)
@@ -109,14 +304,19 @@ func getUpstreams() models.AlertmanagerAPISummary {
 		}
 
 		u := models.AlertmanagerAPIStatus{
-			Name:           upstream.Name,
-			URI:            upstream.SanitizedURI(),
-			PublicURI:      upstream.PublicURI(),
-			Headers:        map[string]string{},
-			Error:          upstream.Error(),
-			Version:        upstream.Version(),
-			Cluster:        upstream.ClusterID(),
-			ClusterMembers: members,
+			Name:                    upstream.Name,
+			URI:                     upstream.SanitizedURI(),
+			PublicURI:               upstream.PublicURI(),
+			Headers:                 map[string]string{},
+			Error:                   upstream.Error(),
+			Version:                 upstream.Version(),
+			Cluster:                 upstream.ClusterID(),
+			ClusterMembers:          members,
+			ClusterPeerStatus:       upstream.ClusterPeerStatus(),
+			Timeout:                 upstream.RequestTimeout.Nanoseconds() / int64(time.Millisecond),
+			AlertCount:              upstream.AlertCount(),
+			LastCollectionDuration:  upstream.LastCollectionDuration().Nanoseconds() / int64(time.Millisecond),
+			LastCollectionTimestamp: upstream.LastCollectionTimestamp(),
 		}
 		if !upstream.ProxyRequests {
 			for k, v := range uri.HeadersForBasicAuth(u.PublicURI) {
@@ -125,21 +325,33 @@ func getUpstreams() models.AlertmanagerAPISummary {
 			for k, v := range upstream.HTTPHeaders {
 				u.Headers[k] = v
 			}
+			for k, v := range upstream.BasicAuthHeaders() {
+				u.Headers[k] = v
+			}
 		}
 		summary.Instances = append(summary.Instances, u)
 
 		summary.Counters.Total++
+		health := clusterHealth[key]
+		health.Total++
 		if u.Error == "" {
 			summary.Counters.Healthy++
+			health.Healthy++
 		} else {
 			summary.Counters.Failed++
+			health.Degraded = true
 		}
+		clusterHealth[key] = health
 	}
 	summary.Clusters = clusters
+	summary.ClusterHealth = clusterHealth
+	summary.Counters.RawTotal, summary.Counters.DedupedTotal, summary.Counters.Dropped = alertmanager.DedupStats()
 
 	return summary
 }
 
+// resolveLabelValue returns custom value used for sorting, exact value
+// mappings are tried first, then regex rules in the order they were configured
 func resolveLabelValue(name, value string) string {
 	valueReplacements, found := config.Config.Grid.Sorting.CustomValues.Labels[name]
 	if found {
@@ -147,22 +359,73 @@ func resolveLabelValue(name, value string) string {
 			return replacement
 		}
 	}
+	for _, rule := range config.Config.Grid.Sorting.CustomValues.Regex[name] {
+		if rule.CompiledRegex == nil {
+			continue
+		}
+		if match := rule.CompiledRegex.FindStringSubmatchIndex(value); match != nil {
+			return string(rule.CompiledRegex.ExpandString(nil, rule.Replacement, value, match))
+		}
+	}
 	return value
 }
 
-func getGroupLabel(group *models.APIAlertGroup, label string) string {
-	if v, found := group.Labels[label]; found {
-		return resolveLabelValue(label, v)
+// splitLabelChain returns a list of label names from a comma separated chain
+func splitLabelChain(chain string) []string {
+	labels := []string{}
+	for _, label := range strings.Split(chain, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
 	}
-	if v, found := group.Shared.Labels[label]; found {
-		return resolveLabelValue(label, v)
+	return labels
+}
+
+// getGroupLabel returns the value of the first label from a comma separated
+// chain of label names that is present on given group
+func getGroupLabel(group *models.APIAlertGroup, chain string) string {
+	for _, label := range splitLabelChain(chain) {
+		if v, found := models.LookupLabel(label, group.Labels, group.Shared.Labels, group.Alerts[0].Labels); found {
+			return resolveLabelValue(label, v)
+		}
 	}
-	if v, found := group.Alerts[0].Labels[label]; found {
-		return resolveLabelValue(label, v)
+	return ""
+}
+
+// getGroupAnnotation returns the value of given annotation used for sorting,
+// annotations shared by all alerts are checked first and if it's not there
+// then the first alert in the group is queried
+func getGroupAnnotation(group *models.APIAlertGroup, name string) string {
+	for _, annotations := range []models.Annotations{group.Shared.Annotations, group.Alerts[0].Annotations} {
+		for _, a := range annotations {
+			if a.Name == name {
+				return a.Value
+			}
+		}
 	}
 	return ""
 }
 
+// groupSeverityScore returns the severity score of an alert group, it's either
+// the highest or the sum of weights assigned to values of the severity label
+// on all alerts in the group, values without a weight count as 0
+func groupSeverityScore(group *models.APIAlertGroup, label, mode string, weights map[string]int) int {
+	var score int
+	for i := range group.Alerts {
+		v, found := models.LookupLabel(label, group.Labels, group.Shared.Labels, group.Alerts[i].Labels)
+		if !found {
+			continue
+		}
+		weight := weights[v]
+		if mode == "sum" {
+			score += weight
+		} else if weight > score {
+			score = weight
+		}
+	}
+	return score
+}
+
 func sortByStartsAt(i, j int, groups []models.APIAlertGroup, sortReverse bool) bool {
 	if sortReverse {
 		return groups[i].LatestStartsAt.After(groups[j].LatestStartsAt)
@@ -170,74 +433,591 @@ func sortByStartsAt(i, j int, groups []models.APIAlertGroup, sortReverse bool) b
 	return groups[i].LatestStartsAt.Before(groups[j].LatestStartsAt)
 }
 
-func sortAlertGroups(c *gin.Context, groupsMap map[string]models.APIAlertGroup) []models.APIAlertGroup {
-	groups := make([]models.APIAlertGroup, 0, len(groupsMap))
+func sortByFirstSeen(i, j int, groups []models.APIAlertGroup, sortReverse bool) bool {
+	if groups[i].LatestFirstSeen.Equal(groups[j].LatestFirstSeen) {
+		// both groups were first seen at the same time, fallback to timestamp sort
+		return sortByStartsAt(i, j, groups, sortReverse)
+	}
+	if sortReverse {
+		return groups[i].LatestFirstSeen.After(groups[j].LatestFirstSeen)
+	}
+	return groups[i].LatestFirstSeen.Before(groups[j].LatestFirstSeen)
+}
 
-	sortOrder, found := c.GetQuery("sortOrder")
-	if !found || sortOrder == "" {
-		sortOrder = config.Config.Grid.Sorting.Order
+func sortByEarliestStartsAt(i, j int, groups []models.APIAlertGroup, sortReverse bool) bool {
+	if groups[i].EarliestStartsAt.Equal(groups[j].EarliestStartsAt) {
+		// oldest alerts in both groups started at the same time, fallback to
+		// timestamp sort
+		return sortByStartsAt(i, j, groups, sortReverse)
+	}
+	if sortReverse {
+		return groups[i].EarliestStartsAt.After(groups[j].EarliestStartsAt)
 	}
+	return groups[i].EarliestStartsAt.Before(groups[j].EarliestStartsAt)
+}
 
-	sortReverse, found := c.GetQuery("sortReverse")
-	if !found || (sortReverse != "0" && sortReverse != "1") {
-		if config.Config.Grid.Sorting.Reverse {
-			sortReverse = "1"
-		} else {
-			sortReverse = "0"
+// labelValueIndex returns the position of given value in the custom value
+// order for label name, or -1 if there's no custom order for that value
+// If name is a chain of labels then the first custom order with this value
+// will be used
+func labelValueIndex(name, value string) int {
+	for _, label := range splitLabelChain(name) {
+		for i, v := range config.Config.Grid.Sorting.CustomValues.Order[label] {
+			if v == value {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// labelValueComparator returns a less function for comparing label values
+// using given collation, "natural" uses natural sort order, anything else is
+// parsed as a language tag and values are compared using collation rules for
+// that language with diacritics ignored
+// Collators are not safe for concurrent use so a new comparator should be
+// created for every sort
+func labelValueComparator(collation string) func(a, b string) bool {
+	if collation == "" || collation == "natural" {
+		return sortorder.NaturalLess
+	}
+	tag, err := language.Parse(collation)
+	if err != nil {
+		log.Errorf("Invalid collation '%s': %s", collation, err)
+		return sortorder.NaturalLess
+	}
+	collator := collate.New(tag, collate.IgnoreDiacritics, collate.Numeric)
+	return func(a, b string) bool {
+		return collator.CompareString(a, b) < 0
+	}
+}
+
+// sortByLabel compares label values of two groups, groups missing the label
+// are always placed at the end of the list (start when reversed)
+// If there's custom value order configured for this label then values from it
+// are placed before all other values, which are sorted using less
+func sortByLabel(name, vi, vj string, sortReverse bool, less func(a, b string) bool) bool {
+	if vi == "" {
+		// first label is missing
+		return sortReverse
+	}
+	if vj == "" {
+		// second label is missing
+		return !sortReverse
+	}
+	ii := labelValueIndex(name, vi)
+	ij := labelValueIndex(name, vj)
+	if ii >= 0 && ij >= 0 {
+		if sortReverse {
+			return ii > ij
+		}
+		return ii < ij
+	}
+	if ii >= 0 || ij >= 0 {
+		// only one value is present in the custom order
+		return (ii >= 0) != sortReverse
+	}
+	if sortReverse {
+		return less(vj, vi)
+	}
+	return less(vi, vj)
+}
+
+// sortByAnnotation compares annotation values of two groups, groups missing
+// the annotation are always placed at the end of the list (start when
+// reversed), same as with sortByLabel
+func sortByAnnotation(vi, vj string, sortReverse bool, less func(a, b string) bool) bool {
+	if vi == "" {
+		// first annotation is missing
+		return sortReverse
+	}
+	if vj == "" {
+		// second annotation is missing
+		return !sortReverse
+	}
+	if sortReverse {
+		return less(vj, vi)
+	}
+	return less(vi, vj)
+}
+
+// getView returns the view selected using view=name query arg, nil is
+// returned if there's no such arg and an error if there's no view with that
+// name
+func getView(c *gin.Context) (*config.ViewConfig, error) {
+	name, found := c.GetQuery("view")
+	if !found || name == "" {
+		return nil, nil
+	}
+	for i := range config.Config.Views {
+		if config.Config.Views[i].Name == name {
+			return &config.Config.Views[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown view '%s'", name)
+}
+
+// getFilterStrings returns filters passed using q query args, if there are
+// none then filters from the selected view are used
+func getFilterStrings(c *gin.Context) []string {
+	filterStrings := c.QueryArray("q")
+	if len(filterStrings) == 0 {
+		if view, err := getView(c); err == nil && view != nil {
+			filterStrings = view.Filters
+		}
+	}
+	return filterStrings
+}
+
+// getDefaultSortSettings returns sort settings from the config file, if the
+// view selected using query args has a sort order set then it will be used
+// instead
+func getDefaultSortSettings(c *gin.Context) models.GridSettings {
+	settings := models.GridSettings{
+		Order:          config.Config.Grid.Sorting.Order,
+		Reverse:        config.Config.Grid.Sorting.Reverse,
+		Label:          config.Config.Grid.Sorting.Label,
+		SecondaryLabel: config.Config.Grid.Sorting.SecondaryLabel,
+	}
+
+	if view, err := getView(c); err == nil && view != nil && view.Sorting.Order != "" {
+		settings.Order = view.Sorting.Order
+		settings.Reverse = view.Sorting.Reverse
+		if view.Sorting.Label != "" {
+			settings.Label = view.Sorting.Label
 		}
 	}
 
-	sortLabel, found := c.GetQuery("sortLabel")
-	if !found || sortLabel == "" {
-		sortLabel = config.Config.Grid.Sorting.Label
+	return settings
+}
+
+// getSortSettings returns sort settings resolved from query args, with
+// default values used for any arg that is missing or invalid
+func getSortSettings(c *gin.Context) models.GridSettings {
+	settings := getDefaultSortSettings(c)
+
+	if sortOrder, found := c.GetQuery("sortOrder"); found && sortOrder != "" {
+		settings.Order = sortOrder
+	}
+
+	if sortReverse, found := c.GetQuery("sortReverse"); found && (sortReverse == "0" || sortReverse == "1") {
+		settings.Reverse = sortReverse == "1"
+	}
+
+	if sortLabel, found := c.GetQuery("sortLabel"); found && sortLabel != "" {
+		settings.Label = sortLabel
+	}
+
+	if sortLabelSecondary, found := c.GetQuery("sortLabelSecondary"); found && sortLabelSecondary != "" {
+		settings.SecondaryLabel = sortLabelSecondary
+	}
+
+	if sortAnnotation, found := c.GetQuery("sortAnnotation"); found && sortAnnotation != "" {
+		settings.Annotation = sortAnnotation
 	}
 
+	return settings
+}
+
+// sortStats holds details about a single sortGroups call
+type sortStats struct {
+	comparisons int
+	duration    time.Duration
+}
+
+// sortAlertGroups returns all groups sorted using settings from the request,
+// an error is returned if the request context was canceled while sorting
+func sortAlertGroups(c *gin.Context, groupsMap map[string]models.APIAlertGroup) ([]models.APIAlertGroup, error) {
+	groups := make([]models.APIAlertGroup, 0, len(groupsMap))
 	for _, g := range groupsMap {
 		groups = append(groups, g)
 	}
 
-	switch sortOrder {
+	ctx := c.Request.Context()
+	settings := getSortSettings(c)
+	stats := sortGroups(ctx, groups, settings)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	order := sortOrderMetricLabel(settings.Order)
+	sortDuration.WithLabelValues(order).Observe(stats.duration.Seconds())
+	sortComparisons.WithLabelValues(order).Add(float64(stats.comparisons))
+
+	if pinFilter, found := c.GetQuery("pinFilter"); found && pinFilter != "" {
+		f := filters.NewFilter(pinFilter)
+		limit := filterLimit{}
+		if err := limit.add(f); err != nil {
+			return nil, err
+		}
+		if f.GetIsValid() {
+			groups = pinAlertGroups(groups, f)
+		} else {
+			log.Debugf("Invalid pin filter '%s': %s", pinFilter, f.GetInvalidReason())
+		}
+	}
+
+	return groups, nil
+}
+
+// pinAlertGroups moves all groups with at least one alert matching the filter
+// before all other groups, the order of groups within both partitions is kept
+func pinAlertGroups(groups []models.APIAlertGroup, f filters.FilterT) []models.APIAlertGroup {
+	pinned := make([]models.APIAlertGroup, 0, len(groups))
+	unpinned := make([]models.APIAlertGroup, 0, len(groups))
+	for _, ag := range groups {
+		var isPinned bool
+		for i := range ag.Alerts {
+			if f.Match(&ag.Alerts[i], 0) {
+				isPinned = true
+				break
+			}
+		}
+		if isPinned {
+			pinned = append(pinned, ag)
+		} else {
+			unpinned = append(unpinned, ag)
+		}
+	}
+	return append(pinned, unpinned...)
+}
+
+// sortOrderMetricLabel returns the value of the order label used for sort
+// metrics, unknown sort orders are reported as disabled since that's how
+// they are sorted, this keeps the number of label values bounded
+func sortOrderMetricLabel(order string) string {
+	if order == "annotation" || slices.StringInSlice(config.SortOrders, order) {
+		return order
+	}
+	return "disabled"
+}
+
+// sortCancelCheckInterval is the number of comparator calls between checks
+// for context cancellation when sorting groups
+const sortCancelCheckInterval = 1000
+
+// sortGroups sorts groups in place using given settings and returns the
+// number of comparator calls and the time it took to sort all groups, once
+// the context is canceled all remaining comparisons are skipped and groups
+// are left partially sorted
+func sortGroups(ctx context.Context, groups []models.APIAlertGroup, settings models.GridSettings) sortStats {
+	sortReverse := settings.Reverse
+	sortLabel := settings.Label
+	sortLabelSecondary := settings.SecondaryLabel
+
+	var less func(i, j int) bool
+	switch settings.Order {
 	case "startsAt":
-		sort.Slice(groups, func(i, j int) bool {
-			return sortByStartsAt(i, j, groups, sortReverse == "1")
-		})
+		less = func(i, j int) bool {
+			return sortByStartsAt(i, j, groups, sortReverse)
+		}
+	case "firstSeen":
+		less = func(i, j int) bool {
+			return sortByFirstSeen(i, j, groups, sortReverse)
+		}
+	case "oldestStartsAt":
+		less = func(i, j int) bool {
+			return sortByEarliestStartsAt(i, j, groups, sortReverse)
+		}
 	case "label":
-		sort.Slice(groups, func(i, j int) bool {
+		valueLess := labelValueComparator(config.Config.Grid.Sorting.Collation)
+		less = func(i, j int) bool {
 			vi := getGroupLabel(&groups[i], sortLabel)
 			vj := getGroupLabel(&groups[j], sortLabel)
 			if vi == "" && vj == "" {
 				// both groups lack this label, fallback to timestamp sort
 				return sortByStartsAt(i, j, groups, true)
 			}
-
-			if vi == "" {
-				// first label is missing
-				return sortReverse != "0"
-			}
-			if vj == "" {
-				// second label is missing
-				return sortReverse == "0"
-			}
 			if vi == vj {
-				// both labels are equal fallback to timestamp sort
+				// both labels are equal, use secondary label if it's set and
+				// it's value differs, fallback to timestamp sort otherwise
+				if sortLabelSecondary != "" {
+					si := getGroupLabel(&groups[i], sortLabelSecondary)
+					sj := getGroupLabel(&groups[j], sortLabelSecondary)
+					if si != sj {
+						return sortByLabel(sortLabelSecondary, si, sj, sortReverse, valueLess)
+					}
+				}
 				return sortByStartsAt(i, j, groups, true)
 			}
 			// finnally return groups sorted by label
-			if sortReverse == "1" {
-				return !sortorder.NaturalLess(vi, vj)
+			return sortByLabel(sortLabel, vi, vj, sortReverse, valueLess)
+		}
+	case "annotation":
+		valueLess := labelValueComparator(config.Config.Grid.Sorting.Collation)
+		sortAnnotation := settings.Annotation
+		less = func(i, j int) bool {
+			vi := getGroupAnnotation(&groups[i], sortAnnotation)
+			vj := getGroupAnnotation(&groups[j], sortAnnotation)
+			if vi == vj {
+				// both groups lack this annotation or have the same value,
+				// fallback to timestamp sort
+				return sortByStartsAt(i, j, groups, true)
 			}
-			return sortorder.NaturalLess(vi, vj)
-		})
+			return sortByAnnotation(vi, vj, sortReverse, valueLess)
+		}
+	case "alertCount":
+		less = func(i, j int) bool {
+			ci := len(groups[i].Alerts)
+			cj := len(groups[j].Alerts)
+			if ci == cj {
+				// both groups have the same number of alerts, fallback to timestamp sort
+				return sortByStartsAt(i, j, groups, true)
+			}
+			if sortReverse {
+				return ci > cj
+			}
+			return ci < cj
+		}
+	case "severityScore":
+		severity := config.Config.Grid.Sorting.SeverityScore
+		scores := make(map[string]int, len(groups))
+		for i := range groups {
+			scores[groups[i].ID] = groupSeverityScore(&groups[i], severity.Label, severity.Mode, severity.Weights)
+		}
+		less = func(i, j int) bool {
+			si := scores[groups[i].ID]
+			sj := scores[groups[j].ID]
+			if si == sj {
+				// both groups have the same score, fallback to timestamp sort
+				return sortByStartsAt(i, j, groups, true)
+			}
+			if sortReverse {
+				return si > sj
+			}
+			return si < sj
+		}
 	default:
 		// sort alert groups so they are always returned in the same order
 		// use group ID which is unique and immutable
-		sort.Slice(groups, func(i, j int) bool {
-			if sortReverse == "1" {
+		less = func(i, j int) bool {
+			if sortReverse {
 				return groups[i].ID < groups[j].ID
 			}
 			return groups[i].ID > groups[j].ID
-		})
+		}
 	}
 
+	stats := sortStats{}
+	start := time.Now()
+	var canceled bool
+	sort.Slice(groups, func(i, j int) bool {
+		if canceled {
+			return false
+		}
+		stats.comparisons++
+		if stats.comparisons%sortCancelCheckInterval == 0 && ctx.Err() != nil {
+			canceled = true
+			return false
+		}
+		return less(i, j)
+	})
+	stats.duration = time.Since(start)
+
+	return stats
+}
+
+// regroupLabels returns the labels of the group alerts with given label value
+// are moved to, alerts without that label are moved to a group with no labels
+// instead of a group with a made up label value, so silences and filters
+// created from group labels never match on a value that no alert has
+func regroupLabels(label, value string) map[string]string {
+	if value == "" {
+		return map[string]string{}
+	}
+	return map[string]string{label: value}
+}
+
+// regroupedGroupID returns the ID of the group alerts with given label value
+// are moved to, it depends only on the label name and value so it's stable
+// across requests
+func regroupedGroupID(label, value string) string {
+	ag := models.AlertGroup{Labels: regroupLabels(label, value)}
+	return ag.LabelsFingerprint()
+}
+
+// regroupAlertGroups moves all alerts into new groups keyed by the value of
+// given label, alerts without that label are placed in a group with no labels
+func regroupAlertGroups(groups []models.AlertGroup, label string) []models.AlertGroup {
+	regrouped := map[string]*models.AlertGroup{}
+	values := []string{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			value := alert.Labels[label]
+			group, found := regrouped[value]
+			if !found {
+				group = &models.AlertGroup{
+					Labels:            regroupLabels(label, value),
+					Alerts:            models.AlertList{},
+					AlertmanagerCount: map[string]int{},
+					StateCount:        map[string]int{},
+				}
+				for _, s := range models.AlertStateList {
+					group.StateCount[s] = 0
+				}
+				group.ID = regroupedGroupID(label, value)
+				regrouped[value] = group
+				values = append(values, value)
+			}
+			group.Alerts = append(group.Alerts, alert)
+			group.StateCount[alert.State]++
+			for _, am := range alert.Alertmanager {
+				group.AlertmanagerCount[am.Name]++
+			}
+		}
+	}
+
+	result := make([]models.AlertGroup, 0, len(values))
+	for _, value := range values {
+		result = append(result, *regrouped[value])
+	}
+	return result
+}
+
+// splitGroupLabels returns the labels of the group created when alerts with
+// given label value are split out of given group
+func splitGroupLabels(ag models.AlertGroup, label, value string) map[string]string {
+	labels := make(map[string]string, len(ag.Labels)+1)
+	for k, v := range ag.Labels {
+		labels[k] = v
+	}
+	labels[label] = value
+	return labels
+}
+
+// splitGroupID returns the ID of the group created when alerts with given
+// label value are split out of given group, it depends only on the group
+// receiver and labels so it's stable across requests
+func splitGroupID(ag models.AlertGroup, label, value string) string {
+	split := models.AlertGroup{Receiver: ag.Receiver, Labels: splitGroupLabels(ag, label, value)}
+	return split.LabelsFingerprint()
+}
+
+// splitAlertGroups subdivides every group using the value of given label, all
+// alerts with the same value are moved to a new group, alerts without that
+// label stay in the original group
+// Groups that already use that label for grouping are left unchanged
+func splitAlertGroups(groups []models.AlertGroup, label string) []models.AlertGroup {
+	newGroup := func(id, receiver string, labels map[string]string) *models.AlertGroup {
+		group := &models.AlertGroup{
+			ID:                id,
+			Receiver:          receiver,
+			Labels:            labels,
+			Alerts:            models.AlertList{},
+			AlertmanagerCount: map[string]int{},
+			StateCount:        map[string]int{},
+		}
+		for _, s := range models.AlertStateList {
+			group.StateCount[s] = 0
+		}
+		return group
+	}
+
+	result := make([]models.AlertGroup, 0, len(groups))
+	for _, ag := range groups {
+		if _, found := ag.Labels[label]; found {
+			result = append(result, ag)
+			continue
+		}
+
+		original := newGroup(ag.ID, ag.Receiver, ag.Labels)
+		split := map[string]*models.AlertGroup{}
+		values := []string{}
+		for _, alert := range ag.Alerts {
+			group := original
+			if value := alert.Labels[label]; value != "" {
+				var found bool
+				group, found = split[value]
+				if !found {
+					group = newGroup(splitGroupID(ag, label, value), ag.Receiver, splitGroupLabels(ag, label, value))
+					split[value] = group
+					values = append(values, value)
+				}
+			}
+			group.Alerts = append(group.Alerts, alert)
+			group.StateCount[alert.State]++
+			for _, am := range alert.Alertmanager {
+				group.AlertmanagerCount[am.Name]++
+			}
+		}
+
+		if len(original.Alerts) > 0 {
+			result = append(result, *original)
+		}
+		sort.Strings(values)
+		for _, value := range values {
+			result = append(result, *split[value])
+		}
+	}
+	return result
+}
+
+// paginateAlertGroups returns a slice of sorted alert groups selected using
+// limit & offset query args, invalid values are ignored
+// Offset outside of the list will return an empty slice
+func paginateAlertGroups(c *gin.Context, groups []models.APIAlertGroup) []models.APIAlertGroup {
+	offset, err := strconv.Atoi(c.Query("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	if offset > len(groups) {
+		offset = len(groups)
+	}
+
+	end := len(groups)
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err == nil && limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	return groups[offset:end]
+}
+
+// truncateAlertGroups limits the number of alerts in each group to the value
+// of maxAlertsPerGroup query arg, alerts are already sorted by StartsAt and
+// fingerprint so the same subset is always returned
+// Only the alerts slice is truncated, all counters still reflect all alerts
+func truncateAlertGroups(c *gin.Context, groups []models.APIAlertGroup) []models.APIAlertGroup {
+	maxAlerts, err := strconv.Atoi(c.Query("maxAlertsPerGroup"))
+	if err != nil || maxAlerts <= 0 {
+		return groups
+	}
+
+	for i := range groups {
+		if len(groups[i].Alerts) > maxAlerts {
+			groups[i].TruncatedAlerts = len(groups[i].Alerts) - maxAlerts
+			groups[i].Alerts = groups[i].Alerts[:maxAlerts]
+		}
+	}
 	return groups
 }
+
+// paginateLabelStats returns only a page of values for every label name, using
+// statsOffset and statsLimit query args, percent and offset of every value are
+// not changed so they are still computed against all values
+func paginateLabelStats(c *gin.Context, stats models.LabelNameStatsList) models.LabelNameStatsList {
+	offset, err := strconv.Atoi(c.Query("statsOffset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	limit, err := strconv.Atoi(c.Query("statsLimit"))
+	if err != nil || limit < 0 {
+		limit = 0
+	}
+	if offset == 0 && limit == 0 {
+		return stats
+	}
+
+	for i := range stats {
+		start := offset
+		if start > len(stats[i].Values) {
+			start = len(stats[i].Values)
+		}
+		end := len(stats[i].Values)
+		if limit > 0 && start+limit < end {
+			end = start + limit
+		}
+		stats[i].Values = stats[i].Values[start:end]
+	}
+	return stats
+}
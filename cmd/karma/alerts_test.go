@@ -0,0 +1,1175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
+	cache "github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/prymitive/karma/internal/alertmanager"
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/mock"
+	"github.com/prymitive/karma/internal/models"
+)
+
+type labelStatsTest struct {
+	counters map[string]int
+	percent  map[string]int
+}
+
+var labelStatsTests = []labelStatsTest{
+	{
+		counters: map[string]int{"a": 1},
+		percent:  map[string]int{"a": 100},
+	},
+	{
+		counters: map[string]int{"a": 1, "b": 1, "c": 1},
+		percent:  map[string]int{"a": 34, "b": 33, "c": 33},
+	},
+	{
+		counters: map[string]int{"a": 5, "b": 1},
+		percent:  map[string]int{"a": 83, "b": 17},
+	},
+	{
+		counters: map[string]int{"a": 3, "b": 2, "c": 2},
+		percent:  map[string]int{"a": 43, "b": 29, "c": 28},
+	},
+	{
+		counters: map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1, "f": 1, "g": 1},
+		percent:  map[string]int{"a": 15, "b": 15, "c": 14, "d": 14, "e": 14, "f": 14, "g": 14},
+	},
+	{
+		counters: map[string]int{"a": 10, "b": 1, "c": 1, "d": 1},
+		percent:  map[string]int{"a": 77, "b": 8, "c": 8, "d": 7},
+	},
+}
+
+func TestCountersToLabelStats(t *testing.T) {
+	for _, testCase := range labelStatsTests {
+		stats := countersToLabelStats(map[string]map[string]int{"foo": testCase.counters}, 0)
+		if len(stats) != 1 {
+			t.Errorf("Expected 1 label stats entry, got %d", len(stats))
+			continue
+		}
+
+		var totalPercent, offset int
+		percent := map[string]int{}
+		for _, value := range stats[0].Values {
+			if value.Offset != offset {
+				t.Errorf("Invalid offset for '%s', expected %d, got %d", value.Value, offset, value.Offset)
+			}
+			offset += value.Percent
+			totalPercent += value.Percent
+			percent[value.Value] = value.Percent
+		}
+		if totalPercent != 100 {
+			t.Errorf("Percent sum is != 100: %d for %v", totalPercent, testCase.counters)
+		}
+		if diff := cmp.Diff(testCase.percent, percent); diff != "" {
+			t.Errorf("Incorrect percent values (-want +got):\n%s", diff)
+		}
+	}
+}
+
+type labelStatsMaxValuesTest struct {
+	counters  map[string]int
+	maxValues int
+	values    []string
+	hits      []int
+}
+
+var labelStatsMaxValuesTests = []labelStatsMaxValuesTest{
+	{
+		counters:  map[string]int{"a": 10, "b": 5, "c": 3, "d": 1, "e": 1},
+		maxValues: 2,
+		values:    []string{"a", "b", otherLabelValue},
+		hits:      []int{10, 5, 5},
+	},
+	{
+		counters:  map[string]int{"a": 1, "b": 1, "c": 1, "d": 1},
+		maxValues: 1,
+		values:    []string{"a", otherLabelValue},
+		hits:      []int{1, 3},
+	},
+	{
+		counters:  map[string]int{"a": 3, "b": 2, "c": 1},
+		maxValues: 3,
+		values:    []string{"a", "b", "c"},
+		hits:      []int{3, 2, 1},
+	},
+	{
+		counters:  map[string]int{"a": 3, "b": 2, "c": 1},
+		maxValues: 10,
+		values:    []string{"a", "b", "c"},
+		hits:      []int{3, 2, 1},
+	},
+	{
+		counters:  map[string]int{"a": 3, "b": 2, "c": 1},
+		maxValues: 0,
+		values:    []string{"a", "b", "c"},
+		hits:      []int{3, 2, 1},
+	},
+}
+
+func TestCountersToLabelStatsMaxValues(t *testing.T) {
+	for _, testCase := range labelStatsMaxValuesTests {
+		stats := countersToLabelStats(map[string]map[string]int{"foo": testCase.counters}, testCase.maxValues)
+		if len(stats) != 1 {
+			t.Errorf("Expected 1 label stats entry, got %d", len(stats))
+			continue
+		}
+
+		values := []string{}
+		hits := []int{}
+		var totalPercent, offset int
+		for _, value := range stats[0].Values {
+			values = append(values, value.Value)
+			hits = append(hits, value.Hits)
+			raw := "foo=" + value.Value
+			if value.Value == otherLabelValue {
+				raw = ""
+			}
+			if value.Raw != raw {
+				t.Errorf("Invalid raw filter for '%s', expected %q, got %q", value.Value, raw, value.Raw)
+			}
+			if value.Offset != offset {
+				t.Errorf("Invalid offset for '%s', expected %d, got %d", value.Value, offset, value.Offset)
+			}
+			offset += value.Percent
+			totalPercent += value.Percent
+		}
+		if totalPercent != 100 {
+			t.Errorf("Percent sum is != 100: %d for %v", totalPercent, testCase.counters)
+		}
+		if diff := cmp.Diff(testCase.values, values); diff != "" {
+			t.Errorf("Wrong values for %v with maxValues=%d (-want +got):\n%s", testCase.counters, testCase.maxValues, diff)
+		}
+		if diff := cmp.Diff(testCase.hits, hits); diff != "" {
+			t.Errorf("Wrong hits for %v with maxValues=%d (-want +got):\n%s", testCase.counters, testCase.maxValues, diff)
+		}
+	}
+}
+
+var paginateLabelStatsTests = []struct {
+	query   string
+	values  []string
+	percent []int
+	offset  []int
+}{
+	{
+		query:   "",
+		values:  []string{"a", "b", "c", "d"},
+		percent: []int{40, 30, 20, 10},
+		offset:  []int{0, 40, 70, 90},
+	},
+	{
+		query:   "statsLimit=2",
+		values:  []string{"a", "b"},
+		percent: []int{40, 30},
+		offset:  []int{0, 40},
+	},
+	{
+		query:   "statsLimit=2&statsOffset=1",
+		values:  []string{"b", "c"},
+		percent: []int{30, 20},
+		offset:  []int{40, 70},
+	},
+	{
+		query:   "statsOffset=3",
+		values:  []string{"d"},
+		percent: []int{10},
+		offset:  []int{90},
+	},
+	{
+		query:   "statsLimit=10&statsOffset=5",
+		values:  []string{},
+		percent: []int{},
+		offset:  []int{},
+	},
+	{
+		query:   "statsLimit=foo&statsOffset=-1",
+		values:  []string{"a", "b", "c", "d"},
+		percent: []int{40, 30, 20, 10},
+		offset:  []int{0, 40, 70, 90},
+	},
+}
+
+func TestPaginateLabelStats(t *testing.T) {
+	counters := map[string]map[string]int{"foo": {"a": 4, "b": 3, "c": 2, "d": 1}}
+	for _, testCase := range paginateLabelStatsTests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		stats := paginateLabelStats(c, countersToLabelStats(counters, 0))
+		if len(stats) != 1 {
+			t.Errorf("[%s] Expected 1 label stats entry, got %d", testCase.query, len(stats))
+			continue
+		}
+		if stats[0].TotalValues != 4 {
+			t.Errorf("[%s] Expected totalValues=4, got %d", testCase.query, stats[0].TotalValues)
+		}
+
+		values := []string{}
+		percent := []int{}
+		offset := []int{}
+		for _, value := range stats[0].Values {
+			values = append(values, value.Value)
+			percent = append(percent, value.Percent)
+			offset = append(offset, value.Offset)
+		}
+		if diff := cmp.Diff(testCase.values, values); diff != "" {
+			t.Errorf("[%s] Wrong values (-want +got):\n%s", testCase.query, diff)
+		}
+		if diff := cmp.Diff(testCase.percent, percent); diff != "" {
+			t.Errorf("[%s] Wrong percent (-want +got):\n%s", testCase.query, diff)
+		}
+		if diff := cmp.Diff(testCase.offset, offset); diff != "" {
+			t.Errorf("[%s] Wrong offset (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func BenchmarkCountersToLabelStatsMaxValues(b *testing.B) {
+	counters := map[string]map[string]int{"instance": {}}
+	for i := 0; i < 10000; i++ {
+		counters["instance"][fmt.Sprintf("server%d", i)] = i%7 + 1
+	}
+	for _, maxValues := range []int{0, 10} {
+		b.Run(fmt.Sprintf("maxValues=%d", maxValues), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				countersToLabelStats(counters, maxValues)
+			}
+		})
+	}
+}
+
+func TestFilterParseErrorsMetric(t *testing.T) {
+	type filterParseErrorsTest struct {
+		filters []string
+		errors  float64
+	}
+	filterParseErrorsTests := []filterParseErrorsTest{
+		{filters: []string{}, errors: 0},
+		{filters: []string{"foo=bar", "@state=active"}, errors: 0},
+		{filters: []string{"foo="}, errors: 1},
+		{filters: []string{"foo=", "foo=bar", "@state=foo", "@limit=abc"}, errors: 3},
+	}
+
+	mockConfig()
+	for _, testCase := range filterParseErrorsTests {
+		before := testutil.ToFloat64(filterParseErrors)
+		getFiltersFromQuery(context.Background(), testCase.filters)
+		after := testutil.ToFloat64(filterParseErrors)
+		if after-before != testCase.errors {
+			t.Errorf("karma_filter_parse_errors_total increased by %v for %v, expected %v", after-before, testCase.filters, testCase.errors)
+		}
+	}
+}
+
+func TestGetUpstreamsClustersOrder(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		mockAlerts(version)
+
+		// encoding/json sorts map keys, so the same clusters must always
+		// serialize to the same output
+		outputs := []string{}
+		for i := 0; i < 5; i++ {
+			summary := getUpstreams()
+			b, err := json.Marshal(summary.Clusters)
+			if err != nil {
+				t.Errorf("[%s] Failed to marshal clusters: %s", version, err)
+			}
+			outputs = append(outputs, string(b))
+		}
+		for _, out := range outputs[1:] {
+			if out != outputs[0] {
+				t.Errorf("[%s] Clusters serialization differs between calls: %s != %s", version, out, outputs[0])
+			}
+		}
+	}
+}
+
+func TestGetUpstreamsClusterHealthAllFailed(t *testing.T) {
+	mockConfig()
+	apiCache = cache.New(cache.NoExpiration, 10*time.Second)
+
+	// no responders are registered so every request fails
+	httpmock.Activate()
+	pullFromAlertmanager()
+	httpmock.DeactivateAndReset()
+
+	summary := getUpstreams()
+	if summary.Counters.Total == 0 || summary.Counters.Failed != summary.Counters.Total {
+		t.Errorf("Expected all upstreams to fail, got counters %+v", summary.Counters)
+	}
+	if len(summary.ClusterHealth) != len(summary.Clusters) {
+		t.Errorf("Got %d cluster health entries, expected %d", len(summary.ClusterHealth), len(summary.Clusters))
+	}
+	for cluster, health := range summary.ClusterHealth {
+		expected := models.AlertmanagerClusterSummary{Total: len(summary.Clusters[cluster]), Healthy: 0, Degraded: true}
+		if diff := cmp.Diff(expected, health); diff != "" {
+			t.Errorf("Wrong health for cluster %s (-want +got):\n%s", cluster, diff)
+		}
+	}
+}
+
+func TestSortAlertmanagers(t *testing.T) {
+	type upstream struct {
+		name     string
+		priority int
+	}
+	upstreams := []upstream{
+		{name: "c", priority: 0},
+		{name: "b", priority: 0},
+		{name: "prod2", priority: 10},
+		{name: "staging", priority: 5},
+		{name: "a", priority: 0},
+		{name: "prod1", priority: 10},
+		{name: "dev", priority: -1},
+	}
+	expected := []string{"prod1", "prod2", "staging", "a", "b", "c", "dev"}
+
+	for i := 0; i < 5; i++ {
+		ams := []*alertmanager.Alertmanager{}
+		for j := range upstreams {
+			// rotate the input so every run starts with a different order
+			u := upstreams[(i+j)%len(upstreams)]
+			am, err := alertmanager.NewAlertmanager(u.name, "http://"+u.name, alertmanager.WithPriority(u.priority))
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+			ams = append(ams, am)
+		}
+		sortAlertmanagers(ams)
+		names := []string{}
+		for _, am := range ams {
+			names = append(names, am.Name)
+		}
+		if diff := cmp.Diff(expected, names); diff != "" {
+			t.Errorf("Wrong Alertmanager order (-want +got):\n%s", diff)
+		}
+	}
+}
+
+type resolveLabelValueTest struct {
+	name     string
+	value    string
+	resolved string
+}
+
+var resolveLabelValueTests = []resolveLabelValueTest{
+	{name: "severity", value: "critical", resolved: "1"},
+	{name: "severity", value: "info", resolved: "info"},
+	{name: "pod", value: "web-5d8f-12", resolved: "12-web"},
+	{name: "pod", value: "api-1", resolved: "api-1"},
+	{name: "pod", value: "web-special", resolved: "0"},
+	{name: "pod", value: "db-2", resolved: "2"},
+	{name: "cluster", value: "web-5d8f-12", resolved: "web-5d8f-12"},
+}
+
+func TestResolveLabelValue(t *testing.T) {
+	config.Config.Grid.Sorting.CustomValues.Labels = map[string]map[string]string{
+		"severity": {"critical": "1"},
+		"pod":      {"web-special": "0"},
+	}
+	config.Config.Grid.Sorting.CustomValues.Regex = config.CustomLabelValueRules{
+		"pod": []config.CustomLabelValueRule{
+			{
+				ValueRegex:    "^web-.*-([0-9]+)$",
+				CompiledRegex: regexp.MustCompile("^web-.*-([0-9]+)$"),
+				Replacement:   "$1-web",
+			},
+			{
+				ValueRegex:    "^web-.*$",
+				CompiledRegex: regexp.MustCompile("^web-.*$"),
+				Replacement:   "web",
+			},
+			{
+				ValueRegex:    "^db-([0-9]+)$",
+				CompiledRegex: regexp.MustCompile("^db-([0-9]+)$"),
+				Replacement:   "${1}",
+			},
+		},
+	}
+	defer func() {
+		config.Config.Grid.Sorting.CustomValues.Labels = map[string]map[string]string{}
+		config.Config.Grid.Sorting.CustomValues.Regex = config.CustomLabelValueRules{}
+	}()
+
+	for _, testCase := range resolveLabelValueTests {
+		resolved := resolveLabelValue(testCase.name, testCase.value)
+		if resolved != testCase.resolved {
+			t.Errorf("resolveLabelValue(%s, %s) returned '%s', expected '%s'", testCase.name, testCase.value, resolved, testCase.resolved)
+		}
+	}
+}
+
+func generateAlerts(count int) []models.Alert {
+	alerts := make([]models.Alert, count)
+	for i := 0; i < count; i++ {
+		alerts[i] = models.Alert{
+			State:    models.AlertStateList[i%len(models.AlertStateList)],
+			Receiver: fmt.Sprintf("receiver%d", i%3),
+			Labels: map[string]string{
+				"alertname": fmt.Sprintf("alert%d", i%50),
+				"instance":  fmt.Sprintf("instance%d", i%1000),
+				"cluster":   fmt.Sprintf("cluster%d", i%5),
+				"job":       fmt.Sprintf("job%d", i%20),
+				"severity":  fmt.Sprintf("severity%d", i%4),
+			},
+		}
+	}
+	return alerts
+}
+
+func TestCountLabels(t *testing.T) {
+	for _, count := range []int{0, 1, 7, 100, 10001} {
+		alerts := generateAlerts(count)
+		serial := countLabels(alerts, 1)
+		for _, workers := range []int{2, 3, 8, 64} {
+			parallel := countLabels(alerts, workers)
+			if diff := cmp.Diff(serial, parallel); diff != "" {
+				t.Errorf("Counters mismatch for %d alert(s) using %d worker(s) (-serial +parallel):\n%s", count, workers, diff)
+			}
+		}
+		if count > 0 && serial["@receiver"]["receiver0"] != (count+2)/3 {
+			t.Errorf("Got %d hits for @receiver=receiver0 using %d alert(s), expected %d", serial["@receiver"]["receiver0"], count, (count+2)/3)
+		}
+	}
+}
+
+func TestCrossTabulateLabels(t *testing.T) {
+	alerts := generateAlerts(100)
+	// some alerts are missing the column label
+	for i := 0; i < len(alerts); i += 10 {
+		delete(alerts[i].Labels, "severity")
+	}
+
+	crossTab := crossTabulateLabels(alerts, "cluster", "severity")
+	if crossTab.Row != "cluster" || crossTab.Column != "severity" {
+		t.Errorf("Got row=%q column=%q, expected row=cluster column=severity", crossTab.Row, crossTab.Column)
+	}
+	if crossTab.Hits != len(alerts) {
+		t.Errorf("Got %d total hits, expected %d", crossTab.Hits, len(alerts))
+	}
+	if len(crossTab.Rows) != 5 {
+		t.Errorf("Got %d rows, expected 5", len(crossTab.Rows))
+	}
+
+	var total int
+	for i, row := range crossTab.Rows {
+		var rowHits int
+		for _, hits := range row.Columns {
+			rowHits += hits
+		}
+		if rowHits != row.Hits {
+			t.Errorf("Row %s has %d hits but columns sum up to %d", row.Value, row.Hits, rowHits)
+		}
+		if i > 0 && crossTab.Rows[i-1].Hits < row.Hits {
+			t.Errorf("Row %s with %d hits is sorted after %s with %d hits", row.Value, row.Hits, crossTab.Rows[i-1].Value, crossTab.Rows[i-1].Hits)
+		}
+		total += row.Hits
+	}
+	if total != len(alerts) {
+		t.Errorf("Rows sum up to %d hits, expected %d", total, len(alerts))
+	}
+
+	// every 10th alert is in cluster0 and has no severity label
+	for _, row := range crossTab.Rows {
+		if row.Value == "cluster0" && row.Columns[""] != 10 {
+			t.Errorf("Got %d hits for cluster0 without severity, expected 10", row.Columns[""])
+		}
+	}
+
+	crossTab = crossTabulateLabels(alerts, "@state", "@receiver")
+	if crossTab.Hits != len(alerts) {
+		t.Errorf("Got %d total hits for @state x @receiver, expected %d", crossTab.Hits, len(alerts))
+	}
+	for _, row := range crossTab.Rows {
+		if _, found := row.Columns[""]; found {
+			t.Errorf("Got hits for an empty @receiver value in @state=%s row", row.Value)
+		}
+	}
+}
+
+func BenchmarkCountLabelsSerial(b *testing.B) {
+	alerts := generateAlerts(50000)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		countLabels(alerts, 1)
+	}
+}
+
+func BenchmarkCountLabelsParallel(b *testing.B) {
+	alerts := generateAlerts(50000)
+	workers := labelCountWorkers(len(alerts))
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		countLabels(alerts, workers)
+	}
+}
+
+type sortByLabelTest struct {
+	values  []string
+	reverse bool
+	sorted  []string
+}
+
+var sortByLabelTests = []sortByLabelTest{
+	{
+		values: []string{"info", "critical", "warning"},
+		sorted: []string{"critical", "warning", "info"},
+	},
+	{
+		values:  []string{"info", "critical", "warning"},
+		reverse: true,
+		sorted:  []string{"info", "warning", "critical"},
+	},
+	{
+		values: []string{"page", "info", "", "debug", "critical", "warning"},
+		sorted: []string{"critical", "warning", "info", "debug", "page", ""},
+	},
+	{
+		values:  []string{"page", "info", "", "debug", "critical", "warning"},
+		reverse: true,
+		sorted:  []string{"", "page", "debug", "info", "warning", "critical"},
+	},
+	{
+		values: []string{"node10", "node9", "node1"},
+		sorted: []string{"node1", "node9", "node10"},
+	},
+}
+
+func TestSortByLabelCustomOrder(t *testing.T) {
+	config.Config.Grid.Sorting.CustomValues.Order = map[string][]string{
+		"severity": {"critical", "warning", "info"},
+	}
+	defer func() {
+		config.Config.Grid.Sorting.CustomValues.Order = map[string][]string{}
+	}()
+
+	for _, testCase := range sortByLabelTests {
+		values := make([]string, len(testCase.values))
+		copy(values, testCase.values)
+		sort.SliceStable(values, func(i, j int) bool {
+			return sortByLabel("severity", values[i], values[j], testCase.reverse, labelValueComparator("natural"))
+		})
+		if diff := cmp.Diff(testCase.sorted, values); diff != "" {
+			t.Errorf("Wrong sort order for %v with reverse=%v (-want +got):\n%s", testCase.values, testCase.reverse, diff)
+		}
+	}
+}
+
+type sortByCollationTest struct {
+	collation string
+	values    []string
+	reverse   bool
+	sorted    []string
+}
+
+var sortByCollationTests = []sortByCollationTest{
+	{
+		collation: "natural",
+		values:    []string{"Bergen", "Ålesund", "Oslo"},
+		sorted:    []string{"Bergen", "Oslo", "Ålesund"},
+	},
+	{
+		collation: "en",
+		values:    []string{"Bergen", "Ålesund", "Oslo"},
+		sorted:    []string{"Ålesund", "Bergen", "Oslo"},
+	},
+	{
+		collation: "en",
+		values:    []string{"Bergen", "Ålesund", "Oslo"},
+		reverse:   true,
+		sorted:    []string{"Oslo", "Bergen", "Ålesund"},
+	},
+	{
+		collation: "natural",
+		values:    []string{"zürich", "zagreb", "école", "dublin"},
+		sorted:    []string{"dublin", "zagreb", "zürich", "école"},
+	},
+	{
+		collation: "fr",
+		values:    []string{"zürich", "zagreb", "école", "dublin"},
+		sorted:    []string{"dublin", "école", "zagreb", "zürich"},
+	},
+	{
+		collation: "en",
+		values:    []string{"node10", "nöde9", "node1"},
+		sorted:    []string{"node1", "nöde9", "node10"},
+	},
+	{
+		collation: "",
+		values:    []string{"node10", "node9", "node1"},
+		sorted:    []string{"node1", "node9", "node10"},
+	},
+	{
+		collation: "not a valid tag!",
+		values:    []string{"node10", "node9", "node1"},
+		sorted:    []string{"node1", "node9", "node10"},
+	},
+}
+
+func TestSortByLabelCollation(t *testing.T) {
+	for _, testCase := range sortByCollationTests {
+		less := labelValueComparator(testCase.collation)
+		values := make([]string, len(testCase.values))
+		copy(values, testCase.values)
+		sort.SliceStable(values, func(i, j int) bool {
+			return sortByLabel("dc", values[i], values[j], testCase.reverse, less)
+		})
+		if diff := cmp.Diff(testCase.sorted, values); diff != "" {
+			t.Errorf("Wrong sort order for %v with collation=%q reverse=%v (-want +got):\n%s", testCase.values, testCase.collation, testCase.reverse, diff)
+		}
+	}
+}
+
+func TestRegroupAlertGroups(t *testing.T) {
+	groups := []models.AlertGroup{
+		{
+			ID: "1",
+			Alerts: models.AlertList{
+				{Labels: map[string]string{"alertname": "a1", "team": "ops"}, State: models.AlertStateActive, Alertmanager: []models.AlertmanagerInstance{{Name: "am1"}}},
+				{Labels: map[string]string{"alertname": "a2", "team": "dev"}, State: models.AlertStateSuppressed},
+			},
+		},
+		{
+			ID: "2",
+			Alerts: models.AlertList{
+				{Labels: map[string]string{"alertname": "a3", "team": "ops"}, State: models.AlertStateActive, Alertmanager: []models.AlertmanagerInstance{{Name: "am1"}, {Name: "am2"}}},
+				{Labels: map[string]string{"alertname": "a4"}, State: models.AlertStateActive},
+			},
+		},
+	}
+
+	regrouped := regroupAlertGroups(groups, "team")
+	if len(regrouped) != 3 {
+		t.Fatalf("regroupAlertGroups() returned %d groups, expected 3", len(regrouped))
+	}
+
+	expected := map[string][]string{
+		"ops": {"a1", "a3"},
+		"dev": {"a2"},
+		"":    {"a4"},
+	}
+	for _, ag := range regrouped {
+		value := ag.Labels["team"]
+		if value == "" && len(ag.Labels) != 0 {
+			t.Errorf("Group for alerts without team label has labels: %v", ag.Labels)
+		}
+		alertnames := []string{}
+		for _, alert := range ag.Alerts {
+			alertnames = append(alertnames, alert.Labels["alertname"])
+		}
+		if diff := cmp.Diff(expected[value], alertnames); diff != "" {
+			t.Errorf("Wrong alerts in group team=%s (-want +got):\n%s", value, diff)
+		}
+		if ag.ID == "" || ag.ID == "1" || ag.ID == "2" {
+			t.Errorf("Group team=%s has invalid ID %q", value, ag.ID)
+		}
+	}
+
+	ops := regrouped[0]
+	if ops.StateCount[models.AlertStateActive] != 2 || ops.StateCount[models.AlertStateSuppressed] != 0 {
+		t.Errorf("Wrong state counts for team=ops: %v", ops.StateCount)
+	}
+	if diff := cmp.Diff(map[string]int{"am1": 2, "am2": 1}, ops.AlertmanagerCount); diff != "" {
+		t.Errorf("Wrong alertmanager counts for team=ops (-want +got):\n%s", diff)
+	}
+
+	again := regroupAlertGroups(groups, "team")
+	for i := range regrouped {
+		if regrouped[i].ID != again[i].ID {
+			t.Errorf("Group ID for team=%s changed between calls: %s != %s", regrouped[i].Labels["team"], regrouped[i].ID, again[i].ID)
+		}
+	}
+}
+
+func TestSplitAlertGroups(t *testing.T) {
+	groups := []models.AlertGroup{
+		{
+			ID:       "1",
+			Receiver: "default",
+			Labels:   map[string]string{"alertname": "a"},
+			Alerts: models.AlertList{
+				{Labels: map[string]string{"alertname": "a", "team": "ops"}, State: models.AlertStateActive, Alertmanager: []models.AlertmanagerInstance{{Name: "am1"}}},
+				{Labels: map[string]string{"alertname": "a", "team": "dev"}, State: models.AlertStateSuppressed},
+				{Labels: map[string]string{"alertname": "a"}, State: models.AlertStateActive},
+				{Labels: map[string]string{"alertname": "a", "team": "ops"}, State: models.AlertStateActive, Alertmanager: []models.AlertmanagerInstance{{Name: "am1"}, {Name: "am2"}}},
+			},
+		},
+		{
+			ID:       "2",
+			Receiver: "default",
+			Labels:   map[string]string{"alertname": "b"},
+			Alerts: models.AlertList{
+				{Labels: map[string]string{"alertname": "b", "team": "ops"}, State: models.AlertStateActive},
+			},
+		},
+		{
+			ID:       "3",
+			Receiver: "default",
+			Labels:   map[string]string{"alertname": "c", "team": "ops"},
+			Alerts: models.AlertList{
+				{Labels: map[string]string{"alertname": "c", "team": "ops"}, State: models.AlertStateActive},
+			},
+		},
+	}
+
+	split := splitAlertGroups(groups, "team")
+
+	type groupSummary struct {
+		Labels map[string]string
+		Alerts int
+	}
+	got := []groupSummary{}
+	for _, ag := range split {
+		got = append(got, groupSummary{Labels: ag.Labels, Alerts: len(ag.Alerts)})
+	}
+	expected := []groupSummary{
+		{Labels: map[string]string{"alertname": "a"}, Alerts: 1},
+		{Labels: map[string]string{"alertname": "a", "team": "dev"}, Alerts: 1},
+		{Labels: map[string]string{"alertname": "a", "team": "ops"}, Alerts: 2},
+		{Labels: map[string]string{"alertname": "b", "team": "ops"}, Alerts: 1},
+		{Labels: map[string]string{"alertname": "c", "team": "ops"}, Alerts: 1},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("Wrong groups returned by splitAlertGroups() (-want +got):\n%s", diff)
+	}
+
+	// alerts without the label stay in the original group
+	if split[0].ID != "1" {
+		t.Errorf("Original group has ID %q, expected 1", split[0].ID)
+	}
+	// group already using the label for grouping is unchanged
+	if split[4].ID != "3" {
+		t.Errorf("Group grouped by team has ID %q, expected 3", split[4].ID)
+	}
+	ids := map[string]bool{}
+	for _, ag := range split {
+		if ids[ag.ID] {
+			t.Errorf("Duplicated group ID %q", ag.ID)
+		}
+		ids[ag.ID] = true
+		if ag.Receiver != "default" {
+			t.Errorf("Group %s has receiver %q, expected default", ag.ID, ag.Receiver)
+		}
+	}
+
+	ops := split[2]
+	if ops.StateCount[models.AlertStateActive] != 2 || ops.StateCount[models.AlertStateSuppressed] != 0 {
+		t.Errorf("Wrong state counts for team=ops: %v", ops.StateCount)
+	}
+	if diff := cmp.Diff(map[string]int{"am1": 2, "am2": 1}, ops.AlertmanagerCount); diff != "" {
+		t.Errorf("Wrong alertmanager counts for team=ops (-want +got):\n%s", diff)
+	}
+	if split[0].StateCount[models.AlertStateActive] != 1 || len(split[0].AlertmanagerCount) != 0 {
+		t.Errorf("Wrong counters for the original group: %v %v", split[0].StateCount, split[0].AlertmanagerCount)
+	}
+
+	// derived IDs must not depend on alert order
+	reversed := []models.AlertGroup{groups[0]}
+	reversed[0].Alerts = models.AlertList{groups[0].Alerts[3], groups[0].Alerts[2], groups[0].Alerts[1], groups[0].Alerts[0]}
+	again := splitAlertGroups(reversed, "team")
+	for i := range again {
+		if split[i].ID != again[i].ID {
+			t.Errorf("Group ID for %v changed between calls: %s != %s", split[i].Labels, split[i].ID, again[i].ID)
+		}
+	}
+}
+
+func TestSortLabelChain(t *testing.T) {
+	newGroup := func(id string, labels map[string]string) models.APIAlertGroup {
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID:     id,
+				Labels: labels,
+				Alerts: models.AlertList{{Labels: map[string]string{}}},
+			},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", map[string]string{"priority": "2"}),
+		"2": newGroup("2", map[string]string{"severity": "1"}),
+		"3": newGroup("3", map[string]string{"priority": "3", "severity": "0"}),
+		"4": newGroup("4", map[string]string{"alertname": "foo"}),
+	}
+
+	type chainTest struct {
+		query string
+		ids   []string
+	}
+	chainTests := []chainTest{
+		{query: "sortLabel=priority,severity", ids: []string{"2", "1", "3", "4"}},
+		{query: "sortLabel=priority, severity", ids: []string{"2", "1", "3", "4"}},
+		{query: "sortLabel=severity,priority", ids: []string{"3", "2", "1", "4"}},
+		{query: "sortLabel=foo,priority,severity", ids: []string{"2", "1", "3", "4"}},
+	}
+
+	for _, testCase := range chainTests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?sortOrder=label&sortReverse=0&"+url.PathEscape(testCase.query), nil)
+		ids := []string{}
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func TestSortByAnnotation(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(id string, startsAt time.Duration, shared, first models.Annotations) models.APIAlertGroup {
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID: id,
+				Alerts: models.AlertList{
+					{Labels: map[string]string{}, Annotations: first},
+					{Labels: map[string]string{}, Annotations: models.Annotations{{Name: "priority", Value: "1"}}},
+				},
+				LatestStartsAt: ts.Add(startsAt),
+			},
+			Shared: models.APIAlertGroupSharedMaps{Annotations: shared},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", time.Minute, models.Annotations{{Name: "priority", Value: "10"}}, nil),
+		"2": newGroup("2", time.Minute*2, nil, models.Annotations{{Name: "priority", Value: "2"}}),
+		"3": newGroup("3", time.Minute*3, nil, models.Annotations{{Name: "summary", Value: "foo"}}),
+		"4": newGroup("4", time.Minute*4, models.Annotations{{Name: "summary", Value: "bar"}}, models.Annotations{{Name: "priority", Value: "9"}}),
+		"5": newGroup("5", time.Minute*5, nil, nil),
+	}
+
+	for _, testCase := range []struct {
+		query string
+		ids   []string
+	}{
+		// 3 and 5 are missing the annotation, they are sorted by timestamp
+		{query: "sortOrder=annotation&sortAnnotation=priority&sortReverse=0", ids: []string{"2", "4", "1", "5", "3"}},
+		{query: "sortOrder=annotation&sortAnnotation=priority&sortReverse=1", ids: []string{"5", "3", "1", "4", "2"}},
+		{query: "sortOrder=annotation&sortAnnotation=summary&sortReverse=0", ids: []string{"4", "3", "5", "2", "1"}},
+		// all groups are missing the annotation
+		{query: "sortOrder=annotation&sortAnnotation=foo&sortReverse=0", ids: []string{"5", "4", "3", "2", "1"}},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []string{}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func generateAlertGroups(count int) []models.APIAlertGroup {
+	startsAt := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	groups := make([]models.APIAlertGroup, count)
+	for i := 0; i < count; i++ {
+		alerts := make(models.AlertList, i%5+1)
+		for j := range alerts {
+			alerts[j] = models.Alert{Labels: map[string]string{}}
+		}
+		groups[i] = models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID: fmt.Sprintf("group%d", i),
+				Labels: map[string]string{
+					"alertname": fmt.Sprintf("alert%d", i%50),
+					"cluster":   fmt.Sprintf("cluster%d", i%5),
+				},
+				Alerts:           alerts,
+				LatestStartsAt:   startsAt.Add(time.Duration(i%997) * time.Second),
+				LatestFirstSeen:  startsAt.Add(time.Duration(i%113) * time.Second),
+				EarliestStartsAt: startsAt.Add(time.Duration(i%59) * time.Second),
+			},
+		}
+	}
+	return groups
+}
+
+func TestSortGroupsStats(t *testing.T) {
+	for _, order := range []string{"disabled", "startsAt", "firstSeen", "oldestStartsAt", "label", "alertCount", "severityScore"} {
+		settings := models.GridSettings{Order: order, Label: "alertname"}
+
+		if stats := sortGroups(context.Background(), generateAlertGroups(1), settings); stats.comparisons != 0 {
+			t.Errorf("[%s] Got %d comparison(s) when sorting a single group, expected 0", order, stats.comparisons)
+		}
+
+		if stats := sortGroups(context.Background(), generateAlertGroups(100), settings); stats.comparisons < 99 {
+			t.Errorf("[%s] Got %d comparison(s) when sorting 100 groups, expected at least 99", order, stats.comparisons)
+		}
+	}
+}
+
+func TestSortAlertGroupsMetrics(t *testing.T) {
+	groups := map[string]models.APIAlertGroup{}
+	for _, ag := range generateAlertGroups(10) {
+		groups[ag.ID] = ag
+	}
+
+	for _, testCase := range []struct {
+		sortOrder string
+		label     string
+	}{
+		{sortOrder: "startsAt", label: "startsAt"},
+		{sortOrder: "firstSeen", label: "firstSeen"},
+		{sortOrder: "oldestStartsAt", label: "oldestStartsAt"},
+		{sortOrder: "label", label: "label"},
+		{sortOrder: "alertCount", label: "alertCount"},
+		{sortOrder: "severityScore", label: "severityScore"},
+		{sortOrder: "disabled", label: "disabled"},
+		{sortOrder: "foo", label: "disabled"},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?sortLabel=alertname&sortOrder="+testCase.sortOrder, nil)
+
+		before := testutil.ToFloat64(sortComparisons.WithLabelValues(testCase.label))
+		sortAlertGroups(c, groups)
+		after := testutil.ToFloat64(sortComparisons.WithLabelValues(testCase.label))
+		if after <= before {
+			t.Errorf("karma_sort_comparisons_total{order=%q} didn't increase after sorting with sortOrder=%s", testCase.label, testCase.sortOrder)
+		}
+	}
+}
+
+func BenchmarkSortGroups(b *testing.B) {
+	for _, order := range []string{"disabled", "startsAt", "firstSeen", "oldestStartsAt", "label", "alertCount", "severityScore"} {
+		settings := models.GridSettings{Order: order, Label: "alertname", SecondaryLabel: "cluster"}
+		b.Run(order, func(b *testing.B) {
+			source := generateAlertGroups(20000)
+			groups := make([]models.APIAlertGroup, len(source))
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				copy(groups, source)
+				b.StartTimer()
+				sortGroups(context.Background(), groups, settings)
+			}
+		})
+	}
+}
+
+func TestSortByFirstSeen(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(id string, firstSeen, startsAt time.Duration) models.APIAlertGroup {
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID:              id,
+				LatestFirstSeen: ts.Add(firstSeen),
+				LatestStartsAt:  ts.Add(startsAt),
+			},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", time.Minute, time.Hour),
+		"2": newGroup("2", time.Minute*2, 0),
+		"3": newGroup("3", 0, time.Minute),
+		"4": newGroup("4", time.Minute, time.Minute),
+	}
+
+	for _, testCase := range []struct {
+		query string
+		ids   []string
+	}{
+		{query: "sortOrder=firstSeen&sortReverse=0", ids: []string{"3", "4", "1", "2"}},
+		{query: "sortOrder=firstSeen&sortReverse=1", ids: []string{"2", "1", "4", "3"}},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		ids := []string{}
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func TestSortByEarliestStartsAt(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(id string, earliest, latest time.Duration) models.APIAlertGroup {
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID:               id,
+				EarliestStartsAt: ts.Add(earliest),
+				LatestStartsAt:   ts.Add(latest),
+			},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", time.Minute, time.Hour),
+		"2": newGroup("2", time.Minute*2, time.Minute*2),
+		"3": newGroup("3", 0, time.Hour*2),
+		"4": newGroup("4", time.Minute, time.Minute),
+	}
+
+	for _, testCase := range []struct {
+		query string
+		ids   []string
+	}{
+		{query: "sortOrder=oldestStartsAt&sortReverse=0", ids: []string{"3", "4", "1", "2"}},
+		{query: "sortOrder=oldestStartsAt&sortReverse=1", ids: []string{"2", "1", "4", "3"}},
+		{query: "sortOrder=startsAt&sortReverse=0", ids: []string{"4", "2", "1", "3"}},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		ids := []string{}
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func TestSortAlertGroupsPinFilter(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(id string, startsAt time.Duration, severities ...string) models.APIAlertGroup {
+		alerts := models.AlertList{}
+		for _, severity := range severities {
+			alerts = append(alerts, models.Alert{
+				Labels:   map[string]string{"severity": severity},
+				StartsAt: ts.Add(startsAt),
+			})
+		}
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID:             id,
+				Alerts:         alerts,
+				LatestStartsAt: ts.Add(startsAt),
+			},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", time.Hour, "critical"),
+		"2": newGroup("2", time.Minute*2, "warning"),
+		"3": newGroup("3", 0, "critical"),
+		"4": newGroup("4", time.Minute, "warning", "critical"),
+	}
+
+	for _, testCase := range []struct {
+		query string
+		ids   []string
+	}{
+		{query: "sortOrder=startsAt&sortReverse=0", ids: []string{"3", "4", "2", "1"}},
+		{query: "sortOrder=startsAt&sortReverse=0&pinFilter=severity=critical", ids: []string{"3", "4", "1", "2"}},
+		{query: "sortOrder=startsAt&sortReverse=1&pinFilter=severity=critical", ids: []string{"1", "4", "3", "2"}},
+		{query: "sortOrder=startsAt&sortReverse=0&pinFilter=severity=warning", ids: []string{"4", "2", "3", "1"}},
+		{query: "sortOrder=startsAt&sortReverse=0&pinFilter=severity=foo", ids: []string{"3", "4", "2", "1"}},
+		{query: "sortOrder=startsAt&sortReverse=0&pinFilter=severity=~(", ids: []string{"3", "4", "2", "1"}},
+		{query: "sortOrder=startsAt&sortReverse=0&pinFilter=", ids: []string{"3", "4", "2", "1"}},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		ids := []string{}
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s (-want +got):\n%s", testCase.query, diff)
+		}
+	}
+}
+
+func TestSortBySeverityScore(t *testing.T) {
+	defaultSeverityScore := config.Config.Grid.Sorting.SeverityScore
+	defer func() {
+		config.Config.Grid.Sorting.SeverityScore = defaultSeverityScore
+	}()
+	config.Config.Grid.Sorting.SeverityScore.Label = "severity"
+	config.Config.Grid.Sorting.SeverityScore.Weights = map[string]int{
+		"critical": 100,
+		"warning":  50,
+		"info":     10,
+	}
+
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(id string, startsAt time.Duration, severities ...string) models.APIAlertGroup {
+		alerts := models.AlertList{}
+		for _, severity := range severities {
+			labels := map[string]string{"instance": id}
+			if severity != "" {
+				labels["severity"] = severity
+			}
+			alerts = append(alerts, models.Alert{Labels: labels, StartsAt: ts.Add(startsAt)})
+		}
+		return models.APIAlertGroup{
+			AlertGroup: models.AlertGroup{
+				ID:             id,
+				Alerts:         alerts,
+				LatestStartsAt: ts.Add(startsAt),
+			},
+		}
+	}
+	groups := map[string]models.APIAlertGroup{
+		"1": newGroup("1", time.Hour, "critical"),
+		"2": newGroup("2", time.Minute*2, "warning", "warning", "warning"),
+		"3": newGroup("3", 0, "info", "unknown"),
+		"4": newGroup("4", time.Minute, "warning", "critical"),
+		"5": newGroup("5", time.Minute*3, ""),
+	}
+
+	for _, testCase := range []struct {
+		mode  string
+		query string
+		ids   []string
+	}{
+		{mode: "max", query: "sortOrder=severityScore&sortReverse=1", ids: []string{"1", "4", "2", "3", "5"}},
+		{mode: "max", query: "sortOrder=severityScore&sortReverse=0", ids: []string{"5", "3", "2", "1", "4"}},
+		{mode: "sum", query: "sortOrder=severityScore&sortReverse=1", ids: []string{"2", "4", "1", "3", "5"}},
+		{mode: "sum", query: "sortOrder=severityScore&sortReverse=0", ids: []string{"5", "3", "1", "2", "4"}},
+	} {
+		config.Config.Grid.Sorting.SeverityScore.Mode = testCase.mode
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+testCase.query, nil)
+		ids := []string{}
+		sorted, err := sortAlertGroups(c, groups)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ag := range sorted {
+			ids = append(ids, ag.ID)
+		}
+		if diff := cmp.Diff(testCase.ids, ids); diff != "" {
+			t.Errorf("Wrong sort order for %s with mode=%s (-want +got):\n%s", testCase.query, testCase.mode, diff)
+		}
+	}
+}
@@ -1026,6 +1026,18 @@ func TestVerifyAllGroups(t *testing.T) {
 			}
 		}
 
+		for _, upstream := range ur.Upstreams.Instances {
+			if upstream.Name == "default" && upstream.AlertCount != 24 {
+				t.Errorf("[%s] Upstream '%s' alertCount mismatch, expected 24 but got %d", version, upstream.Name, upstream.AlertCount)
+			}
+			if upstream.LastCollectionTimestamp.IsZero() {
+				t.Errorf("[%s] Upstream '%s' has zero lastCollectionTimestamp", version, upstream.Name)
+			}
+			if upstream.LastCollectionDuration < 0 {
+				t.Errorf("[%s] Upstream '%s' has negative lastCollectionDuration: %d", version, upstream.Name, upstream.LastCollectionDuration)
+			}
+		}
+
 		am, foundAM := ur.Silences["843c4a11660fe38ea61e6960a29d4f4796da6488"]
 		if !foundAM {
 			t.Errorf("[%s] Alertmanager cluster '843c4a11660fe38ea61e6960a29d4f4796da6488' (default) missing from silences", version)
@@ -1088,12 +1100,13 @@ func TestVerifyAllGroups(t *testing.T) {
 }
 
 type sortTest struct {
-	filter         string
-	sortOrder      string
-	sortLabel      string
-	sortReverse    string
-	expectedLabel  string
-	expectedValues []string
+	filter             string
+	sortOrder          string
+	sortLabel          string
+	sortLabelSecondary string
+	sortReverse        string
+	expectedLabel      string
+	expectedValues     []string
 }
 
 var sortTests = []sortTest{
@@ -1185,6 +1198,40 @@ var sortTests = []sortTest{
 		expectedLabel:  "job",
 		expectedValues: []string{"node_ping", "node_ping", "node_ping", "node_exporter", "node_exporter", "node_exporter"},
 	},
+	{
+		filter:             "q=@receiver=by-cluster-service",
+		sortOrder:          "label",
+		sortLabel:          "cluster",
+		sortLabelSecondary: "alertname",
+		sortReverse:        "0",
+		expectedLabel:      "alertname",
+		expectedValues:     []string{"HTTP_Probe_Failed", "Host_Down", "Host_Down", "Memory_Usage_Too_High", "Free_Disk_Space_Too_Low", "Host_Down"},
+	},
+	{
+		filter:             "q=@receiver=by-cluster-service",
+		sortOrder:          "label",
+		sortLabel:          "cluster",
+		sortLabelSecondary: "alertname",
+		sortReverse:        "1",
+		expectedLabel:      "alertname",
+		expectedValues:     []string{"Host_Down", "Free_Disk_Space_Too_Low", "Memory_Usage_Too_High", "Host_Down", "Host_Down", "HTTP_Probe_Failed"},
+	},
+	{
+		filter:         "q=@receiver=by-name&q=alertname!=Free_Disk_Space_Too_Low",
+		sortOrder:      "alertCount",
+		sortLabel:      "",
+		sortReverse:    "0",
+		expectedLabel:  "alertname",
+		expectedValues: []string{"Memory_Usage_Too_High", "HTTP_Probe_Failed", "Host_Down"},
+	},
+	{
+		filter:         "q=@receiver=by-name&q=alertname!=Free_Disk_Space_Too_Low",
+		sortOrder:      "alertCount",
+		sortLabel:      "",
+		sortReverse:    "1",
+		expectedLabel:  "alertname",
+		expectedValues: []string{"Host_Down", "HTTP_Probe_Failed", "Memory_Usage_Too_High"},
+	},
 }
 
 func TestSortOrder(t *testing.T) {
@@ -1203,9 +1250,10 @@ func TestSortOrder(t *testing.T) {
 
 		for _, testCase := range sortTests {
 			uri := fmt.Sprintf(
-				"/alerts.json?sortOrder=%s&sortLabel=%s&sortReverse=%s&%s",
+				"/alerts.json?sortOrder=%s&sortLabel=%s&sortLabelSecondary=%s&sortReverse=%s&%s",
 				testCase.sortOrder,
 				testCase.sortLabel,
+				testCase.sortLabelSecondary,
 				testCase.sortReverse,
 				testCase.filter,
 			)
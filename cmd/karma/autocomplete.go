@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/prymitive/karma/internal/alertmanager"
+	"vbom.ml/util/sortorder"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -30,20 +31,21 @@ func knownLabelNames(c *gin.Context) {
 	labels := alertmanager.DedupKnownLabels()
 	acData := []string{}
 
-	term, found := c.GetQuery("term")
-	if !found || term == "" {
-		// return everything
-		sort.Strings(labels)
-		acData = labels
-	} else {
-		// return what matches
-		for _, key := range labels {
-			if strings.Contains(key, term) {
-				acData = append(acData, key)
-			}
+	term := c.Query("term")
+	search := c.Query("search")
+	for _, key := range labels {
+		// term= matches any part of the label name, search= only the prefix
+		if term != "" && !strings.Contains(key, term) {
+			continue
 		}
-		sort.Strings(acData)
+		if search != "" && !strings.HasPrefix(key, search) {
+			continue
+		}
+		acData = append(acData, key)
 	}
+	sort.Slice(acData, func(i, j int) bool {
+		return sortorder.NaturalLess(acData[i], acData[j])
+	})
 
 	data, err := json.Marshal(acData)
 	if err != nil {
@@ -57,6 +59,24 @@ func knownLabelNames(c *gin.Context) {
 	logAlertsView(c, "MIS", time.Since(start))
 }
 
+// filterLabelValues returns naturally sorted label values, if search isn't
+// empty then only values starting with it are returned
+func filterLabelValues(values []string, search string) []string {
+	filtered := []string{}
+	for _, value := range values {
+		if search != "" && !strings.HasPrefix(value, search) {
+			continue
+		}
+		filtered = append(filtered, value)
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return sortorder.NaturalLess(filtered[i], filtered[j])
+	})
+	return filtered
+}
+
+// knownLabelValues allows querying distinct values of a label, search= can be
+// used to only return values with given prefix
 func knownLabelValues(c *gin.Context) {
 	noCache(c)
 	start := time.Now()
@@ -77,8 +97,7 @@ func knownLabelValues(c *gin.Context) {
 		return
 	}
 
-	values := alertmanager.DedupKnownLabelValues(name)
-	sort.Strings(values)
+	values := filterLabelValues(redactLabelValues(name, alertmanager.DedupKnownLabelValues(name)), c.Query("search"))
 
 	data, err := json.Marshal(values)
 	if err != nil {
@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/prymitive/karma/internal/mock"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 type requestTest struct {
@@ -49,6 +51,26 @@ var autocompleteTests = []autocompleteTest{
 				StatusCode: 200,
 				Results:    []string{},
 			},
+			{
+				PathSuffix: "?search=",
+				StatusCode: 200,
+				Results:    []string{"alertname", "cluster", "disk", "instance", "ip", "job"},
+			},
+			{
+				PathSuffix: "?search=i",
+				StatusCode: 200,
+				Results:    []string{"instance", "ip"},
+			},
+			{
+				PathSuffix: "?search=name",
+				StatusCode: 200,
+				Results:    []string{},
+			},
+			{
+				PathSuffix: "?search=i&term=n",
+				StatusCode: 200,
+				Results:    []string{"instance"},
+			},
 		},
 	},
 	{
@@ -74,6 +96,31 @@ var autocompleteTests = []autocompleteTest{
 				StatusCode: 200,
 				Results:    []string{"dev", "prod", "staging"},
 			},
+			{
+				PathSuffix: "?name=cluster&search=",
+				StatusCode: 200,
+				Results:    []string{"dev", "prod", "staging"},
+			},
+			{
+				PathSuffix: "?name=cluster&search=s",
+				StatusCode: 200,
+				Results:    []string{"staging"},
+			},
+			{
+				PathSuffix: "?name=cluster&search=x",
+				StatusCode: 200,
+				Results:    []string{},
+			},
+			{
+				PathSuffix: "?name=foobar&search=s",
+				StatusCode: 200,
+				Results:    []string{},
+			},
+			{
+				PathSuffix: "?name=instance&search=web",
+				StatusCode: 200,
+				Results:    []string{"web1", "web2"},
+			},
 		},
 	},
 }
@@ -108,6 +155,8 @@ func TestLabelAutocomplete(t *testing.T) {
 						if len(ur) != len(testCase.Results) {
 							t.Errorf("Invalid number of responses for %s, got %d, expected %d", url, len(ur), len(testCase.Results))
 							t.Errorf("Results: %s", ur)
+						} else if diff := cmp.Diff(testCase.Results, ur); diff != "" {
+							t.Errorf("Wrong results for %s (-want +got):\n%s", url, diff)
 						}
 					}
 				}
@@ -115,3 +164,22 @@ func TestLabelAutocomplete(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterLabelValues(t *testing.T) {
+	for _, testCase := range []struct {
+		values []string
+		search string
+		result []string
+	}{
+		{values: []string{}, search: "", result: []string{}},
+		{values: []string{"node10", "node9", "node1"}, search: "", result: []string{"node1", "node9", "node10"}},
+		{values: []string{"node10", "node9", "node1", "web1"}, search: "node", result: []string{"node1", "node9", "node10"}},
+		{values: []string{"node10", "node9", "node1", "web1"}, search: "node1", result: []string{"node1", "node10"}},
+		{values: []string{"node10", "node9", "node1", "web1"}, search: "Node", result: []string{}},
+	} {
+		result := filterLabelValues(testCase.values, testCase.search)
+		if diff := cmp.Diff(testCase.result, result); diff != "" {
+			t.Errorf("Wrong result for %v with search=%q (-want +got):\n%s", testCase.values, testCase.search, diff)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// exportAlerts endpoint, json, returns filtered and sorted alert groups
+// it accepts the same filter (q) and sort query args as the alerts endpoint
+func exportAlerts(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	ts, _ := start.UTC().MarshalText()
+
+	cacheKey := c.Request.RequestURI
+
+	data, found := apiCache.Get(cacheKey)
+	if found {
+		c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+		logAlertsView(c, "HIT", time.Since(start))
+		return
+	}
+
+	matchFilters, validFilters, err := getFiltersFromQuery(c.Request.Context(), c.QueryArray("q"))
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	filtered, err := filterAlerts(c.Request.Context(), matchFilters, validFilters, "", "", getShowResolved(c), getInvert(c), nil)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	sortedGroups, err := sortAlertGroups(c, filtered.groups)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+
+	resp := models.AlertsExportResponse{
+		SchemaVersion: models.AlertsExportSchemaVersion,
+		Timestamp:     string(ts),
+		Version:       version,
+		AlertGroups:   redactAlertGroups(sortedGroups),
+		TotalAlerts:   filtered.totalAlerts,
+	}
+
+	data, err = json.Marshal(resp)
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+
+	apiCache.Set(cacheKey, data, -1)
+
+	c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// exportLabelStats endpoint, csv, streams label stats calculated for alerts
+// matching passed filters (q) as name, value, hits and percent rows
+func exportLabelStats(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	matchFilters, validFilters, err := getFiltersFromQuery(c.Request.Context(), c.QueryArray("q"))
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	filtered, err := filterAlerts(c.Request.Context(), matchFilters, validFilters, "", "", getShowResolved(c), getInvert(c), nil)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	// export all values without grouping them
+	stats := countersToLabelStats(redactCounters(filtered.counters), 0)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	err = w.Write([]string{"name", "value", "hits", "percent"})
+	if err != nil {
+		log.Errorf("Failed to write CSV header: %s", err)
+		return
+	}
+	for _, nameStats := range stats {
+		for _, valueStats := range nameStats.Values {
+			err = w.Write([]string{
+				nameStats.Name,
+				valueStats.Value,
+				strconv.Itoa(valueStats.Hits),
+				strconv.Itoa(valueStats.Percent),
+			})
+			if err != nil {
+				log.Errorf("Failed to write CSV row: %s", err)
+				return
+			}
+		}
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		log.Errorf("Failed to flush CSV data: %s", err)
+		return
+	}
+
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// exportAlertMetrics endpoint, text, returns all firing alerts matching passed
+// filters (q or filter) as Prometheus metrics, only labels listed in the
+// alerts.metrics.labels config option are exported
+func exportAlertMetrics(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	// filter is accepted as an alias of q so that scrape configs that can only
+	// set params with unique names don't need to use q
+	filterStrings := append(c.QueryArray("q"), c.QueryArray("filter")...)
+	matchFilters, validFilters, err := getFiltersFromQuery(c.Request.Context(), filterStrings)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	filtered, err := filterAlerts(c.Request.Context(), matchFilters, validFilters, "", "", false, getInvert(c), nil)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+
+	// the same alert can be routed to multiple receivers, count it only once
+	seen := map[string]bool{}
+	alerts := []map[string]string{}
+	for _, ag := range filtered.groups {
+		for _, alert := range ag.Alerts {
+			if alert.State != models.AlertStateActive || seen[alert.LabelsFingerprint()] {
+				continue
+			}
+			seen[alert.LabelsFingerprint()] = true
+			// labels shared by all alerts were moved out of every alert
+			labels := map[string]string{}
+			for _, m := range []map[string]string{ag.Labels, ag.Shared.Labels, alert.Labels} {
+				for name, value := range m {
+					labels[name] = value
+				}
+			}
+			alerts = append(alerts, redactLabels(labels))
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newAlertMetricsCollector(config.Config.Alerts.Metrics.Labels, alerts))
+	// responses are already compressed by the gzip middleware
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{DisableCompression: true}).ServeHTTP(c.Writer, c.Request)
+
+	logAlertsView(c, "MIS", time.Since(start))
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prymitive/karma/internal/alertmanager"
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/mock"
+	"github.com/prymitive/karma/internal/models"
+)
+
+func TestExportAlerts(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alerts export using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		req := httptest.NewRequest("GET", "/export/alerts.json?q=@receiver=by-cluster-service&q=alertname=Host_Down&sortOrder=label&sortLabel=cluster&sortReverse=0", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("GET /export/alerts.json returned status %d", resp.Code)
+		}
+
+		ur := models.AlertsExportResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), &ur)
+		if err != nil {
+			t.Errorf("Failed to unmarshal response: %s", err)
+		}
+		if ur.SchemaVersion != models.AlertsExportSchemaVersion {
+			t.Errorf("[%s] Got schema version %d, expected %d", version, ur.SchemaVersion, models.AlertsExportSchemaVersion)
+		}
+		if ur.TotalAlerts != 8 {
+			t.Errorf("[%s] Got %d alert(s) in response, expected %d", version, ur.TotalAlerts, 8)
+		}
+		clusters := []string{}
+		for _, ag := range ur.AlertGroups {
+			clusters = append(clusters, ag.Labels["cluster"])
+		}
+		expected := []string{"dev", "prod", "staging"}
+		if len(clusters) != len(expected) {
+			t.Errorf("[%s] Got %d alert group(s) in response, expected %d", version, len(clusters), len(expected))
+		} else {
+			for i := range expected {
+				if clusters[i] != expected[i] {
+					t.Errorf("[%s] Incorrectly sorted alert groups, expected %v, got %v", version, expected, clusters)
+					break
+				}
+			}
+		}
+	}
+}
+
+type labelStatsExportTest struct {
+	filter   string
+	rows     int
+	expected [][]string
+}
+
+var labelStatsExportTests = []labelStatsExportTest{
+	{
+		filter: "q=@receiver=by-cluster-service&q=alertname=Host_Down",
+		rows:   25,
+		expected: [][]string{
+			{"name", "value", "hits", "percent"},
+			{"cluster", "dev", "3", "38"},
+			{"cluster", "staging", "3", "37"},
+			{"cluster", "prod", "2", "25"},
+		},
+	},
+	{
+		filter: "q=alertname=NotExisting",
+		rows:   1,
+		expected: [][]string{
+			{"name", "value", "hits", "percent"},
+		},
+	},
+}
+
+func TestExportLabelStats(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing label stats export using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		for _, testCase := range labelStatsExportTests {
+			req := httptest.NewRequest("GET", "/export/labelStats.csv?"+testCase.filter, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /export/labelStats.csv returned status %d", resp.Code)
+			}
+
+			rows, err := csv.NewReader(resp.Body).ReadAll()
+			if err != nil {
+				t.Errorf("[%s] Failed to parse CSV response: %s", version, err)
+			}
+			if len(rows) != testCase.rows {
+				t.Errorf("[%s] Got %d CSV row(s) for %s, expected %d", version, len(rows), testCase.filter, testCase.rows)
+			}
+			for _, expectedRow := range testCase.expected {
+				var found bool
+				for _, row := range rows {
+					if strings.Join(row, ",") == strings.Join(expectedRow, ",") {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("[%s] CSV row %v not found in response for %s: %v", version, expectedRow, testCase.filter, rows)
+				}
+			}
+		}
+	}
+}
+
+type alertMetricsTest struct {
+	filter  string
+	labels  []string
+	cluster string
+	missing []string
+}
+
+var alertMetricsTests = []alertMetricsTest{
+	{
+		labels:  []string{"alertname"},
+		missing: []string{"cluster=", "instance="},
+	},
+	{
+		labels:  []string{"alertname", "cluster"},
+		missing: []string{"instance=", "job="},
+	},
+	{
+		filter:  "q=cluster=dev",
+		labels:  []string{"alertname", "cluster"},
+		cluster: "dev",
+		missing: []string{`cluster="staging"`, `cluster="prod"`},
+	},
+	{
+		filter:  "filter=cluster=dev",
+		labels:  []string{"alertname", "cluster"},
+		cluster: "dev",
+		missing: []string{`cluster="staging"`, `cluster="prod"`},
+	},
+}
+
+func TestExportAlertMetrics(t *testing.T) {
+	for _, testCase := range alertMetricsTests {
+		mockConfig()
+		config.Config.Alerts.Metrics.Labels = testCase.labels
+		for _, version := range mock.ListAllMocks() {
+			t.Logf("Testing alert metrics using mock files from Alertmanager %s", version)
+			mockAlerts(version)
+			r := ginTestEngine()
+			req := httptest.NewRequest("GET", "/metrics/alerts?"+testCase.filter, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /metrics/alerts?%s returned status %d", testCase.filter, resp.Code)
+			}
+			body := resp.Body.String()
+
+			// count unique firing alerts for every combination of label values
+			counts := map[string]int{}
+			seen := map[string]bool{}
+			for _, ag := range alertmanager.DedupAlerts() {
+				for _, alert := range ag.Alerts {
+					if alert.State != models.AlertStateActive || seen[alert.LabelsFingerprint()] {
+						continue
+					}
+					if testCase.cluster != "" && alert.Labels["cluster"] != testCase.cluster {
+						continue
+					}
+					seen[alert.LabelsFingerprint()] = true
+					pairs := []string{}
+					for _, name := range testCase.labels {
+						pairs = append(pairs, fmt.Sprintf("%s=%q", name, alert.Labels[name]))
+					}
+					counts[strings.Join(pairs, ",")]++
+				}
+			}
+			if len(counts) == 0 {
+				t.Errorf("[%s] No firing alerts found in mock data", version)
+			}
+
+			for labels, count := range counts {
+				line := fmt.Sprintf("karma_alert_firing{%s} %d\n", labels, count)
+				if !strings.Contains(body, line) {
+					t.Errorf("[%s] Metric line %q not found in response: %s", version, line, body)
+				}
+			}
+			if n := strings.Count(body, "karma_alert_firing{"); n != len(counts) {
+				t.Errorf("[%s] Got %d metric(s) for %v, expected %d", version, n, testCase.labels, len(counts))
+			}
+			for _, m := range testCase.missing {
+				if strings.Contains(body, m) {
+					t.Errorf("[%s] Response contains %q that should be excluded: %s", version, m, body)
+				}
+			}
+		}
+	}
+}
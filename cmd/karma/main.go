@@ -16,6 +16,7 @@ import (
 	"github.com/prymitive/karma/internal/config"
 	"github.com/prymitive/karma/internal/models"
 	"github.com/prymitive/karma/internal/transform"
+	"github.com/prymitive/karma/internal/webhook"
 
 	"github.com/DeanThompson/ginpprof"
 	"github.com/gin-contrib/cors"
@@ -34,15 +35,15 @@ import (
 var (
 	version = "dev"
 
-	// ticker is a timer used by background loop that will keep pulling
-	// data from Alertmanager
-	ticker *time.Ticker
-
 	// apiCache will be used to keep short lived copy of JSON reponses generated for the UI
 	// If there are requests with the same filter we should respond from cache
 	// rather than do all the filtering every time
 	apiCache *cache.Cache
 
+	// notifier will be used to send webhook requests for new alerts, it's only
+	// set if webhook.uri is configured
+	notifier *webhook.Notifier
+
 	staticBuildFileSystem = newBinaryFileSystem("ui/build")
 	staticSrcFileSystem   = newBinaryFileSystem("ui/src")
 )
@@ -57,6 +58,21 @@ func getViewURL(sub string) string {
 	return u
 }
 
+// requestTimeout cancels the context of every request that takes longer than
+// given timeout, handlers filtering and sorting alerts stop once it's canceled
+func requestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func setupRouter(router *gin.Engine) {
 	router.Use(gzip.Gzip(gzip.DefaultCompression))
 
@@ -88,11 +104,28 @@ func setupRouter(router *gin.Engine) {
 		ExposeHeaders:    []string{"Content-Length"},
 	}))
 
+	timeout := requestTimeout(config.Config.Listen.RequestTimeout)
+
 	router.GET(getViewURL("/"), index)
-	router.GET(getViewURL("/alerts.json"), alerts)
+	router.GET(getViewURL("/alerts.json"), timeout, alerts)
 	router.GET(getViewURL("/autocomplete.json"), autocomplete)
+	router.GET(getViewURL("/export/alerts.json"), timeout, exportAlerts)
+	router.GET(getViewURL("/export/labelStats.csv"), timeout, exportLabelStats)
+	router.GET(getViewURL("/metrics/alerts"), timeout, exportAlertMetrics)
 	router.GET(getViewURL("/labelNames.json"), knownLabelNames)
 	router.GET(getViewURL("/labelValues.json"), knownLabelValues)
+	router.GET(getViewURL("/labelCrossTab.json"), timeout, labelCrossTab)
+	router.GET(getViewURL("/filterCheck"), filterCheck)
+	router.POST(getViewURL("/filterDiff"), filterDiff)
+	router.GET(getViewURL("/schema"), apiSchema)
+	router.GET(getViewURL("/silences"), activeSilences)
+	router.POST(getViewURL("/silences/bulk"), bulkSilence)
+	router.POST(getViewURL("/ack"), acknowledge)
+	router.DELETE(getViewURL("/ack"), unacknowledge)
+	router.POST(getViewURL("/maintenance"), startMaintenance)
+	router.DELETE(getViewURL("/maintenance"), stopMaintenance)
+	router.GET(getViewURL("/health"), health)
+	router.GET(getViewURL("/ready"), ready)
 
 	router.GET(getViewURL("/custom.css"), func(c *gin.Context) {
 		serveFileOr404(config.Config.Custom.CSS, "text/css", c)
@@ -131,6 +164,9 @@ func setupUpstreams() {
 			alertmanager.WithProxy(s.Proxy),
 			alertmanager.WithHTTPTransport(httpTransport), // we will pass a nil unless TLS.CA or TLS.Cert is set
 			alertmanager.WithHTTPHeaders(s.Headers),
+			alertmanager.WithBasicAuthFile(s.BasicAuth.Username, s.BasicAuth.PasswordFile),
+			alertmanager.WithPriority(s.Priority),
+			alertmanager.WithRateLimit(s.RateLimit.Rate, s.RateLimit.Burst, s.RateLimit.Reads),
 		)
 		if err != nil {
 			log.Fatalf("Failed to create Alertmanager '%s' with URI '%s': %s", s.Name, s.URI, err)
@@ -201,6 +237,10 @@ func main() {
 
 	apiCache = cache.New(cache.NoExpiration, 10*time.Second)
 
+	if config.Config.Webhook.URI != "" {
+		notifier = webhook.NewNotifier(config.Config.Webhook.URI, config.Config.Webhook.Headers, config.Config.Webhook.Timeout)
+	}
+
 	setupUpstreams()
 
 	if len(alertmanager.GetAlertmanagers()) == 0 {
@@ -218,7 +258,6 @@ func main() {
 	log.Info("Done, starting HTTP server")
 
 	// background loop that will fetch updates from Alertmanager
-	ticker = time.NewTicker(config.Config.Alertmanager.Interval)
 	go Tick()
 
 	switch config.Config.Debug {
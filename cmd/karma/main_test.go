@@ -46,6 +46,7 @@ func TestMetrics(t *testing.T) {
 		"karma_collected_alerts_count",
 		"karma_collect_cycles_total",
 		"karma_alertmanager_errors_total",
+		"karma_filter_parse_errors_total",
 	} {
 		if !strings.Contains(body, s) {
 			t.Errorf("Metric '%s' missing from /metrics response", s)
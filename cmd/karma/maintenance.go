@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prymitive/karma/internal/filters"
+	"github.com/prymitive/karma/internal/models"
+)
+
+// maintenanceStore keeps the maintenance window, there can be only one and it's
+// only kept in memory, so restarting karma will remove it
+type maintenanceStore struct {
+	lock   sync.Mutex
+	window *models.MaintenanceWindow
+}
+
+var maintenance = &maintenanceStore{}
+
+func (s *maintenanceStore) set(window models.MaintenanceWindow) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.window = &window
+}
+
+func (s *maintenanceStore) remove() (models.MaintenanceWindow, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.window == nil {
+		return models.MaintenanceWindow{}, false
+	}
+	window := *s.window
+	s.window = nil
+	return window, true
+}
+
+// active returns the maintenance window if it's active at given time, windows
+// that already ended are removed
+func (s *maintenanceStore) active(now time.Time) *models.MaintenanceWindow {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.window == nil {
+		return nil
+	}
+	if !s.window.EndsAt.After(now) {
+		s.window = nil
+		return nil
+	}
+	if !s.window.IsActive(now) {
+		return nil
+	}
+	window := *s.window
+	return &window
+}
+
+// maintenanceFilters parses filters of given maintenance window, all of them
+// are validated when the window is created
+func maintenanceFilters(window *models.MaintenanceWindow) []filters.FilterT {
+	windowFilters := []filters.FilterT{}
+	if window == nil {
+		return windowFilters
+	}
+	for _, filterExpression := range window.Filters {
+		windowFilters = append(windowFilters, filters.NewFilter(filterExpression))
+	}
+	return windowFilters
+}
+
+// isInMaintenance returns true if alert group is in scope of the maintenance
+// window with given filters, that's when there are no filters or when at
+// least one alert in the group matches all of them
+func isInMaintenance(ag models.AlertGroup, windowFilters []filters.FilterT) bool {
+	if len(windowFilters) == 0 {
+		return true
+	}
+	for _, alert := range ag.Alerts {
+		alert := alert // scopelint pin
+		isMatch := true
+		for _, filter := range windowFilters {
+			if !filter.Match(&alert, 0) {
+				isMatch = false
+				break
+			}
+		}
+		if isMatch {
+			return true
+		}
+	}
+	return false
+}
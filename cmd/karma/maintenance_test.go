@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+func TestMaintenanceStore(t *testing.T) {
+	now := time.Now()
+	s := &maintenanceStore{}
+
+	if s.active(now) != nil {
+		t.Errorf("active() returned a window for an empty store")
+	}
+	if _, found := s.remove(); found {
+		t.Errorf("remove() returned true for an empty store")
+	}
+
+	s.set(models.MaintenanceWindow{StartsAt: now.Add(time.Minute), EndsAt: now.Add(time.Hour), CreatedBy: "me"})
+	if s.active(now) != nil {
+		t.Errorf("active() returned a window that didn't start yet")
+	}
+	if w := s.active(now.Add(time.Minute)); w == nil || w.CreatedBy != "me" {
+		t.Errorf("active() returned invalid window at start time: %v", w)
+	}
+	if s.active(now.Add(time.Minute*30)) == nil {
+		t.Errorf("active() didn't return a window that's in progress")
+	}
+	if s.active(now.Add(time.Hour)) != nil {
+		t.Errorf("active() returned a window that already ended")
+	}
+	if _, found := s.remove(); found {
+		t.Errorf("remove() returned true for a window that already ended")
+	}
+
+	s.set(models.MaintenanceWindow{StartsAt: now, EndsAt: now.Add(time.Hour), CreatedBy: "me"})
+	s.set(models.MaintenanceWindow{StartsAt: now, EndsAt: now.Add(time.Minute), CreatedBy: "you"})
+	if w := s.active(now); w == nil || w.CreatedBy != "you" {
+		t.Errorf("set() didn't replace the previous window: %v", w)
+	}
+	w, found := s.remove()
+	if !found || w.CreatedBy != "you" {
+		t.Errorf("remove() returned invalid window: %v", w)
+	}
+	if s.active(now) != nil {
+		t.Errorf("active() returned a window after remove()")
+	}
+}
+
+func TestIsInMaintenance(t *testing.T) {
+	ag := models.AlertGroup{
+		Alerts: models.AlertList{
+			{Labels: map[string]string{"alertname": "foo", "cluster": "dev"}},
+			{Labels: map[string]string{"alertname": "foo", "cluster": "prod"}},
+		},
+	}
+	window := func(filters ...string) *models.MaintenanceWindow {
+		return &models.MaintenanceWindow{Filters: filters}
+	}
+	for _, testCase := range []struct {
+		window *models.MaintenanceWindow
+		inside bool
+	}{
+		{window: window(), inside: true},
+		{window: window("alertname=foo"), inside: true},
+		{window: window("cluster=prod"), inside: true},
+		{window: window("alertname=foo", "cluster=prod"), inside: true},
+		{window: window("alertname=bar"), inside: false},
+		{window: window("cluster=prod", "cluster=dev"), inside: false},
+	} {
+		if inside := isInMaintenance(ag, maintenanceFilters(testCase.window)); inside != testCase.inside {
+			t.Errorf("isInMaintenance() with filters %v returned %v, expected %v", testCase.window.Filters, inside, testCase.inside)
+		}
+	}
+}
@@ -1,10 +1,28 @@
 package main
 
 import (
+	"strings"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prymitive/karma/internal/alertmanager"
 )
 
+var filterParseErrors = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "karma_filter_parse_errors_total",
+	Help: "Total number of invalid filters passed in API requests",
+})
+
+var sortDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "karma_sort_duration_seconds",
+	Help:    "Time spent sorting alert groups in API responses",
+	Buckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+}, []string{"order"})
+
+var sortComparisons = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "karma_sort_comparisons_total",
+	Help: "Total number of comparisons made when sorting alert groups in API responses",
+}, []string{"order"})
+
 type karmaCollector struct {
 	collectedAlerts *prometheus.Desc
 	collectedGroups *prometheus.Desc
@@ -121,4 +139,58 @@ func (c *karmaCollector) Collect(ch chan<- prometheus.Metric) {
 
 func init() {
 	prometheus.MustRegister(newKarmaCollector())
+	prometheus.MustRegister(filterParseErrors)
+	prometheus.MustRegister(sortDuration)
+	prometheus.MustRegister(sortComparisons)
+}
+
+// alertMetricsCollector exports firing alerts as metrics, only labels from the
+// allowlist are used so alerts with the same values of those labels are
+// counted together
+type alertMetricsCollector struct {
+	firing *prometheus.Desc
+	labels []string
+	alerts []map[string]string
+}
+
+func newAlertMetricsCollector(labels []string, alerts []map[string]string) *alertMetricsCollector {
+	return &alertMetricsCollector{
+		firing: prometheus.NewDesc(
+			"karma_alert_firing",
+			"Number of firing alerts with given labels",
+			labels,
+			prometheus.Labels{},
+		),
+		labels: labels,
+		alerts: alerts,
+	}
+}
+
+func (c *alertMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.firing
+}
+
+func (c *alertMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	// joined label values -> count
+	counts := map[string]float64{}
+	// joined label values -> label values
+	values := map[string][]string{}
+	for _, labels := range c.alerts {
+		vals := make([]string, 0, len(c.labels))
+		for _, name := range c.labels {
+			vals = append(vals, labels[name])
+		}
+		key := strings.Join(vals, "\xff")
+		counts[key]++
+		values[key] = vals
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.firing,
+			prometheus.GaugeValue,
+			count,
+			values[key]...,
+		)
+	}
 }
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/filters"
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/slices"
+	"github.com/prymitive/karma/internal/transform"
+)
+
+// isPrivateLabel returns true if given label is stripped or redacted from API
+// responses
+func isPrivateLabel(name string) bool {
+	return slices.StringInSlice(config.Config.Privacy.Labels.Strip, name) ||
+		slices.StringInSlice(config.Config.Privacy.Labels.Redact, name)
+}
+
+// isPrivateAnnotation returns true if given annotation is stripped or
+// redacted from API responses
+func isPrivateAnnotation(name string) bool {
+	return slices.StringInSlice(config.Config.Privacy.Annotations.Strip, name) ||
+		slices.StringInSlice(config.Config.Privacy.Annotations.Redact, name)
+}
+
+// isPrivacyEnabled returns true if any label or annotation is stripped or
+// redacted from API responses
+func isPrivacyEnabled() bool {
+	p := config.Config.Privacy
+	return len(p.Labels.Strip) > 0 || len(p.Labels.Redact) > 0 || len(p.Annotations.Strip) > 0 || len(p.Annotations.Redact) > 0
+}
+
+// redactLabels returns a copy of labels with stripped labels removed and
+// values of redacted labels replaced with the placeholder
+func redactLabels(labels map[string]string) map[string]string {
+	p := config.Config.Privacy
+	return transform.RedactLabels(p.Labels.Strip, p.Labels.Redact, p.Placeholder, labels)
+}
+
+// redactAnnotations returns a copy of annotations with stripped annotations
+// removed and values of redacted annotations replaced with the placeholder
+func redactAnnotations(annotations models.Annotations) models.Annotations {
+	p := config.Config.Privacy
+	return transform.RedactAnnotations(p.Annotations.Strip, p.Annotations.Redact, p.Placeholder, annotations)
+}
+
+// redactAlert strips or redacts configured labels and annotations from a
+// single alert, source links of alerts with any private label are removed
+// since generator URLs usually include label values in the query
+func redactAlert(alert models.Alert) models.Alert {
+	hasPrivateLabels := false
+	for name := range alert.Labels {
+		if isPrivateLabel(name) {
+			hasPrivateLabels = true
+			break
+		}
+	}
+	alert.Labels = redactLabels(alert.Labels)
+	alert.Annotations = redactAnnotations(alert.Annotations)
+	if hasPrivateLabels {
+		instances := make([]models.AlertmanagerInstance, 0, len(alert.Alertmanager))
+		for _, am := range alert.Alertmanager {
+			am.Source = ""
+			instances = append(instances, am)
+		}
+		alert.Alertmanager = instances
+	}
+	return alert
+}
+
+// redactAlertGroups strips or redacts configured labels and annotations from
+// alert groups, it's done when assembling the response so it doesn't affect
+// filtering, new maps are created since groups share them with deduplicated
+// alerts
+func redactAlertGroups(groups []models.APIAlertGroup) []models.APIAlertGroup {
+	if !isPrivacyEnabled() {
+		return groups
+	}
+
+	for i, ag := range groups {
+		ag.Labels = redactLabels(ag.Labels)
+		ag.Shared.Labels = redactLabels(ag.Shared.Labels)
+		ag.Shared.Annotations = redactAnnotations(ag.Shared.Annotations)
+		alerts := make(models.AlertList, 0, len(ag.Alerts))
+		for _, alert := range ag.Alerts {
+			alerts = append(alerts, redactAlert(alert))
+		}
+		ag.Alerts = alerts
+		groups[i] = ag
+	}
+	return groups
+}
+
+// redactSilence removes matchers for stripped labels from a silence and
+// replaces values of matchers for redacted labels with the placeholder
+func redactSilence(silence models.Silence) models.Silence {
+	p := config.Config.Privacy
+	matchers := make([]models.SilenceMatcher, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		switch {
+		case slices.StringInSlice(p.Labels.Strip, m.Name):
+			continue
+		case slices.StringInSlice(p.Labels.Redact, m.Name):
+			m.Value = p.Placeholder
+			m.IsRegex = false
+		}
+		matchers = append(matchers, m)
+	}
+	silence.Matchers = matchers
+	return silence
+}
+
+// redactSilences applies redactSilence to silences returned with alerts
+func redactSilences(silences map[string]map[string]models.Silence) map[string]map[string]models.Silence {
+	if !isPrivacyEnabled() {
+		return silences
+	}
+
+	redacted := make(map[string]map[string]models.Silence, len(silences))
+	for cluster, clusterSilences := range silences {
+		redacted[cluster] = make(map[string]models.Silence, len(clusterSilences))
+		for id, silence := range clusterSilences {
+			redacted[cluster][id] = redactSilence(silence)
+		}
+	}
+	return redacted
+}
+
+// redactLabelValues returns values of a label as they should be returned in
+// API responses, there are no values for stripped labels and all values of
+// redacted labels are replaced with a single placeholder
+func redactLabelValues(name string, values []string) []string {
+	p := config.Config.Privacy
+	switch {
+	case slices.StringInSlice(p.Labels.Strip, name):
+		return []string{}
+	case slices.StringInSlice(p.Labels.Redact, name) && len(values) > 0:
+		return []string{p.Placeholder}
+	}
+	return values
+}
+
+// redactAutocomplete removes all hints for stripped or redacted labels and
+// annotations, since those include their values
+func redactAutocomplete(hints []models.Autocomplete) []models.Autocomplete {
+	if !isPrivacyEnabled() {
+		return hints
+	}
+
+	redacted := make([]models.Autocomplete, 0, len(hints))
+	for _, hint := range hints {
+		f := filters.NewFilter(hint.Value)
+		if isPrivateLabel(f.GetName()) {
+			continue
+		}
+		if f.GetName() == "@annotation" && isPrivateAnnotation(strings.SplitN(f.GetValue(), ":", 2)[0]) {
+			continue
+		}
+		redacted = append(redacted, hint)
+	}
+	return redacted
+}
+
+// redactColors removes colors of all stripped or redacted labels, since
+// those are keyed by label values
+func redactColors(colors models.LabelsColorMap) models.LabelsColorMap {
+	redacted := models.LabelsColorMap{}
+	for name, values := range colors {
+		if !isPrivateLabel(name) {
+			redacted[name] = values
+		}
+	}
+	return redacted
+}
+
+// redactCounters removes stripped labels from label counters and merges all
+// values of redacted labels under the placeholder, it's only done if
+// privacy.stripCounters is enabled, otherwise counters use all values
+func redactCounters(counters map[string]map[string]int) map[string]map[string]int {
+	p := config.Config.Privacy
+	if !p.StripCounters {
+		return counters
+	}
+
+	redacted := map[string]map[string]int{}
+	for name, values := range counters {
+		switch {
+		case slices.StringInSlice(p.Labels.Strip, name):
+			continue
+		case slices.StringInSlice(p.Labels.Redact, name):
+			total := 0
+			for _, hits := range values {
+				total += hits
+			}
+			redacted[name] = map[string]int{p.Placeholder: total}
+		default:
+			redacted[name] = values
+		}
+	}
+	return redacted
+}
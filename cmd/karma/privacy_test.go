@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRedactAlert(t *testing.T) {
+	defer func() { config.Config.Privacy.Labels.Redact = []string{} }()
+	config.Config.Privacy.Placeholder = "[redacted]"
+
+	newAlert := func(labels map[string]string) models.Alert {
+		return models.Alert{
+			Labels: labels,
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prometheus/graph?g0.expr=up%7Binstance%3D%22server1%22%7D"},
+			},
+		}
+	}
+
+	config.Config.Privacy.Labels.Redact = []string{"instance"}
+	alert := newAlert(map[string]string{"alertname": "Foo", "instance": "server1"})
+	redacted := redactAlert(alert)
+	if diff := cmp.Diff(map[string]string{"alertname": "Foo", "instance": "[redacted]"}, redacted.Labels); diff != "" {
+		t.Errorf("Wrong labels on redacted alert (-want +got):\n%s", diff)
+	}
+	if redacted.Alertmanager[0].Source != "" {
+		t.Errorf("Source wasn't removed from alert with redacted labels: %s", redacted.Alertmanager[0].Source)
+	}
+	if alert.Labels["instance"] != "server1" || alert.Alertmanager[0].Source == "" {
+		t.Errorf("Original alert was modified: %v", alert)
+	}
+
+	alert = newAlert(map[string]string{"alertname": "Foo"})
+	if redacted = redactAlert(alert); redacted.Alertmanager[0].Source != alert.Alertmanager[0].Source {
+		t.Errorf("Source was removed from alert without redacted labels: %v", redacted.Alertmanager)
+	}
+}
+
+func TestRedactSilence(t *testing.T) {
+	defer func() {
+		config.Config.Privacy.Labels.Strip = []string{}
+		config.Config.Privacy.Labels.Redact = []string{}
+	}()
+	config.Config.Privacy.Placeholder = "[redacted]"
+	config.Config.Privacy.Labels.Strip = []string{"job"}
+	config.Config.Privacy.Labels.Redact = []string{"instance"}
+
+	silence := models.Silence{
+		ID: "1",
+		Matchers: []models.SilenceMatcher{
+			{Name: "alertname", Value: "Foo"},
+			{Name: "instance", Value: "server[0-9]+", IsRegex: true},
+			{Name: "job", Value: "node"},
+		},
+	}
+	expected := []models.SilenceMatcher{
+		{Name: "alertname", Value: "Foo"},
+		{Name: "instance", Value: "[redacted]"},
+	}
+	if diff := cmp.Diff(expected, redactSilence(silence).Matchers); diff != "" {
+		t.Errorf("Wrong matchers on redacted silence (-want +got):\n%s", diff)
+	}
+	if len(silence.Matchers) != 3 || silence.Matchers[1].Value != "server[0-9]+" {
+		t.Errorf("Original silence was modified: %v", silence.Matchers)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prymitive/karma/internal/alertmanager"
@@ -42,6 +43,11 @@ func NewAlertmanagerProxy(alertmanager *alertmanager.Alertmanager) (*httputil.Re
 				password, _ := upstreamURL.User.Password()
 				req.SetBasicAuth(username, password)
 			}
+			// password file is checked on every request, so rotated passwords
+			// are used right away
+			for k, v := range alertmanager.BasicAuthHeaders() {
+				req.Header.Set(k, v)
+			}
 
 			// drop Accept-Encoding header so we always get uncompressed reponses from
 			// upstream, there's a gzip middleware that's global so we don't want it
@@ -69,6 +75,75 @@ func NewAlertmanagerProxy(alertmanager *alertmanager.Alertmanager) (*httputil.Re
 	return &proxy, nil
 }
 
+// proxyRateLimit rejects proxied requests with 429 status code once the rate
+// limit configured for given Alertmanager instance is exceeded
+func proxyRateLimit(alertmanager *alertmanager.Alertmanager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !alertmanager.AllowProxyRequest(c.Request.Method) {
+			log.Warningf("[%s] Rate limit exceeded for proxied %s request to %s", alertmanager.Name, c.Request.Method, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("rate limit exceeded for Alertmanager '%s'", alertmanager.Name)})
+			return
+		}
+		c.Next()
+	}
+}
+
+// memberSelector is used to spread proxied read requests across all members
+// of a cluster, it keeps a counter for every cluster so members are used in
+// turn
+type memberSelector struct {
+	lock sync.Mutex
+	next map[string]int
+}
+
+func newMemberSelector() *memberSelector {
+	return &memberSelector{next: map[string]int{}}
+}
+
+var proxyReadSelector = newMemberSelector()
+
+// pick returns the next member of given cluster, fallback is returned if there
+// are no members
+func (s *memberSelector) pick(cluster string, members []*alertmanager.Alertmanager, fallback *alertmanager.Alertmanager) *alertmanager.Alertmanager {
+	if len(members) == 0 {
+		return fallback
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	i := s.next[cluster] % len(members)
+	s.next[cluster] = i + 1
+	return members[i]
+}
+
+// proxyRead sends read requests to the next ready member of the cluster given
+// Alertmanager instance belongs to, members that are not ready or failed the
+// last collection are skipped, if there are no such members then the request
+// is sent to the instance itself
+// Rate limit of the selected member is applied since that's the instance that
+// will receive the request
+func proxyRead(am *alertmanager.Alertmanager, selector *memberSelector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		member := selector.pick(am.ClusterID(), am.ReadyClusterMembers(), am)
+		if !member.AllowProxyRequest(c.Request.Method) {
+			log.Warningf("[%s] Rate limit exceeded for proxied %s request to %s via cluster member %s", am.Name, c.Request.Method, c.Request.URL.Path, member.Name)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("rate limit exceeded for Alertmanager '%s'", member.Name)})
+			return
+		}
+		proxy, err := NewAlertmanagerProxy(member)
+		if err != nil {
+			log.Errorf("[%s] Failed to create proxy for cluster member '%s': %s", am.Name, member.Name, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if member.Name != am.Name {
+			log.Debugf("[%s] Proxy read request via cluster member %s", am.Name, member.Name)
+		}
+		http.StripPrefix(proxyPathPrefix(am.Name), proxy).ServeHTTP(c.Writer, c.Request)
+	}
+}
+
 func setupRouterProxyHandlers(router *gin.Engine, alertmanager *alertmanager.Alertmanager) error {
 	proxy, err := NewAlertmanagerProxy(alertmanager)
 	if err != nil {
@@ -76,15 +151,24 @@ func setupRouterProxyHandlers(router *gin.Engine, alertmanager *alertmanager.Ale
 	}
 	router.POST(
 		proxyPath(alertmanager.Name, "/api/v1/silences"),
+		proxyRateLimit(alertmanager),
 		gin.WrapH(http.StripPrefix(proxyPathPrefix(alertmanager.Name), proxy)))
 	router.DELETE(
 		proxyPath(alertmanager.Name, "/api/v1/silence/*id"),
+		proxyRateLimit(alertmanager),
 		gin.WrapH(http.StripPrefix(proxyPathPrefix(alertmanager.Name), proxy)))
 	router.POST(
 		proxyPath(alertmanager.Name, "/api/v2/silences"),
+		proxyRateLimit(alertmanager),
 		gin.WrapH(http.StripPrefix(proxyPathPrefix(alertmanager.Name), proxy)))
 	router.DELETE(
 		proxyPath(alertmanager.Name, "/api/v2/silence/*id"),
+		proxyRateLimit(alertmanager),
 		gin.WrapH(http.StripPrefix(proxyPathPrefix(alertmanager.Name), proxy)))
+	for _, path := range []string{"/api/v1/silences", "/api/v1/silence/*id", "/api/v2/silences", "/api/v2/silence/*id"} {
+		router.GET(
+			proxyPath(alertmanager.Name, path),
+			proxyRead(alertmanager, proxyReadSelector))
+	}
 	return nil
 }
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path"
 	"testing"
 	"time"
 
 	"github.com/prymitive/karma/internal/alertmanager"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/jarcoal/httpmock"
 )
 
@@ -69,19 +73,34 @@ var proxyTests = []proxyTest{
 	},
 	// valid alertmanager name, but invalid method
 	{
-		method:      "GET",
+		method:      "PUT",
 		localPath:   "/proxy/alertmanager/dummy/api/v1/silences",
 		upstreamURI: "",
 		code:        404,
 		response:    "404 page not found",
 	},
 	{
-		method:      "GET",
+		method:      "PUT",
 		localPath:   "/proxy/alertmanager/dummy/api/v1/silence/d8a61ca8-ee2e-4076-999f-276f1e986bf3",
 		upstreamURI: "http://localhost:9093/api/v1/silence/d8a61ca8-ee2e-4076-999f-276f1e986bf3",
 		code:        404,
 		response:    "404 page not found",
 	},
+	// read requests
+	{
+		method:      "GET",
+		localPath:   "/proxy/alertmanager/dummy/api/v2/silences",
+		upstreamURI: "http://localhost:9093/api/v2/silences",
+		code:        200,
+		response:    "[]",
+	},
+	{
+		method:      "GET",
+		localPath:   "/proxy/alertmanager/dummy/api/v2/silence/d8a61ca8-ee2e-4076-999f-276f1e986bf3",
+		upstreamURI: "http://localhost:9093/api/v2/silence/d8a61ca8-ee2e-4076-999f-276f1e986bf3",
+		code:        200,
+		response:    "{\"id\":\"d8a61ca8-ee2e-4076-999f-276f1e986bf3\"}",
+	},
 }
 
 func TestProxy(t *testing.T) {
@@ -123,6 +142,132 @@ func TestProxy(t *testing.T) {
 	}
 }
 
+func TestMemberSelector(t *testing.T) {
+	ams := []*alertmanager.Alertmanager{}
+	for _, name := range []string{"am1", "am2", "am3"} {
+		am, err := alertmanager.NewAlertmanager(name, "http://"+name+".localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ams = append(ams, am)
+	}
+	fallback, err := alertmanager.NewAlertmanager("fallback", "http://fallback.localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newMemberSelector()
+	picked := map[string]int{}
+	for i := 0; i < 9; i++ {
+		picked[s.pick("cluster1", ams, fallback).Name]++
+	}
+	if diff := cmp.Diff(map[string]int{"am1": 3, "am2": 3, "am3": 3}, picked); diff != "" {
+		t.Errorf("Requests are not spread across all members (-want +got):\n%s", diff)
+	}
+
+	// failed member is no longer passed, remaining members are still used in turn
+	picked = map[string]int{}
+	for i := 0; i < 4; i++ {
+		picked[s.pick("cluster1", []*alertmanager.Alertmanager{ams[0], ams[2]}, fallback).Name]++
+	}
+	if diff := cmp.Diff(map[string]int{"am1": 2, "am3": 2}, picked); diff != "" {
+		t.Errorf("Requests are not spread across remaining members (-want +got):\n%s", diff)
+	}
+
+	// every cluster has its own counter
+	if am := s.pick("cluster2", ams, fallback); am.Name != "am1" {
+		t.Errorf("First request for a new cluster was sent to %s, expected am1", am.Name)
+	}
+
+	if am := s.pick("cluster1", []*alertmanager.Alertmanager{}, fallback); am.Name != "fallback" {
+		t.Errorf("Request without any ready members was sent to %s, expected fallback", am.Name)
+	}
+}
+
+func TestProxyRateLimit(t *testing.T) {
+	r := ginTestEngine()
+	am, err := alertmanager.NewAlertmanager(
+		"dummy",
+		"http://localhost:9093",
+		alertmanager.WithRequestTimeout(time.Second*5),
+		alertmanager.WithProxy(true),
+		alertmanager.WithRateLimit(0.001, 3, false),
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	err = setupRouterProxyHandlers(r, am)
+	if err != nil {
+		t.Errorf("Failed to setup proxy for Alertmanager %s: %s", am.Name, err)
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:9093/api/v2/silences", httpmock.NewStringResponder(200, "{\"silenceID\":\"d8a61ca8\"}"))
+	httpmock.RegisterResponder("DELETE", "http://localhost:9093/api/v2/silence/d8a61ca8", httpmock.NewStringResponder(200, ""))
+
+	// the bucket is shared by all write requests
+	for i, testCase := range []struct {
+		method    string
+		localPath string
+		code      int
+	}{
+		{method: "POST", localPath: "/proxy/alertmanager/dummy/api/v2/silences", code: 200},
+		{method: "POST", localPath: "/proxy/alertmanager/dummy/api/v2/silences", code: 200},
+		{method: "DELETE", localPath: "/proxy/alertmanager/dummy/api/v2/silence/d8a61ca8", code: 200},
+		{method: "POST", localPath: "/proxy/alertmanager/dummy/api/v2/silences", code: 429},
+		{method: "DELETE", localPath: "/proxy/alertmanager/dummy/api/v2/silence/d8a61ca8", code: 429},
+	} {
+		req := httptest.NewRequest(testCase.method, testCase.localPath, nil)
+		resp := newCloseNotifyingRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != testCase.code {
+			t.Errorf("[%d] %s %s returned status %d while %d was expected", i, testCase.method, testCase.localPath, resp.Code, testCase.code)
+		}
+	}
+
+	if calls := httpmock.GetTotalCallCount(); calls != 3 {
+		t.Errorf("Got %d request(s) proxied to Alertmanager, expected 3", calls)
+	}
+}
+
+func TestProxyReadRateLimit(t *testing.T) {
+	r := ginTestEngine()
+	am, err := alertmanager.NewAlertmanager(
+		"dummy",
+		"http://localhost:9093",
+		alertmanager.WithRequestTimeout(time.Second*5),
+		alertmanager.WithProxy(true),
+		alertmanager.WithRateLimit(0.001, 2, true),
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	err = setupRouterProxyHandlers(r, am)
+	if err != nil {
+		t.Errorf("Failed to setup proxy for Alertmanager %s: %s", am.Name, err)
+	}
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("GET", "http://localhost:9093/api/v2/silences", httpmock.NewStringResponder(200, "[]"))
+
+	// there are no other cluster members so reads are sent to the instance
+	// itself and its rate limit is used
+	for i, code := range []int{200, 200, 429} {
+		req := httptest.NewRequest("GET", "/proxy/alertmanager/dummy/api/v2/silences", nil)
+		resp := newCloseNotifyingRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != code {
+			t.Errorf("[%d] GET returned status %d while %d was expected", i, resp.Code, code)
+		}
+	}
+
+	if calls := httpmock.GetTotalCallCount(); calls != 2 {
+		t.Errorf("Got %d request(s) proxied to Alertmanager, expected 2", calls)
+	}
+}
+
 type proxyHeaderTest struct {
 	method           string
 	localPath        string
@@ -256,3 +401,61 @@ func TestProxyToSubURIAlertmanager(t *testing.T) {
 		t.Errorf("Got response code %d instead of 200", resp.Code)
 	}
 }
+
+func TestProxyBasicAuthFile(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	dir, err := ioutil.TempDir("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	passwordFile := path.Join(dir, "password")
+
+	r := ginTestEngine()
+	am, err := alertmanager.NewAlertmanager(
+		"dummy",
+		"http://alertmanager.example.com",
+		alertmanager.WithRequestTimeout(time.Second*5),
+		alertmanager.WithProxy(true),
+		alertmanager.WithBasicAuthFile("foo", passwordFile),
+	)
+	if err != nil {
+		t.Error(err)
+	}
+	err = setupRouterProxyHandlers(r, am)
+	if err != nil {
+		t.Errorf("Failed to setup proxy for Alertmanager %s: %s", am.Name, err)
+	}
+
+	now := time.Now()
+	for i, password := range []string{"bar", "rotated"} {
+		password := password // scopelint pin
+		err = ioutil.WriteFile(passwordFile, []byte(password), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(time.Minute * time.Duration(i))
+		err = os.Chtimes(passwordFile, modTime, modTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", "http://alertmanager.example.com/api/v2/silences", func(req *http.Request) (*http.Response, error) {
+			user, pass, _ := req.BasicAuth()
+			if user != "foo" || pass != password {
+				t.Errorf("Proxied request has Basic Auth '%s:%s', expected 'foo:%s'", user, pass, password)
+			}
+			return httpmock.NewStringResponse(200, "ok"), nil
+		})
+
+		req := httptest.NewRequest("POST", "/proxy/alertmanager/dummy/api/v2/silences", nil)
+		resp := newCloseNotifyingRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != 200 {
+			t.Errorf("Proxied request returned status %d", resp.Code)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/prymitive/karma/internal/alertmanager"
+	"github.com/prymitive/karma/internal/config"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -32,13 +34,26 @@ func pullFromAlertmanager() {
 
 	wg.Wait()
 
+	alertmanager.ExpireAcknowledgements()
+	alertmanager.UpdateDedupStats()
+
+	if notifier != nil {
+		notifier.Update(alertmanager.DedupAlerts())
+	}
+
 	log.Info("Pull completed")
 	runtime.GC()
 }
 
 // Tick is the background timer used to call PullFromAlertmanager
 func Tick() {
-	for range ticker.C {
+	lastPull := time.Now()
+	for {
+		// wait is counted from the start of the last pull, so the time it
+		// took doesn't delay the next one
+		wait := alertmanager.PullInterval(config.Config.Alertmanager.Interval, config.Config.Alertmanager.Jitter)
+		time.Sleep(time.Until(lastPull.Add(wait)))
+		lastPull = time.Now()
 		pullFromAlertmanager()
 	}
 }
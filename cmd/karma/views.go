@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,13 +11,17 @@ import (
 	"net/http"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/prymitive/karma/internal/alertmanager"
 	"github.com/prymitive/karma/internal/config"
 	"github.com/prymitive/karma/internal/filters"
 	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/schema"
 	"github.com/prymitive/karma/internal/slices"
 	"github.com/prymitive/karma/internal/transform"
 
@@ -92,6 +97,17 @@ func logAlertsView(c *gin.Context, cacheStatus string, duration time.Duration) {
 	log.Infof("[%s %s] <%d> %s %s took %s", c.ClientIP(), cacheStatus, http.StatusOK, c.Request.Method, c.Request.RequestURI, duration)
 }
 
+// abortAlertsView responds with an error when alerts couldn't be filtered or
+// sorted, requests that were canceled or timed out get a 503 response
+func abortAlertsView(c *gin.Context, err error, start time.Time) {
+	status := http.StatusBadRequest
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+	log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), status, c.Request.Method, c.Request.RequestURI, time.Since(start))
+}
+
 func populateAPIFilters(matchFilters []filters.FilterT) []models.Filter {
 	apiFilters := []models.Filter{}
 	for _, filter := range matchFilters {
@@ -125,86 +141,68 @@ func authorFromHeader(c *gin.Context, header string, valueRe string) string {
 	return ""
 }
 
-// alerts endpoint, json, JS will query this via AJAX call
-func alerts(c *gin.Context) {
-	noCache(c)
-	start := time.Now()
-	ts, _ := start.UTC().MarshalText()
-
-	// initialize response object, set fields that don't require any locking
-	resp := models.AlertsResponse{}
-	resp.Status = "success"
-	resp.Timestamp = string(ts)
-	resp.Version = version
-	resp.Upstreams = getUpstreams()
-	resp.Settings = models.Settings{
-		Sorting: models.SortSettings{
-			Grid: models.GridSettings{
-				Order:   config.Config.Grid.Sorting.Order,
-				Reverse: config.Config.Grid.Sorting.Reverse,
-				Label:   config.Config.Grid.Sorting.Label,
-			},
-			ValueMapping: map[string]map[string]string{},
-		},
-		StaticColorLabels:        config.Config.Labels.Color.Static,
-		AnnotationsDefaultHidden: config.Config.Annotations.Default.Hidden,
-		AnnotationsHidden:        config.Config.Annotations.Hidden,
-		AnnotationsVisible:       config.Config.Annotations.Visible,
-		SilenceForm: models.SilenceFormSettings{
-			Author: authorFromHeader(c, config.Config.SilenceForm.Author.PopulateFromHeader.Header, config.Config.SilenceForm.Author.PopulateFromHeader.ValueRegex),
-			Strip: models.SilenceFormStripSettings{
-				Labels: config.Config.SilenceForm.Strip.Labels,
-			},
-		},
-	}
+// filteredAlerts holds all alert groups and the data derived from alerts
+// that passed all filters
+type filteredAlerts struct {
+	groups      map[string]models.APIAlertGroup
+	colors      models.LabelsColorMap
+	counters    map[string]map[string]int
+	silences    map[string]map[string]models.Silence
+	totalAlerts int
+}
 
-	if config.Config.Grid.Sorting.CustomValues.Labels != nil {
-		resp.Settings.Sorting.ValueMapping = config.Config.Grid.Sorting.CustomValues.Labels
+// getShowResolved returns true if resolved alerts should be included in the
+// response, showResolved query arg overrides the config value
+func getShowResolved(c *gin.Context) bool {
+	if showResolved, found := c.GetQuery("showResolved"); found && (showResolved == "0" || showResolved == "1") {
+		return showResolved == "1"
 	}
+	return config.Config.Grid.ShowResolved
+}
 
-	// use full URI (including query args) as cache key
-	cacheKey := c.Request.RequestURI
+// getInvert returns true if invert=1 query arg was passed, in which case only
+// alerts that don't match all filters are returned
+func getInvert(c *gin.Context) bool {
+	return c.Query("invert") == "1"
+}
 
-	data, found := apiCache.Get(cacheKey)
-	if found {
-		rawData, err := decompressCachedResponse(data.([]byte))
-		if err != nil {
-			log.Error(err.Error())
-			panic(err)
-		}
+// getCountersOnly returns true if countersOnly=1 query arg was passed, in which
+// case alert groups are not included in the response, only counters are
+func getCountersOnly(c *gin.Context) bool {
+	return c.Query("countersOnly") == "1"
+}
 
-		// need to overwrite settings as they can have user specific data
-		newResp := models.AlertsResponse{}
-		err = json.Unmarshal(rawData, &newResp)
-		if err != nil {
-			log.Error(err.Error())
-			panic(err)
-		}
-		newResp.Settings = resp.Settings
-		newResp.Timestamp = string(ts)
-		newData, err := json.Marshal(&newResp)
-		if err != nil {
-			log.Error(err.Error())
-			panic(err)
-		}
-		c.Data(http.StatusOK, gin.MIMEJSON, newData)
-		logAlertsView(c, "HIT", time.Since(start))
-		return
+// filterAlerts will apply filters to deduplicated alerts from all upstreams
+// and return alert groups with all alerts that matched
+// if regroupBy is set then alerts will be re-grouped using the value of that
+// label instead of the grouping done by Alertmanager
+// if splitBy is set then every group is further subdivided using the value of
+// that label, alerts without it stay in the original group
+// if showResolved is false then resolved alerts are skipped before applying
+// filters, so they are not counted anywhere
+// if invert is true then the final match result is inverted after applying
+// all filters, so only alerts that didn't match are returned
+// if window is set then alert groups in scope of that maintenance window are
+// tagged, or skipped if the window is configured to hide them
+// matching stops and an error is returned if the context is canceled
+func filterAlerts(ctx context.Context, matchFilters []filters.FilterT, validFilters bool, regroupBy string, splitBy string, showResolved bool, invert bool, window *models.MaintenanceWindow) (filteredAlerts, error) {
+	result := filteredAlerts{
+		groups:   map[string]models.APIAlertGroup{},
+		colors:   models.LabelsColorMap{},
+		silences: map[string]map[string]models.Silence{},
 	}
-
-	// get filters
-	matchFilters, validFilters := getFiltersFromQuery(c.QueryArray("q"))
-
-	// set pointers for data store objects, need a lock until end of view is reached
-	alerts := map[string]models.APIAlertGroup{}
-	colors := models.LabelsColorMap{}
-	counters := map[string]map[string]int{}
+	colors := result.colors
+	silences := result.silences
+	// alerts that passed all filters and are not hidden by maintenance, used
+	// for label counters
+	matched := []models.Alert{}
+	// groups with at least one alert that passed all filters
+	matchedGroups := []models.AlertGroup{}
 
 	dedupedAlerts := alertmanager.DedupAlerts()
 	dedupedColors := alertmanager.DedupColors()
 
 	amNameToCluster := map[string]string{}
-	silences := map[string]map[string]models.Silence{}
 	for _, am := range alertmanager.GetAlertmanagers() {
 		key := am.ClusterID()
 		amNameToCluster[am.Name] = key
@@ -214,13 +212,19 @@ func alerts(c *gin.Context) {
 		}
 	}
 
+	now := time.Now()
 	var matches int
 	for _, ag := range dedupedAlerts {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
 		agCopy := models.AlertGroup{
 			ID:                ag.ID,
 			Receiver:          ag.Receiver,
 			Labels:            ag.Labels,
 			LatestStartsAt:    ag.LatestStartsAt,
+			LatestFirstSeen:   ag.LatestFirstSeen,
+			EarliestStartsAt:  ag.EarliestStartsAt,
 			Alerts:            []models.Alert{},
 			AlertmanagerCount: map[string]int{},
 			StateCount:        map[string]int{},
@@ -231,6 +235,18 @@ func alerts(c *gin.Context) {
 
 		for _, alert := range ag.Alerts {
 			alert := alert // scopelint pin
+			if !showResolved && alert.IsResolved(now) {
+				continue
+			}
+			// @group filter must use the ID of the group alert will be moved to
+			parent := ag
+			if regroupBy != "" {
+				parent = models.AlertGroup{Labels: regroupLabels(regroupBy, alert.Labels[regroupBy])}
+				alert.GroupID = regroupedGroupID(regroupBy, alert.Labels[regroupBy])
+			}
+			if _, found := parent.Labels[splitBy]; splitBy != "" && !found && alert.Labels[splitBy] != "" {
+				alert.GroupID = splitGroupID(parent, splitBy, alert.Labels[splitBy])
+			}
 			results := []bool{}
 			if validFilters {
 				for _, filter := range matchFilters {
@@ -240,7 +256,11 @@ func alerts(c *gin.Context) {
 					}
 				}
 			}
-			if !validFilters || (slices.BoolInSlice(results, true) && !slices.BoolInSlice(results, false)) {
+			isMatch := !validFilters || (slices.BoolInSlice(results, true) && !slices.BoolInSlice(results, false))
+			if validFilters && invert {
+				isMatch = !isMatch
+			}
+			if isMatch {
 				matches++
 				// we need to update fingerprints since we've modified some fields in dedup
 				// and agCopy.ContentFingerprint() depends on per alert fingerprint
@@ -249,9 +269,6 @@ func alerts(c *gin.Context) {
 				alert.UpdateFingerprints()
 				agCopy.Alerts = append(agCopy.Alerts, alert)
 
-				countLabel(counters, "@state", alert.State)
-
-				countLabel(counters, "@receiver", alert.Receiver)
 				if ck, foundKey := dedupedColors["@receiver"]; foundKey {
 					if cv, foundVal := ck[alert.Receiver]; foundVal {
 						if _, found := colors["@receiver"]; !found {
@@ -291,7 +308,6 @@ func alerts(c *gin.Context) {
 							colors[key][value] = color
 						}
 					}
-					countLabel(counters, key, value)
 				}
 			}
 		}
@@ -313,30 +329,894 @@ func alerts(c *gin.Context) {
 					}
 				}
 			}
-			sort.Sort(agCopy.Alerts)
-			agCopy.LatestStartsAt = agCopy.FindLatestStartsAt()
-			agCopy.Hash = agCopy.ContentFingerprint()
-			apiAG := models.APIAlertGroup{AlertGroup: agCopy}
-			apiAG.DedupSharedMaps()
-			alerts[agCopy.ID] = apiAG
-			resp.TotalAlerts += len(agCopy.Alerts)
+			matchedGroups = append(matchedGroups, agCopy)
+		}
+
+	}
+
+	if regroupBy != "" {
+		matchedGroups = regroupAlertGroups(matchedGroups, regroupBy)
+	}
+	if splitBy != "" {
+		matchedGroups = splitAlertGroups(matchedGroups, splitBy)
+	}
+
+	windowFilters := maintenanceFilters(window)
+	for _, ag := range matchedGroups {
+		inMaintenance := window != nil && isInMaintenance(ag, windowFilters)
+		if inMaintenance && window.Hide {
+			continue
+		}
+		matched = append(matched, ag.Alerts...)
+		sort.Sort(ag.Alerts)
+		ag.LatestStartsAt = ag.FindLatestStartsAt()
+		ag.LatestFirstSeen = ag.FindLatestFirstSeen()
+		ag.EarliestStartsAt = ag.FindEarliestStartsAt()
+		ag.Hash = ag.ContentFingerprint()
+		apiAG := models.APIAlertGroup{AlertGroup: ag, Flapping: ag.IsFlapping(), MaintenanceActive: inMaintenance}
+		apiAG.DedupSharedMaps()
+		result.groups[ag.ID] = apiAG
+		result.totalAlerts += len(ag.Alerts)
+	}
+
+	result.counters = countLabels(matched, labelCountWorkers(len(matched)))
+
+	return result, nil
+}
+
+// labelCrossTab endpoint, json, returns the number of alerts matching passed
+// filters (q) for every combination of values of the row and column labels
+func labelCrossTab(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	cacheKey := c.Request.RequestURI
+
+	data, found := apiCache.Get(cacheKey)
+	if found {
+		c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+		logAlertsView(c, "HIT", time.Since(start))
+		return
+	}
+
+	for _, arg := range []string{"row", "column"} {
+		if c.Query(arg) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("missing %s=<label> parameter", arg)})
+			log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+			return
+		}
+	}
+
+	matchFilters, validFilters, err := getFiltersFromQuery(c.Request.Context(), c.QueryArray("q"))
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	filtered, err := filterAlerts(c.Request.Context(), matchFilters, validFilters, "", "", getShowResolved(c), getInvert(c), nil)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+	alerts := []models.Alert{}
+	for _, ag := range filtered.groups {
+		for _, alert := range ag.Alerts {
+			if isPrivacyEnabled() {
+				alert = redactAlert(alert)
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+
+	data, err = json.Marshal(crossTabulateLabels(alerts, c.Query("row"), c.Query("column")))
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+
+	apiCache.Set(cacheKey, data, -1)
+
+	c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// filterCheck endpoint, json, parses given filter expression and returns
+// the result without matching it against any alerts
+func filterCheck(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	expression, found := c.GetQuery("filter")
+	if !found || expression == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing filter=<expression> parameter"})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+		return
+	}
+
+	f := filters.NewFilter(expression)
+	resp := models.FilterCheckResponse{
+		Valid:  f.GetIsValid(),
+		Reason: f.GetInvalidReason(),
+	}
+	if resp.Valid {
+		resp.Parsed.Name = f.GetName()
+		resp.Parsed.Matcher = f.GetMatcher()
+		resp.Parsed.Value = f.GetValue()
+	}
+
+	c.JSON(http.StatusOK, resp)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// newFilterDiffBucket returns a bucket with all fingerprints from given set
+func newFilterDiffBucket(fingerprints map[string]bool) models.FilterDiffBucket {
+	bucket := models.FilterDiffBucket{Fingerprints: make([]string, 0, len(fingerprints))}
+	for fp := range fingerprints {
+		bucket.Fingerprints = append(bucket.Fingerprints, fp)
+	}
+	sort.Strings(bucket.Fingerprints)
+	bucket.Count = len(bucket.Fingerprints)
+	return bucket
+}
+
+// filterDiff endpoint, json, matches all alerts against two sets of filters
+// and returns fingerprints of alerts matched by only one of those sets or by
+// both, this allows to check how changing filters affects matched alerts
+func filterDiff(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	badRequest := func(reason string) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	}
+
+	req := models.FilterDiffRequest{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		badRequest(fmt.Sprintf("invalid request body: %s", err))
+		return
+	}
+
+	limit := filterLimit{}
+	filterSets := [][]filters.FilterT{}
+	for _, set := range []struct {
+		name        string
+		expressions []string
+	}{
+		{name: "a", expressions: req.A},
+		{name: "b", expressions: req.B},
+	} {
+		if len(set.expressions) == 0 {
+			badRequest(fmt.Sprintf("at least one filter is required in %s", set.name))
+			return
+		}
+		matchFilters := []filters.FilterT{}
+		for _, expression := range set.expressions {
+			f := filters.NewFilter(expression)
+			if !f.GetIsValid() {
+				badRequest(fmt.Sprintf("invalid filter '%s' in %s: %s", expression, set.name, f.GetInvalidReason()))
+				return
+			}
+			if err := limit.add(f); err != nil {
+				badRequest(err.Error())
+				return
+			}
+			matchFilters = append(matchFilters, f)
+		}
+		filterSets = append(filterSets, matchFilters)
+	}
+
+	// fingerprints of alerts matched by each filter set, filters are matched
+	// against redacted alerts so they can't be used to find out values of
+	// private labels and annotations
+	matched := []map[string]bool{{}, {}}
+	for _, ag := range alertmanager.DedupAlerts() {
+		for _, alert := range ag.Alerts {
+			alert := alert // scopelint pin
+			if alert.Fingerprint == "" {
+				continue
+			}
+			if isPrivacyEnabled() {
+				alert = redactAlert(alert)
+			}
+			for i, matchFilters := range filterSets {
+				isMatch := true
+				for _, filter := range matchFilters {
+					if !filter.Match(&alert, len(matched[i])) {
+						isMatch = false
+					}
+				}
+				if isMatch {
+					matched[i][alert.Fingerprint] = true
+				}
+			}
+		}
+	}
+
+	onlyA, onlyB, both := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for fp := range matched[0] {
+		if matched[1][fp] {
+			both[fp] = true
+		} else {
+			onlyA[fp] = true
+		}
+	}
+	for fp := range matched[1] {
+		if !matched[0][fp] {
+			onlyB[fp] = true
+		}
+	}
+
+	c.JSON(http.StatusOK, models.FilterDiffResponse{
+		OnlyA: newFilterDiffBucket(onlyA),
+		OnlyB: newFilterDiffBucket(onlyB),
+		Both:  newFilterDiffBucket(both),
+	})
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// newBulkSilence returns a silence with an equality matcher for every label
+func newBulkSilence(labels map[string]string, startsAt, endsAt time.Time, req models.BulkSilenceRequest) models.Silence {
+	silence := models.Silence{
+		Matchers:  []models.SilenceMatcher{},
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: req.CreatedBy,
+		Comment:   req.Comment,
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		silence.Matchers = append(silence.Matchers, models.SilenceMatcher{Name: name, Value: labels[name]})
+	}
+	return silence
+}
+
+// silenceTemplateData is passed to silence templates when rendering, it only
+// holds strings so templates have no access to any methods
+type silenceTemplateData struct {
+	Labels  map[string]string
+	Comment string
+}
+
+// newTemplatedSilence returns a silence with matchers and comment rendered
+// from given template using alert labels, an error is returned if the
+// template references a label that's missing from the alert
+// Values passed to regex matcher templates are escaped, so label values are
+// always matched literally and only the template itself can use regex syntax
+func newTemplatedSilence(tmpl config.SilenceTemplate, labels map[string]string, startsAt, endsAt time.Time, req models.BulkSilenceRequest) (models.Silence, error) {
+	silence := models.Silence{
+		Matchers:  []models.SilenceMatcher{},
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		CreatedBy: req.CreatedBy,
+		Comment:   req.Comment,
+	}
+	data := silenceTemplateData{Labels: labels, Comment: req.Comment}
+	regexData := silenceTemplateData{Labels: make(map[string]string, len(labels)), Comment: regexp.QuoteMeta(req.Comment)}
+	for name, value := range labels {
+		regexData.Labels[name] = regexp.QuoteMeta(value)
+	}
+	render := func(t *template.Template, data silenceTemplateData) (string, error) {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+
+	for _, matcher := range tmpl.Matchers {
+		matcherData := data
+		if matcher.IsRegex {
+			matcherData = regexData
+		}
+		value, err := render(matcher.CompiledValue, matcherData)
+		if err != nil {
+			return silence, err
+		}
+		if value == "" {
+			return silence, fmt.Errorf("matcher '%s' rendered to an empty value", matcher.Name)
+		}
+		silence.Matchers = append(silence.Matchers, models.SilenceMatcher{Name: matcher.Name, Value: value, IsRegex: matcher.IsRegex})
+	}
+	if tmpl.CompiledComment != nil {
+		comment, err := render(tmpl.CompiledComment, data)
+		if err != nil {
+			return silence, err
+		}
+		silence.Comment = comment
+	}
+	return silence, nil
+}
+
+// bulkSilence endpoint, json, creates silences for all alerts matching given
+// filters, one silence is created for every distinct label set on each
+// Alertmanager cluster alerts were collected from
+// Silence is sent to cluster members one by one until it's accepted, errors
+// from all failed members are returned with the list of created silences
+// If a silence template is used then silences are rendered from it for every
+// label set, label sets rendering the same silence share it
+// Alerts that are already silenced are skipped and only counted, requests
+// to Alertmanager instances are subject to the same rate limit as proxied
+// requests
+func bulkSilence(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	badRequest := func(reason string) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	}
+
+	req := models.BulkSilenceRequest{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		badRequest(fmt.Sprintf("invalid request body: %s", err))
+		return
+	}
+	if len(req.Filters) == 0 {
+		badRequest("at least one filter is required")
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		badRequest(fmt.Sprintf("invalid duration '%s'", req.Duration))
+		return
+	}
+	if req.CreatedBy == "" {
+		badRequest("createdBy is required")
+		return
+	}
+	var tmpl *config.SilenceTemplate
+	if req.Template != "" {
+		for i := range config.Config.Silences.Templates {
+			if config.Config.Silences.Templates[i].Name == req.Template {
+				tmpl = &config.Config.Silences.Templates[i]
+			}
+		}
+		if tmpl == nil {
+			badRequest(fmt.Sprintf("unknown silence template '%s'", req.Template))
+			return
+		}
+	}
+	if req.Comment == "" && (tmpl == nil || tmpl.CompiledComment == nil) {
+		badRequest("comment is required")
+		return
+	}
+
+	limit := filterLimit{}
+	matchFilters := []filters.FilterT{}
+	for _, expression := range req.Filters {
+		f := filters.NewFilter(expression)
+		if !f.GetIsValid() {
+			badRequest(fmt.Sprintf("invalid filter '%s': %s", expression, f.GetInvalidReason()))
+			return
+		}
+		if err := limit.add(f); err != nil {
+			badRequest(err.Error())
+			return
+		}
+		matchFilters = append(matchFilters, f)
+	}
+
+	resp := models.BulkSilenceResponse{
+		Silences: map[string][]string{},
+		Errors:   map[string][]string{},
+		Rendered: []models.Silence{},
+	}
+
+	startsAt := time.Now().UTC()
+	endsAt := startsAt.Add(duration)
+
+	// cluster ID -> silence key -> silence
+	clusterSilences := map[string]map[string]models.Silence{}
+	// silence key -> silence, used for the response
+	rendered := map[string]models.Silence{}
+	for _, ag := range alertmanager.DedupAlerts() {
+		for _, alert := range ag.Alerts {
+			alert := alert // scopelint pin
+			isMatch := true
+			for _, filter := range matchFilters {
+				if !filter.Match(&alert, resp.Alerts) {
+					isMatch = false
+				}
+			}
+			if !isMatch {
+				continue
+			}
+			if alert.IsSilenced() {
+				resp.Skipped++
+				continue
+			}
+			resp.Alerts++
+			silence := newBulkSilence(alert.Labels, startsAt, endsAt, req)
+			if tmpl != nil {
+				var err error
+				silence, err = newTemplatedSilence(*tmpl, alert.Labels, startsAt, endsAt, req)
+				if err != nil {
+					badRequest(fmt.Sprintf("failed to render silence template '%s' for alert %s: %s", tmpl.Name, alert.Labels, err))
+					return
+				}
+			}
+			key, err := json.Marshal([]interface{}{silence.Matchers, silence.Comment})
+			if err != nil {
+				log.Error(err.Error())
+				panic(err)
+			}
+			rendered[string(key)] = silence
+			for _, am := range alert.Alertmanager {
+				if _, found := clusterSilences[am.Cluster]; !found {
+					clusterSilences[am.Cluster] = map[string]models.Silence{}
+				}
+				clusterSilences[am.Cluster][string(key)] = silence
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(rendered))
+	for key := range rendered {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		resp.Rendered = append(resp.Rendered, rendered[key])
+	}
+
+	members := map[string][]*alertmanager.Alertmanager{}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		members[am.ClusterID()] = append(members[am.ClusterID()], am)
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for cluster, silences := range clusterSilences {
+		ams := members[cluster]
+		sort.Slice(ams, func(i, j int) bool {
+			return ams[i].Name < ams[j].Name
+		})
+		wg.Add(1)
+		go func(ams []*alertmanager.Alertmanager, silences map[string]models.Silence) {
+			defer wg.Done()
+			for _, silence := range silences {
+				for _, am := range ams {
+					var id string
+					var err error
+					if am.AllowProxyRequest(http.MethodPost) {
+						id, err = am.CreateSilence(silence)
+					} else {
+						err = fmt.Errorf("rate limit exceeded for Alertmanager '%s'", am.Name)
+					}
+					lock.Lock()
+					if err != nil {
+						log.Errorf("[%s] Failed to create silence: %s", am.Name, err)
+						resp.Errors[am.Name] = append(resp.Errors[am.Name], err.Error())
+					} else {
+						resp.Silences[am.Name] = append(resp.Silences[am.Name], id)
+					}
+					lock.Unlock()
+					if err == nil {
+						break
+					}
+				}
+			}
+		}(ams, silences)
+	}
+	wg.Wait()
+
+	for name := range resp.Silences {
+		sort.Strings(resp.Silences[name])
+	}
+	for name := range resp.Errors {
+		sort.Strings(resp.Errors[name])
+	}
+
+	c.JSON(http.StatusOK, resp)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// acknowledge endpoint, json, marks alert with given fingerprint as
+// acknowledged, acknowledgements are only stored in karma memory and expire
+// after configured ttl, they are not sent to Alertmanager
+func acknowledge(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	fail := func(code int, reason string) {
+		c.JSON(code, gin.H{"error": reason})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), code, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	}
+
+	req := models.AcknowledgementRequest{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		fail(http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+		return
+	}
+	if req.Fingerprint == "" {
+		fail(http.StatusBadRequest, "fingerprint is required")
+		return
+	}
+	if req.CreatedBy == "" {
+		fail(http.StatusBadRequest, "createdBy is required")
+		return
+	}
+
+	found := false
+	for _, ag := range alertmanager.DedupAlerts() {
+		for _, alert := range ag.Alerts {
+			if alert.Fingerprint == req.Fingerprint {
+				found = true
+			}
+		}
+	}
+	if !found {
+		fail(http.StatusNotFound, fmt.Sprintf("alert with fingerprint '%s' not found", req.Fingerprint))
+		return
+	}
+
+	ack := alertmanager.Acknowledge(req.Fingerprint, req.CreatedBy, config.Config.Acknowledgement.TTL)
+	// cached responses don't include this acknowledgement
+	apiCache.Flush()
+
+	c.JSON(http.StatusOK, ack)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// unacknowledge endpoint, json, removes the acknowledgement for alert with
+// fingerprint passed in the query args
+func unacknowledge(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	fail := func(code int, reason string) {
+		c.JSON(code, gin.H{"error": reason})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), code, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	}
+
+	fingerprint := c.Query("fingerprint")
+	if fingerprint == "" {
+		fail(http.StatusBadRequest, "fingerprint is required")
+		return
+	}
+
+	ack, found := alertmanager.Unacknowledge(fingerprint)
+	if !found {
+		fail(http.StatusNotFound, fmt.Sprintf("alert with fingerprint '%s' is not acknowledged", fingerprint))
+		return
+	}
+	apiCache.Flush()
+
+	c.JSON(http.StatusOK, ack)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// startMaintenance endpoint, json, starts a maintenance window, while it's
+// active all alert groups in its scope are tagged or hidden in the alerts
+// response, maintenance window is only stored in karma memory, there can be
+// only one and starting a new one replaces the previous window
+func startMaintenance(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	fail := func(code int, reason string) {
+		c.JSON(code, gin.H{"error": reason})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), code, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	}
+
+	window := models.MaintenanceWindow{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&window); err != nil {
+		fail(http.StatusBadRequest, fmt.Sprintf("invalid request body: %s", err))
+		return
+	}
+	if window.CreatedBy == "" {
+		fail(http.StatusBadRequest, "createdBy is required")
+		return
+	}
+	if window.EndsAt.IsZero() {
+		fail(http.StatusBadRequest, "endsAt is required")
+		return
+	}
+	if window.StartsAt.IsZero() {
+		window.StartsAt = start.UTC()
+	}
+	if !window.EndsAt.After(window.StartsAt) {
+		fail(http.StatusBadRequest, "endsAt must be after startsAt")
+		return
+	}
+	if !window.EndsAt.After(start) {
+		fail(http.StatusBadRequest, "endsAt must be in the future")
+		return
+	}
+	if window.Filters == nil {
+		window.Filters = []string{}
+	}
+	for _, filterExpression := range window.Filters {
+		f := filters.NewFilter(filterExpression)
+		if !f.GetIsValid() {
+			fail(http.StatusBadRequest, fmt.Sprintf("invalid filter '%s': %s", filterExpression, f.GetInvalidReason()))
+			return
+		}
+	}
+
+	maintenance.set(window)
+	// cached responses might use the previous maintenance window
+	apiCache.Flush()
+
+	c.JSON(http.StatusOK, window)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// stopMaintenance endpoint, json, removes the maintenance window
+func stopMaintenance(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	window, found := maintenance.remove()
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "there is no maintenance window"})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusNotFound, c.Request.Method, c.Request.RequestURI, time.Since(start))
+		return
+	}
+	apiCache.Flush()
+
+	c.JSON(http.StatusOK, window)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// activeSilences endpoint, json, returns all active silences collected from
+// all upstreams, silences with the same ID collected from members of the same
+// cluster are merged
+// author and comment query args will only return silences with createdBy and
+// comment containing given value, both are case insensitive
+func activeSilences(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	author := strings.ToLower(c.Query("author"))
+	comment := strings.ToLower(c.Query("comment"))
+
+	resp := []models.ManagedSilence{}
+	for _, ms := range alertmanager.DedupSilences() {
+		if author != "" && !strings.Contains(strings.ToLower(ms.Silence.CreatedBy), author) {
+			continue
+		}
+		if comment != "" && !strings.Contains(strings.ToLower(ms.Silence.Comment), comment) {
+			continue
+		}
+		if isPrivacyEnabled() {
+			ms.Silence = redactSilence(ms.Silence)
+		}
+		resp = append(resp, ms)
+	}
+
+	c.JSON(http.StatusOK, resp)
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// apiSchema endpoint, json, returns JSON Schema for types returned by the API,
+// it's generated from the Go types so it always matches the response format
+func apiSchema(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+
+	g := schema.NewGenerator()
+	g.Add("AlertsResponse", models.AlertsResponse{})
+	g.Add("APIAlertGroup", models.APIAlertGroup{})
+	g.Add("LabelNameStatsList", models.LabelNameStatsList{})
+	g.Add("AlertmanagerAPISummary", models.AlertmanagerAPISummary{})
+
+	c.JSON(http.StatusOK, g.Schema())
+	logAlertsView(c, "MIS", time.Since(start))
+}
+
+// health endpoint, returns 200 as long as karma is running
+func health(c *gin.Context) {
+	noCache(c)
+	c.String(http.StatusOK, "OK")
+}
+
+// ready endpoint, returns 200 only if there's enough healthy upstreams
+func ready(c *gin.Context) {
+	noCache(c)
+	summary := getUpstreams()
+	if summary.Counters.Healthy < config.Config.Alertmanager.MinHealthy {
+		c.String(http.StatusServiceUnavailable, "%d healthy Alertmanager server(s), required: %d", summary.Counters.Healthy, config.Config.Alertmanager.MinHealthy)
+		return
+	}
+	c.String(http.StatusOK, "OK")
+}
+
+var acceptAPIVersionRegex = regexp.MustCompile(`application/vnd\.karma\.v([0-9]+)\+json`)
+
+// getAPIVersion returns the version of the alerts response schema requested
+// by the client, it can be passed using apiVersion=N query argument or the
+// Accept header with application/vnd.karma.vN+json value, the current version
+// is used if none was requested
+func getAPIVersion(c *gin.Context) (int, error) {
+	value, found := c.GetQuery("apiVersion")
+	if !found {
+		match := acceptAPIVersionRegex.FindStringSubmatch(c.GetHeader("Accept"))
+		if match == nil {
+			return models.AlertsAPIVersion, nil
+		}
+		value = match[1]
+	}
+	apiVersion, err := strconv.Atoi(value)
+	if err != nil || apiVersion < models.AlertsAPIVersionLegacy || apiVersion > models.AlertsAPIVersion {
+		return 0, fmt.Errorf("invalid API version '%s', supported versions: %d-%d", value, models.AlertsAPIVersionLegacy, models.AlertsAPIVersion)
+	}
+	return apiVersion, nil
+}
+
+// alertsETag returns the ETag value for alerts response, it's computed from
+// request URI (so it depends on filters and sort options) and the whole
+// response except for the timestamp, which is different for every request
+func alertsETag(uri string, resp models.AlertsResponse) string {
+	resp.Timestamp = ""
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+	hash, err := slices.StringSliceToSHA1([]string{uri, string(data)})
+	if err != nil {
+		log.Error(err.Error())
+		panic(err)
+	}
+	return fmt.Sprintf("\"%s\"", hash)
+}
+
+// notModified sets the ETag header and returns true with 304 status set if
+// client already has the same response
+func notModified(c *gin.Context, etag string, start time.Time) bool {
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") != etag {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusNotModified, c.Request.Method, c.Request.RequestURI, time.Since(start))
+	return true
+}
+
+// alerts endpoint, json, JS will query this via AJAX call
+func alerts(c *gin.Context) {
+	noCache(c)
+	start := time.Now()
+	ts, _ := start.UTC().MarshalText()
+
+	if _, err := getView(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+		return
+	}
+
+	// response schema depends on the Accept header, caches must not return
+	// responses for one version to clients asking for another one
+	c.Header("Vary", "Accept")
+	apiVersion, err := getAPIVersion(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		log.Infof("[%s] <%d> %s %s took %s", c.ClientIP(), http.StatusBadRequest, c.Request.Method, c.Request.RequestURI, time.Since(start))
+		return
+	}
+
+	// initialize response object, set fields that don't require any locking
+	resp := models.AlertsResponse{}
+	resp.Status = "success"
+	resp.Timestamp = string(ts)
+	resp.Version = version
+	resp.Upstreams = getUpstreams()
+	resp.Settings = models.Settings{
+		Sorting: models.SortSettings{
+			Grid:         getDefaultSortSettings(c),
+			ValueMapping: map[string]map[string]string{},
+		},
+		StaticColorLabels:        config.Config.Labels.Color.Static,
+		AnnotationsDefaultHidden: config.Config.Annotations.Default.Hidden,
+		AnnotationsHidden:        config.Config.Annotations.Hidden,
+		AnnotationsVisible:       config.Config.Annotations.Visible,
+		SilenceForm: models.SilenceFormSettings{
+			Author: authorFromHeader(c, config.Config.SilenceForm.Author.PopulateFromHeader.Header, config.Config.SilenceForm.Author.PopulateFromHeader.ValueRegex),
+			Strip: models.SilenceFormStripSettings{
+				Labels: config.Config.SilenceForm.Strip.Labels,
+			},
+		},
+	}
+
+	if config.Config.Grid.Sorting.CustomValues.Labels != nil {
+		resp.Settings.Sorting.ValueMapping = config.Config.Grid.Sorting.CustomValues.Labels
+	}
+
+	// use full URI (including query args) as cache key
+	cacheKey := c.Request.RequestURI
+	// responses differ while there's an active maintenance window, it can start
+	// or end between collection cycles so it must be part of the key
+	window := maintenance.active(start)
+	if window != nil {
+		cacheKey += "#maintenance"
+	}
+	// API version can be set using the Accept header so it must be part of the
+	// key, skip it for the current version so ETag values don't change
+	// Cached responses always use the current schema and are converted when
+	// an older version was requested
+	if apiVersion != models.AlertsAPIVersion {
+		cacheKey = fmt.Sprintf("%s#v%d", cacheKey, apiVersion)
+	}
+	etagKey := cacheKey
+
+	data, found := apiCache.Get(cacheKey)
+	if found {
+		rawData, err := decompressCachedResponse(data.([]byte))
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
+		}
+
+		// need to overwrite settings as they can have user specific data
+		newResp := models.AlertsResponse{}
+		err = json.Unmarshal(rawData, &newResp)
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
 		}
+		newResp.Settings = resp.Settings
+		newResp.SortSettings = getSortSettings(c)
+		newResp.Timestamp = string(ts)
+		if notModified(c, alertsETag(etagKey, newResp), start) {
+			return
+		}
+		newData, err := models.MarshalAlertsResponse(newResp, apiVersion)
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
+		}
+		c.Data(http.StatusOK, gin.MIMEJSON, newData)
+		logAlertsView(c, "HIT", time.Since(start))
+		return
+	}
 
+	// get filters
+	matchFilters, validFilters, err := getFiltersFromQuery(c.Request.Context(), getFilterStrings(c))
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
+	}
+
+	filtered, err := filterAlerts(c.Request.Context(), matchFilters, validFilters, c.Query("regroupBy"), c.Query("splitBy"), getShowResolved(c), getInvert(c), window)
+	if err != nil {
+		abortAlertsView(c, err, start)
+		return
 	}
 
 	for _, filter := range matchFilters {
 		if filter.GetValue() != "" && filter.GetMatcher() == "=" {
-			transform.ColorLabel(colors, filter.GetName(), filter.GetValue())
+			transform.ColorLabel(filtered.colors, filter.GetName(), filter.GetValue())
 		}
 	}
 
-	resp.AlertGroups = sortAlertGroups(c, alerts)
-	resp.Silences = silences
-	resp.Colors = colors
-	resp.Counters = countersToLabelStats(counters)
+	resp.SortSettings = getSortSettings(c)
+	if getCountersOnly(c) {
+		// only counters were requested, skip sorting and leave out all data
+		// needed to render alert groups
+		resp.AlertGroups = []models.APIAlertGroup{}
+		resp.Silences = map[string]map[string]models.Silence{}
+		resp.Colors = models.LabelsColorMap{}
+	} else {
+		sortedGroups, err := sortAlertGroups(c, filtered.groups)
+		if err != nil {
+			abortAlertsView(c, err, start)
+			return
+		}
+		resp.AlertGroups = redactAlertGroups(truncateAlertGroups(c, paginateAlertGroups(c, sortedGroups)))
+		resp.Silences = redactSilences(filtered.silences)
+		resp.Colors = redactColors(filtered.colors)
+	}
+	resp.TotalGroups = len(filtered.groups)
+	resp.TotalAlerts = filtered.totalAlerts
+	resp.Counters = paginateLabelStats(c, countersToLabelStats(redactCounters(filtered.counters), config.Config.LabelStats.MaxValuesPerName))
 	resp.Filters = populateAPIFilters(matchFilters)
 
-	data, err := json.Marshal(resp)
+	data, err = json.Marshal(resp)
 	if err != nil {
 		log.Error(err.Error())
 		panic(err)
@@ -348,6 +1228,16 @@ func alerts(c *gin.Context) {
 	}
 	apiCache.Set(cacheKey, compressedData, -1)
 
+	if notModified(c, alertsETag(etagKey, resp), start) {
+		return
+	}
+	if apiVersion != models.AlertsAPIVersion {
+		data, err = models.MarshalAlertsResponse(resp, apiVersion)
+		if err != nil {
+			log.Error(err.Error())
+			panic(err)
+		}
+	}
 	c.Data(http.StatusOK, gin.MIMEJSON, data.([]byte))
 	logAlertsView(c, "MIS", time.Since(start))
 }
@@ -375,7 +1265,7 @@ func autocomplete(c *gin.Context) {
 
 	acData := sort.StringSlice{}
 
-	dedupedAutocomplete := alertmanager.DedupAutocomplete()
+	dedupedAutocomplete := redactAutocomplete(alertmanager.DedupAutocomplete())
 
 	for _, hint := range dedupedAutocomplete {
 		if strings.HasPrefix(strings.ToLower(hint.Value), strings.ToLower(term)) {
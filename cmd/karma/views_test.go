@@ -1,16 +1,26 @@
 package main
 
 import (
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prymitive/karma/internal/alertmanager"
 	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/filters"
 	"github.com/prymitive/karma/internal/mock"
 	"github.com/prymitive/karma/internal/models"
 	"github.com/prymitive/karma/internal/slices"
@@ -18,7 +28,9 @@ import (
 	cache "github.com/patrickmn/go-cache"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/gin-gonic/gin"
+	"github.com/google/go-cmp/cmp"
 	"github.com/jarcoal/httpmock"
 )
 
@@ -130,9 +142,38 @@ func TestAlerts(t *testing.T) {
 		if ur.Upstreams.Counters.Failed > 0 {
 			t.Errorf("[%s] %d error(s) in upstream status: %v", version, ur.Upstreams.Counters.Failed, ur.Upstreams)
 		}
+		if ur.Upstreams.Counters.RawTotal != 24 || ur.Upstreams.Counters.DedupedTotal != 24 {
+			t.Errorf("[%s] Got %d raw and %d deduplicated alert(s) in upstream counters, expected 24 and 24",
+				version, ur.Upstreams.Counters.RawTotal, ur.Upstreams.Counters.DedupedTotal)
+		}
 		if len(ur.Upstreams.Instances) == 0 {
 			t.Errorf("[%s] No instances in upstream status: %v", version, ur.Upstreams.Instances)
 		}
+		// cluster status is only exposed by Alertmanager >= 0.15.0
+		peerStatus := models.ClusterPeerStatusUnknown
+		if semver.MustParse(version).Compare(semver.MustParse("0.15.0")) >= 0 {
+			peerStatus = "ready"
+		}
+		for _, instance := range ur.Upstreams.Instances {
+			if instance.ClusterPeerStatus != peerStatus {
+				t.Errorf("[%s] Instance %s has cluster peer status %q, expected %q", version, instance.Name, instance.ClusterPeerStatus, peerStatus)
+			}
+			// mock config doesn't set any timeout so the default is used
+			if instance.Timeout != 40000 {
+				t.Errorf("[%s] Instance %s has timeout %d, expected %d", version, instance.Name, instance.Timeout, 40000)
+			}
+		}
+		if len(ur.Upstreams.ClusterHealth) != len(ur.Upstreams.Clusters) {
+			t.Errorf("[%s] Got %d cluster health entries, expected %d", version, len(ur.Upstreams.ClusterHealth), len(ur.Upstreams.Clusters))
+		}
+		for cluster, health := range ur.Upstreams.ClusterHealth {
+			if health.Degraded {
+				t.Errorf("[%s] Cluster %s is degraded: %v", version, cluster, health)
+			}
+			if health.Total == 0 || health.Healthy != health.Total {
+				t.Errorf("[%s] Cluster %s health mismatch: %v", version, cluster, health)
+			}
+		}
 		if ur.Status != "success" {
 			t.Errorf("[%s] Invalid status in response: %s", version, ur.Status)
 		}
@@ -158,6 +199,546 @@ func TestAlerts(t *testing.T) {
 	}
 }
 
+func TestAlertsFilterHits(t *testing.T) {
+	mockConfig()
+	expectedHits := map[string]int{
+		"@receiver=by-cluster-service": 12,
+		"alertname=HTTP_Probe_Failed":  4,
+		"cluster=":                     0,
+	}
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing filter hits using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		// run the same query twice to verify that hits don't accumulate
+		// across requests
+		for i := 0; i < 2; i++ {
+			apiCache.Flush()
+			req := httptest.NewRequest("GET", "/alerts.json?q=@receiver=by-cluster-service&q=alertname=HTTP_Probe_Failed&q=cluster=", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /alerts.json returned status %d", resp.Code)
+			}
+
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if len(ur.Filters) != len(expectedHits) {
+				t.Errorf("[%s] Got %d filter(s) in response, expected %d", version, len(ur.Filters), len(expectedHits))
+			}
+			for _, filter := range ur.Filters {
+				hits, found := expectedHits[filter.Text]
+				if !found {
+					t.Errorf("[%s] Unexpected filter in response: %v", version, filter)
+					continue
+				}
+				if filter.Hits != hits {
+					t.Errorf("[%s] Filter %s got %d hits, expected %d", version, filter.Text, filter.Hits, hits)
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsOrFilter(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing OR filters using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		req := httptest.NewRequest("GET", "/alerts.json?q=@receiver=by-name&q=alertname=HTTP_Probe_Failed%20OR%20alertname=Free_Disk", nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("GET /alerts.json returned status %d", resp.Code)
+		}
+
+		ur := models.AlertsResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), &ur)
+		if err != nil {
+			t.Errorf("Failed to unmarshal response: %s", err)
+		}
+		if len(ur.AlertGroups) != 2 {
+			t.Errorf("[%s] Got %d alert group(s) in response, expected %d", version, len(ur.AlertGroups), 2)
+		}
+		if ur.TotalAlerts != 3 {
+			t.Errorf("[%s] Got %d alert(s) in response, expected %d", version, ur.TotalAlerts, 3)
+		}
+		for _, ag := range ur.AlertGroups {
+			if ag.Receiver != "by-name" {
+				t.Errorf("[%s] Got alert group with receiver %s, expected by-name", version, ag.Receiver)
+			}
+		}
+	}
+}
+
+func TestAlertsFingerprintFilter(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing fingerprint filter using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		for _, q := range []string{"@fingerprint=aae7a1432b5d2f1b", "@fingerprint=~%5Eaae7", "@fingerprint_in=aae7a1432b5d2f1b,0000000000000000"} {
+			req := httptest.NewRequest("GET", "/alerts.json?q="+q, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /alerts.json returned status %d", resp.Code)
+			}
+
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			// the same alert is routed to 2 receivers
+			if ur.TotalAlerts != 2 {
+				t.Errorf("[%s] [%s] Got %d alert(s) in response, expected %d", version, q, ur.TotalAlerts, 2)
+			}
+			if len(ur.AlertGroups) != 2 {
+				t.Errorf("[%s] [%s] Got %d alert group(s) in response, expected %d", version, q, len(ur.AlertGroups), 2)
+			}
+			for _, ag := range ur.AlertGroups {
+				for _, alert := range ag.Alerts {
+					if alert.Fingerprint != "aae7a1432b5d2f1b" {
+						t.Errorf("[%s] [%s] Got alert with fingerprint '%s' in response, expected '%s'", version, q, alert.Fingerprint, "aae7a1432b5d2f1b")
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsGroupFilter(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing group filter using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func(query string) models.AlertsResponse {
+			req := httptest.NewRequest("GET", "/alerts.json?"+query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json?%s returned status %d", version, query, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur
+		}
+
+		for _, args := range []string{"regroupBy=", "regroupBy=cluster", "splitBy=instance", "regroupBy=cluster&splitBy=job"} {
+			all := getAlerts(args)
+			if len(all.AlertGroups) < 2 {
+				t.Fatalf("[%s] Got %d alert group(s), expected more than 1", version, len(all.AlertGroups))
+			}
+			for _, ag := range all.AlertGroups {
+				ur := getAlerts(args + "&q=@group=" + ag.ID)
+				if len(ur.AlertGroups) != 1 {
+					t.Errorf("[%s] [%s] @group=%s returned %d alert group(s), expected 1", version, args, ag.ID, len(ur.AlertGroups))
+					continue
+				}
+				if ur.AlertGroups[0].ID != ag.ID {
+					t.Errorf("[%s] [%s] @group=%s returned alert group %s", version, args, ag.ID, ur.AlertGroups[0].ID)
+				}
+				if len(ur.AlertGroups[0].Alerts) != len(ag.Alerts) {
+					t.Errorf("[%s] [%s] @group=%s returned %d alert(s), expected %d", version, args, ag.ID, len(ur.AlertGroups[0].Alerts), len(ag.Alerts))
+				}
+
+				ur = getAlerts(args + "&q=@group!=" + ag.ID)
+				if ur.TotalAlerts != all.TotalAlerts-len(ag.Alerts) {
+					t.Errorf("[%s] [%s] @group!=%s returned %d alert(s), expected %d", version, args, ag.ID, ur.TotalAlerts, all.TotalAlerts-len(ag.Alerts))
+				}
+			}
+
+			ur := getAlerts(args + "&q=@group=0000000000000000")
+			if ur.TotalAlerts != 0 {
+				t.Errorf("[%s] [%s] @group with unknown ID returned %d alert(s)", version, args, ur.TotalAlerts)
+			}
+		}
+	}
+}
+
+func TestAlertsLabelMissingFilter(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing label missing filter using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		for _, testCase := range []struct {
+			filter string
+			alerts int
+		}{
+			{filter: "@label_missing=disk", alerts: 22},
+			{filter: "@label_missing!=disk", alerts: 2},
+			// cluster is a group label for by-cluster-service receiver groups and
+			// an alert label in by-name groups
+			{filter: "@label_missing=cluster", alerts: 0},
+			{filter: "@label_missing!=cluster", alerts: 24},
+			{filter: "@label_missing=team", alerts: 24},
+		} {
+			req := httptest.NewRequest("GET", "/alerts.json?q="+testCase.filter, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /alerts.json returned status %d", resp.Code)
+			}
+
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if ur.TotalAlerts != testCase.alerts {
+				t.Errorf("[%s] [%s] Got %d alert(s) in response, expected %d", version, testCase.filter, ur.TotalAlerts, testCase.alerts)
+			}
+		}
+	}
+}
+
+func TestAlertsShowResolved(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Grid.ShowResolved = true }()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing showResolved using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		// v1 API mocks have no endsAt set on alerts, v2 API mocks were captured
+		// long ago and all alerts have endsAt in the past, there are no resolved
+		// alerts in the first case, in the second one every alert is resolved
+		resolvedAlerts := 0
+		if semver.MustParse(version).Compare(semver.MustParse("0.17.0")) >= 0 {
+			resolvedAlerts = 24
+		}
+
+		for _, testCase := range []struct {
+			hideByDefault bool
+			query         string
+			alerts        int
+		}{
+			{query: "", alerts: 24},
+			{query: "showResolved=1", alerts: 24},
+			{query: "showResolved=foo", alerts: 24},
+			{query: "showResolved=0", alerts: 24 - resolvedAlerts},
+			{query: "showResolved=0&q=cluster=prod", alerts: 6 - resolvedAlerts/4},
+			{hideByDefault: true, query: "", alerts: 24 - resolvedAlerts},
+			{hideByDefault: true, query: "showResolved=foo", alerts: 24 - resolvedAlerts},
+			{hideByDefault: true, query: "showResolved=1", alerts: 24},
+			{hideByDefault: true, query: "showResolved=1&q=cluster=prod", alerts: 6},
+		} {
+			config.Config.Grid.ShowResolved = !testCase.hideByDefault
+			apiCache.Flush()
+			req := httptest.NewRequest("GET", "/alerts.json?"+testCase.query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /alerts.json returned status %d", resp.Code)
+			}
+
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if ur.TotalAlerts != testCase.alerts {
+				t.Errorf("[%s] [%s] hideByDefault=%v Got %d alert(s) in response, expected %d", version, testCase.query, testCase.hideByDefault, ur.TotalAlerts, testCase.alerts)
+			}
+			// groups with only resolved alerts must be dropped
+			if testCase.alerts == 0 && len(ur.AlertGroups) != 0 {
+				t.Errorf("[%s] [%s] Got %d alert group(s) in response, expected 0", version, testCase.query, len(ur.AlertGroups))
+			}
+			for _, ag := range ur.AlertGroups {
+				if len(ag.Alerts) == 0 {
+					t.Errorf("[%s] [%s] Got an empty alert group: %v", version, testCase.query, ag)
+				}
+			}
+			// resolved alerts must not be counted in label stats
+			var hits int
+			for _, counter := range ur.Counters {
+				if counter.Name == "alertname" {
+					hits = counter.Hits
+				}
+			}
+			if hits != testCase.alerts {
+				t.Errorf("[%s] [%s] Got %d alertname hit(s) in counters, expected %d", version, testCase.query, hits, testCase.alerts)
+			}
+		}
+	}
+}
+
+func TestAlertsMaxAlertsPerGroup(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing maxAlertsPerGroup using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, testCase := range []struct {
+			query     string
+			maxAlerts int
+		}{
+			{query: "", maxAlerts: 0},
+			{query: "maxAlertsPerGroup=0", maxAlerts: 0},
+			{query: "maxAlertsPerGroup=-1", maxAlerts: 0},
+			{query: "maxAlertsPerGroup=abc", maxAlerts: 0},
+			{query: "maxAlertsPerGroup=1", maxAlerts: 1},
+			{query: "maxAlertsPerGroup=2", maxAlerts: 2},
+			{query: "maxAlertsPerGroup=100", maxAlerts: 0},
+		} {
+			responses := []models.AlertsResponse{}
+			// the second request uses a different URI so it's not served from cache
+			for _, uri := range []string{"/alerts.json?" + testCase.query, "/alerts.json?" + testCase.query + "&nocache=1"} {
+				req := httptest.NewRequest("GET", uri, nil)
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+				if resp.Code != http.StatusOK {
+					t.Errorf("GET %s returned status %d", uri, resp.Code)
+				}
+				ur := models.AlertsResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ur)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+				responses = append(responses, ur)
+			}
+
+			ur := responses[0]
+			if ur.TotalAlerts != 24 {
+				t.Errorf("[%s] [%s] Got %d total alert(s), expected 24", version, testCase.query, ur.TotalAlerts)
+			}
+			var alerts, truncated int
+			for _, ag := range ur.AlertGroups {
+				if testCase.maxAlerts > 0 && len(ag.Alerts) > testCase.maxAlerts {
+					t.Errorf("[%s] [%s] Group %s has %d alert(s)", version, testCase.query, ag.ID, len(ag.Alerts))
+				}
+				if testCase.maxAlerts == 0 && ag.TruncatedAlerts != 0 {
+					t.Errorf("[%s] [%s] Group %s has %d truncated alert(s), expected 0", version, testCase.query, ag.ID, ag.TruncatedAlerts)
+				}
+				var stateCount int
+				for _, count := range ag.StateCount {
+					stateCount += count
+				}
+				if stateCount != len(ag.Alerts)+ag.TruncatedAlerts {
+					t.Errorf("[%s] [%s] Group %s state counters sum to %d, expected %d", version, testCase.query, ag.ID, stateCount, len(ag.Alerts)+ag.TruncatedAlerts)
+				}
+				alerts += len(ag.Alerts)
+				truncated += ag.TruncatedAlerts
+			}
+			if alerts+truncated != 24 {
+				t.Errorf("[%s] [%s] Got %d alert(s) and %d truncated alert(s), expected 24 in total", version, testCase.query, alerts, truncated)
+			}
+			if testCase.maxAlerts > 0 && truncated == 0 {
+				t.Errorf("[%s] [%s] No alerts were truncated", version, testCase.query)
+			}
+			for _, counter := range ur.Counters {
+				if counter.Name == "alertname" && counter.Hits != 24 {
+					t.Errorf("[%s] [%s] Got %d alertname hit(s) in counters, expected 24", version, testCase.query, counter.Hits)
+				}
+			}
+
+			// groups with the same timestamp can be returned in any order, so
+			// compare alerts in each group
+			groupAlerts := func(groups []models.APIAlertGroup) map[string]string {
+				alerts := map[string]string{}
+				for _, ag := range groups {
+					data, _ := json.Marshal(ag.Alerts)
+					alerts[ag.ID] = string(data)
+				}
+				return alerts
+			}
+			if diff := cmp.Diff(groupAlerts(responses[0].AlertGroups), groupAlerts(responses[1].AlertGroups)); diff != "" {
+				t.Errorf("[%s] [%s] Truncated alert groups differ between requests (-first +second):\n%s", version, testCase.query, diff)
+			}
+		}
+	}
+}
+
+func TestAlertsETag(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alerts ETag using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		uri := "/alerts.json?q=@receiver=by-name&sortOrder=label&sortLabel=alertname&sortReverse=0"
+		req := httptest.NewRequest("GET", uri, nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+		}
+		etag := resp.Header().Get("ETag")
+		if etag == "" {
+			t.Errorf("[%s] GET %s returned empty ETag", version, uri)
+		}
+
+		// response is now cached, ETag should be the same
+		for _, ifNoneMatch := range []string{"", "\"foo\""} {
+			req = httptest.NewRequest("GET", uri, nil)
+			req.Header.Set("If-None-Match", ifNoneMatch)
+			resp = httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET %s with If-None-Match '%s' returned status %d", version, uri, ifNoneMatch, resp.Code)
+			}
+			if resp.Header().Get("ETag") != etag {
+				t.Errorf("[%s] GET %s returned ETag '%s', expected '%s'", version, uri, resp.Header().Get("ETag"), etag)
+			}
+		}
+
+		req = httptest.NewRequest("GET", uri, nil)
+		req.Header.Set("If-None-Match", etag)
+		resp = httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusNotModified {
+			t.Errorf("[%s] GET %s with matching If-None-Match returned status %d", version, uri, resp.Code)
+		}
+		if resp.Body.Len() != 0 {
+			t.Errorf("[%s] GET %s with matching If-None-Match returned non empty body", version, uri)
+		}
+
+		// same ETag but uncached response should also return 304
+		apiCache.Flush()
+		resp = httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusNotModified {
+			t.Errorf("[%s] GET %s with matching If-None-Match and empty cache returned status %d", version, uri, resp.Code)
+		}
+
+		// reversed sort order is a different response
+		reversedURI := "/alerts.json?q=@receiver=by-name&sortOrder=label&sortLabel=alertname&sortReverse=1"
+		req = httptest.NewRequest("GET", reversedURI, nil)
+		req.Header.Set("If-None-Match", etag)
+		resp = httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("[%s] GET %s returned status %d", version, reversedURI, resp.Code)
+		}
+		if resp.Header().Get("ETag") == etag {
+			t.Errorf("[%s] GET %s returned the same ETag as %s", version, reversedURI, uri)
+		}
+
+		// alert groups are the same but colors are not, so it's a different
+		// response
+		config.Config.Labels.Color.Unique = []string{"alertname", "cluster"}
+		mockAlerts(version)
+		req = httptest.NewRequest("GET", uri, nil)
+		req.Header.Set("If-None-Match", etag)
+		resp = httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("[%s] GET %s with different colors returned status %d", version, uri, resp.Code)
+		}
+		if resp.Header().Get("ETag") == etag {
+			t.Errorf("[%s] GET %s with different colors returned the same ETag", version, uri)
+		}
+		config.Config.Labels.Color.Unique = []string{"alertname"}
+	}
+}
+
+func TestHealthAndReady(t *testing.T) {
+	type healthTest struct {
+		path       string
+		minHealthy int
+		code       int
+	}
+	healthTests := []healthTest{
+		{path: "/health", minHealthy: 1, code: http.StatusOK},
+		{path: "/health", minHealthy: 2, code: http.StatusOK},
+		{path: "/ready", minHealthy: 0, code: http.StatusOK},
+		{path: "/ready", minHealthy: 1, code: http.StatusOK},
+		{path: "/ready", minHealthy: 2, code: http.StatusServiceUnavailable},
+	}
+
+	mockConfig()
+	defer func() {
+		config.Config.Alertmanager.MinHealthy = 1
+	}()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing health checks using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+		for _, testCase := range healthTests {
+			config.Config.Alertmanager.MinHealthy = testCase.minHealthy
+			req := httptest.NewRequest("GET", testCase.path, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] GET %s with minHealthy=%d returned status %d, expected %d", version, testCase.path, testCase.minHealthy, resp.Code, testCase.code)
+			}
+		}
+	}
+}
+
+func TestAlertsPagination(t *testing.T) {
+	type paginationTest struct {
+		args   string
+		offset int
+		count  int
+	}
+	paginationTests := []paginationTest{
+		{args: "", offset: 0, count: 10},
+		{args: "&limit=0", offset: 0, count: 10},
+		{args: "&limit=3", offset: 0, count: 3},
+		{args: "&limit=3&offset=3", offset: 3, count: 3},
+		{args: "&limit=5&offset=8", offset: 8, count: 2},
+		{args: "&offset=9", offset: 9, count: 1},
+		{args: "&offset=10", offset: 10, count: 0},
+		{args: "&limit=5&offset=100", offset: 10, count: 0},
+		{args: "&offset=-1", offset: 0, count: 10},
+		{args: "&limit=-1", offset: 0, count: 10},
+		{args: "&limit=abc&offset=def", offset: 0, count: 10},
+	}
+
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alerts pagination using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getGroups := func(uri string) models.AlertsResponse {
+			req := httptest.NewRequest("GET", uri, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("[%s] Failed to unmarshal response: %s", version, err)
+			}
+			return ur
+		}
+
+		all := getGroups("/alerts.json?sortOrder=disabled")
+		for _, testCase := range paginationTests {
+			uri := "/alerts.json?sortOrder=disabled" + testCase.args
+			ur := getGroups(uri)
+			if ur.TotalGroups != len(all.AlertGroups) {
+				t.Errorf("[%s] GET %s returned totalGroups=%d, expected %d", version, uri, ur.TotalGroups, len(all.AlertGroups))
+			}
+			if len(ur.AlertGroups) != testCase.count {
+				t.Errorf("[%s] GET %s returned %d group(s), expected %d", version, uri, len(ur.AlertGroups), testCase.count)
+				continue
+			}
+			for i, ag := range ur.AlertGroups {
+				if ag.ID != all.AlertGroups[testCase.offset+i].ID {
+					t.Errorf("[%s] GET %s returned group %s at position %d, expected %s", version, uri, ag.ID, i, all.AlertGroups[testCase.offset+i].ID)
+				}
+			}
+		}
+	}
+}
+
 func TestValidateAllAlerts(t *testing.T) {
 	mockConfig()
 	for _, version := range mock.ListAllMocks() {
@@ -268,10 +849,16 @@ var acTests = []acTestCase{
 	{
 		Term: "@st",
 		Results: []string{
+			"@state_changed>1h",
+			"@state_changed>10m",
+			"@state_changed<1h",
+			"@state_changed<10m",
 			"@state=suppressed",
 			"@state=active",
 			"@state!=suppressed",
 			"@state!=active",
+			"@started_between=09:00-17:00",
+			"@started_between!=09:00-17:00",
 		},
 	},
 	{
@@ -505,10 +1092,57 @@ func TestGzipMiddlewareWithoutAcceptEncoding(t *testing.T) {
 	}
 }
 
-func TestValidateAuthorFromHeaders(t *testing.T) {
-	type testValidateAuthorFromHeaders struct {
-		configHeader       string
-		configRegex        string
+func TestGzipAlertsResponse(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing gzip alerts response using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, acceptEncoding := range []string{"", "gzip"} {
+			req := httptest.NewRequest("GET", "/alerts.json", nil)
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("GET /alerts.json returned status %d", resp.Code)
+			}
+
+			ce := resp.Header().Get("Content-Encoding")
+			if ce != acceptEncoding {
+				t.Errorf("[%s] Invalid 'Content-Encoding' in response, expected '%s', got '%s'", version, acceptEncoding, ce)
+			}
+
+			body := resp.Body.Bytes()
+			if ce == "gzip" {
+				z, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Errorf("[%s] Failed to create gzip reader: %s", version, err)
+					continue
+				}
+				body, err = ioutil.ReadAll(z)
+				if err != nil {
+					t.Errorf("[%s] Failed to decompress response: %s", version, err)
+					continue
+				}
+			}
+
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(body, &ur)
+			if err != nil {
+				t.Errorf("[%s] Failed to unmarshal response with Accept-Encoding '%s': %s", version, acceptEncoding, err)
+			}
+			if ur.TotalAlerts != 24 {
+				t.Errorf("[%s] Got %d alert(s) in response with Accept-Encoding '%s', expected %d", version, ur.TotalAlerts, acceptEncoding, 24)
+			}
+		}
+	}
+}
+
+func TestValidateAuthorFromHeaders(t *testing.T) {
+	type testValidateAuthorFromHeaders struct {
+		configHeader       string
+		configRegex        string
 		requestHeaderName  string
 		requestHeaderValue string
 		expectedAuthor     string
@@ -584,3 +1218,1647 @@ func TestValidateAuthorFromHeaders(t *testing.T) {
 		}
 	}
 }
+
+var filterCheckTests = []struct {
+	filter   string
+	response models.FilterCheckResponse
+}{
+	{
+		filter: "@state=active",
+		response: func() models.FilterCheckResponse {
+			r := models.FilterCheckResponse{Valid: true}
+			r.Parsed.Name = "@state"
+			r.Parsed.Matcher = "="
+			r.Parsed.Value = "active"
+			return r
+		}(),
+	},
+	{
+		filter:   "@state>active",
+		response: models.FilterCheckResponse{Valid: false, Reason: "operator '>' is not supported by '@state' filter"},
+	},
+	{
+		filter:   "@foo=bar",
+		response: models.FilterCheckResponse{Valid: false, Reason: "unknown filter '@foo'"},
+	},
+}
+
+func TestFilterCheck(t *testing.T) {
+	mockConfig()
+	r := ginTestEngine()
+
+	req := httptest.NewRequest("GET", "/filterCheck", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Errorf("Invalid status code for request without any query: %d", resp.Code)
+	}
+
+	for _, fc := range filterCheckTests {
+		uri := fmt.Sprintf("/filterCheck?filter=%s", url.QueryEscape(fc.filter))
+		req := httptest.NewRequest("GET", uri, nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("GET %s returned status %d", uri, resp.Code)
+		}
+
+		ur := models.FilterCheckResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), &ur)
+		if err != nil {
+			t.Errorf("Failed to unmarshal response: %s", err)
+		}
+		if ur != fc.response {
+			t.Errorf("GET %s returned %+v, expected %+v", uri, ur, fc.response)
+		}
+	}
+}
+
+func TestFilterDiff(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing filter diff using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		// returns sorted fingerprints of all alerts passing given label check
+		fingerprints := func(check func(labels map[string]string) bool) []string {
+			fps := map[string]bool{}
+			for _, ag := range alertmanager.DedupAlerts() {
+				for _, alert := range ag.Alerts {
+					if alert.Fingerprint != "" && check(alert.Labels) {
+						fps[alert.Fingerprint] = true
+					}
+				}
+			}
+			result := []string{}
+			for fp := range fps {
+				result = append(result, fp)
+			}
+			sort.Strings(result)
+			return result
+		}
+		hostDown := func(labels map[string]string) bool { return labels["alertname"] == "Host_Down" }
+		dev := func(labels map[string]string) bool { return labels["cluster"] == "dev" }
+		httpProbe := func(labels map[string]string) bool { return labels["alertname"] == "HTTP_Probe_Failed" }
+
+		for _, testCase := range []struct {
+			body  string
+			code  int
+			onlyA []string
+			onlyB []string
+			both  []string
+		}{
+			{body: `{"a":`, code: 400},
+			{body: `{"b":["alertname=Host_Down"]}`, code: 400},
+			{body: `{"a":["alertname=Host_Down"]}`, code: 400},
+			{body: `{"a":["alertname=Host_Down"],"b":["@link_count=foo"]}`, code: 400},
+			{
+				// identical filters
+				body:  `{"a":["alertname=Host_Down"],"b":["alertname=Host_Down"]}`,
+				code:  200,
+				onlyA: []string{},
+				onlyB: []string{},
+				both:  fingerprints(hostDown),
+			},
+			{
+				// disjoint filters
+				body:  `{"a":["alertname=Host_Down"],"b":["alertname=HTTP_Probe_Failed"]}`,
+				code:  200,
+				onlyA: fingerprints(hostDown),
+				onlyB: fingerprints(httpProbe),
+				both:  []string{},
+			},
+			{
+				// overlapping filters
+				body:  `{"a":["alertname=Host_Down"],"b":["cluster=dev"]}`,
+				code:  200,
+				onlyA: fingerprints(func(l map[string]string) bool { return hostDown(l) && !dev(l) }),
+				onlyB: fingerprints(func(l map[string]string) bool { return dev(l) && !hostDown(l) }),
+				both:  fingerprints(func(l map[string]string) bool { return hostDown(l) && dev(l) }),
+			},
+			{
+				// more specific filters
+				body:  `{"a":["alertname=Host_Down"],"b":["alertname=Host_Down","cluster=dev"]}`,
+				code:  200,
+				onlyA: fingerprints(func(l map[string]string) bool { return hostDown(l) && !dev(l) }),
+				onlyB: []string{},
+				both:  fingerprints(func(l map[string]string) bool { return hostDown(l) && dev(l) }),
+			},
+		} {
+			req := httptest.NewRequest("POST", "/filterDiff", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] POST /filterDiff with %s returned status %d, expected %d", version, testCase.body, resp.Code, testCase.code)
+				continue
+			}
+			if resp.Code != http.StatusOK {
+				continue
+			}
+
+			ur := models.FilterDiffResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			for _, bucket := range []struct {
+				name     string
+				got      models.FilterDiffBucket
+				expected []string
+			}{
+				{name: "onlyA", got: ur.OnlyA, expected: testCase.onlyA},
+				{name: "onlyB", got: ur.OnlyB, expected: testCase.onlyB},
+				{name: "both", got: ur.Both, expected: testCase.both},
+			} {
+				if bucket.got.Count != len(bucket.got.Fingerprints) {
+					t.Errorf("[%s] POST /filterDiff with %s returned %s count %d with %d fingerprint(s)", version, testCase.body, bucket.name, bucket.got.Count, len(bucket.got.Fingerprints))
+				}
+				if diff := cmp.Diff(bucket.expected, bucket.got.Fingerprints); diff != "" {
+					t.Errorf("[%s] POST /filterDiff with %s returned wrong %s fingerprints (-want +got):\n%s", version, testCase.body, bucket.name, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsRegroupBy(t *testing.T) {
+	type regroupTest struct {
+		regroupBy string
+		groups    map[string]int
+	}
+	regroupTests := []regroupTest{
+		{regroupBy: "cluster", groups: map[string]int{"dev": 10, "staging": 8, "prod": 6}},
+		{regroupBy: "job", groups: map[string]int{"node_ping": 16, "node_exporter": 8}},
+		{regroupBy: "foo", groups: map[string]int{"": 24}},
+	}
+
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alerts regroupBy using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, testCase := range regroupTests {
+			uri := fmt.Sprintf("/alerts.json?regroupBy=%s", testCase.regroupBy)
+			ids := map[string]string{}
+			for i := 0; i < 2; i++ {
+				apiCache.Flush()
+				req := httptest.NewRequest("GET", uri, nil)
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+				if resp.Code != http.StatusOK {
+					t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+				}
+				ur := models.AlertsResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ur)
+				if err != nil {
+					t.Errorf("[%s] Failed to unmarshal response: %s", version, err)
+				}
+
+				got := map[string]int{}
+				for _, ag := range ur.AlertGroups {
+					value := ag.Labels[testCase.regroupBy]
+					got[value] = len(ag.Alerts)
+					if id, found := ids[value]; found && id != ag.ID {
+						t.Errorf("[%s] GET %s returned different ID for group %s: %s != %s", version, uri, value, id, ag.ID)
+					}
+					ids[value] = ag.ID
+				}
+				if diff := cmp.Diff(testCase.groups, got); diff != "" {
+					t.Errorf("[%s] GET %s returned wrong groups (-want +got):\n%s", version, uri, diff)
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsSplitBy(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alerts splitBy using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func(query string) models.AlertsResponse {
+			apiCache.Flush()
+			req := httptest.NewRequest("GET", "/alerts.json?"+query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json?%s returned status %d", version, query, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur
+		}
+
+		all := getAlerts("")
+		for _, label := range []string{"instance", "job", "foo"} {
+			ur := getAlerts("splitBy=" + label)
+			if ur.TotalAlerts != all.TotalAlerts {
+				t.Errorf("[%s] splitBy=%s returned %d alert(s), expected %d", version, label, ur.TotalAlerts, all.TotalAlerts)
+			}
+			if len(ur.AlertGroups) < len(all.AlertGroups) {
+				t.Errorf("[%s] splitBy=%s returned %d group(s), expected at least %d", version, label, len(ur.AlertGroups), len(all.AlertGroups))
+			}
+			if label == "foo" && len(ur.AlertGroups) != len(all.AlertGroups) {
+				t.Errorf("[%s] splitBy=%s returned %d group(s), expected %d", version, label, len(ur.AlertGroups), len(all.AlertGroups))
+			}
+
+			ids := map[string]bool{}
+			for _, ag := range ur.AlertGroups {
+				ids[ag.ID] = true
+				// grouping labels are removed from alerts in the response, so the
+				// split label can only be present on the group itself
+				if _, found := ag.Shared.Labels[label]; found {
+					t.Errorf("[%s] splitBy=%s group %v has %s in shared labels", version, label, ag.Labels, label)
+				}
+				for _, alert := range ag.Alerts {
+					if _, found := alert.Labels[label]; found {
+						t.Errorf("[%s] splitBy=%s group %v contains alert %v", version, label, ag.Labels, alert.Labels)
+					}
+				}
+			}
+
+			again := getAlerts("splitBy=" + label)
+			for _, ag := range again.AlertGroups {
+				if !ids[ag.ID] {
+					t.Errorf("[%s] splitBy=%s returned unknown group ID %s on the second request", version, label, ag.ID)
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsSortSettings(t *testing.T) {
+	type sortSettingsTest struct {
+		args     string
+		settings models.GridSettings
+	}
+	sortSettingsTests := []sortSettingsTest{
+		{
+			args:     "",
+			settings: models.GridSettings{Order: "startsAt", Reverse: true, Label: "alertname"},
+		},
+		{
+			args:     "sortOrder=label&sortReverse=0&sortLabel=cluster&sortLabelSecondary=instance",
+			settings: models.GridSettings{Order: "label", Reverse: false, Label: "cluster", SecondaryLabel: "instance"},
+		},
+		{
+			args:     "sortOrder=&sortReverse=foo&sortLabel=",
+			settings: models.GridSettings{Order: "startsAt", Reverse: true, Label: "alertname"},
+		},
+		{
+			args:     "sortReverse=1&sortOrder=alertCount",
+			settings: models.GridSettings{Order: "alertCount", Reverse: true, Label: "alertname"},
+		},
+	}
+
+	mockConfig()
+	mockAlerts("0.19.0")
+	r := ginTestEngine()
+	for _, testCase := range sortSettingsTests {
+		uri := "/alerts.json?" + testCase.args
+		req := httptest.NewRequest("GET", uri, nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("GET %s returned status %d", uri, resp.Code)
+		}
+		ur := models.AlertsResponse{}
+		err := json.Unmarshal(resp.Body.Bytes(), &ur)
+		if err != nil {
+			t.Errorf("Failed to unmarshal response: %s", err)
+		}
+		if diff := cmp.Diff(testCase.settings, ur.SortSettings); diff != "" {
+			t.Errorf("GET %s returned wrong sortSettings (-want +got):\n%s", uri, diff)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	mockConfig()
+	r := ginTestEngine()
+	req := httptest.NewRequest("GET", "/schema", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Errorf("GET /schema returned status %d", resp.Code)
+	}
+
+	type definition struct {
+		Type       string                 `json:"type"`
+		Properties map[string]interface{} `json:"properties"`
+		Items      map[string]string      `json:"items"`
+	}
+	ur := struct {
+		Definitions map[string]definition `json:"definitions"`
+	}{}
+	err := json.Unmarshal(resp.Body.Bytes(), &ur)
+	if err != nil {
+		t.Errorf("Failed to unmarshal response: %s", err)
+	}
+
+	for name, fields := range map[string][]string{
+		"AlertsResponse":         {"status", "timestamp", "version", "upstreams", "silences", "groups", "totalAlerts", "colors", "filters", "counters", "settings"},
+		"APIAlertGroup":          {"receiver", "labels", "alerts", "id", "hash", "alertmanagerCount", "stateCount", "shared"},
+		"AlertmanagerAPISummary": {"counters", "instances", "clusters"},
+		"Alert":                  {"annotations", "labels", "startsAt", "state", "alertmanager", "receiver"},
+	} {
+		d, found := ur.Definitions[name]
+		if !found {
+			t.Errorf("Schema is missing %s definition", name)
+			continue
+		}
+		if d.Type != "object" {
+			t.Errorf("%s has type %q, expected object", name, d.Type)
+		}
+		for _, field := range fields {
+			if _, found := d.Properties[field]; !found {
+				t.Errorf("%s definition is missing %s property", name, field)
+			}
+		}
+	}
+
+	stats := ur.Definitions["LabelNameStatsList"]
+	if stats.Type != "array" || stats.Items["$ref"] != "#/definitions/LabelNameStats" {
+		t.Errorf("Wrong LabelNameStatsList definition: %+v", stats)
+	}
+}
+
+type bulkSilenceTest struct {
+	body     string
+	upstream int
+	code     int
+	alerts   int
+	skipped  int
+	silences int
+	errors   int
+}
+
+var bulkSilenceTests = []bulkSilenceTest{
+	{
+		body:     `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code:     200,
+		upstream: 200,
+		alerts:   6,
+		silences: 3,
+	},
+	{
+		body:     `{"filters":["alertname=Free_Disk_Space_Too_Low"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code:     200,
+		upstream: 200,
+		alerts:   2,
+		silences: 1,
+	},
+	{
+		body:     `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code:     200,
+		upstream: 500,
+		alerts:   6,
+		errors:   3,
+	},
+	{
+		body:     `{"filters":["cluster=foo"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code:     200,
+		upstream: 200,
+	},
+	{
+		// only web2 isn't silenced
+		body:     `{"filters":["cluster=dev"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code:     200,
+		upstream: 200,
+		alerts:   2,
+		skipped:  8,
+		silences: 1,
+	},
+	{
+		body: `{"filters":[],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":["cluster=prod","@age=foo"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":["cluster=prod"],"duration":"foo","createdBy":"me@example.com","comment":"bulk"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":["cluster=prod"],"duration":"-1h","createdBy":"me@example.com","comment":"bulk"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":["cluster=prod"],"duration":"1h","comment":"bulk"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com"}`,
+		code: 400,
+	},
+	{
+		body: `{"filters":`,
+		code: 400,
+	},
+}
+
+func TestBulkSilence(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing bulk silences using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, testCase := range bulkSilenceTests {
+			testCase := testCase // scopelint pin
+			httpmock.Activate()
+			var created int
+			responder := func(req *http.Request) (*http.Response, error) {
+				if testCase.upstream != 200 {
+					return httpmock.NewStringResponse(testCase.upstream, "error"), nil
+				}
+				created++
+				if req.URL.Path == "/api/v2/silences" {
+					return httpmock.NewStringResponse(200, fmt.Sprintf(`{"silenceID":"silence%d"}`, created)), nil
+				}
+				return httpmock.NewStringResponse(200, fmt.Sprintf(`{"status":"success","data":{"silenceId":"silence%d"}}`, created)), nil
+			}
+			httpmock.RegisterResponder("POST", "http://localhost/api/v1/silences", responder)
+			httpmock.RegisterResponder("POST", "http://localhost/api/v2/silences", responder)
+
+			req := httptest.NewRequest("POST", "/silences/bulk", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			httpmock.DeactivateAndReset()
+
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] POST /silences/bulk with %s returned status %d, expected %d", version, testCase.body, resp.Code, testCase.code)
+				continue
+			}
+			if resp.Code != http.StatusOK {
+				continue
+			}
+
+			ur := models.BulkSilenceResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if ur.Alerts != testCase.alerts {
+				t.Errorf("[%s] %s matched %d alert(s), expected %d", version, testCase.body, ur.Alerts, testCase.alerts)
+			}
+			if ur.Skipped != testCase.skipped {
+				t.Errorf("[%s] %s skipped %d silenced alert(s), expected %d", version, testCase.body, ur.Skipped, testCase.skipped)
+			}
+			if len(ur.Silences["default"]) != testCase.silences {
+				t.Errorf("[%s] %s created %d silence(s), expected %d: %v", version, testCase.body, len(ur.Silences["default"]), testCase.silences, ur.Silences)
+			}
+			if len(ur.Errors["default"]) != testCase.errors {
+				t.Errorf("[%s] %s returned %d error(s), expected %d: %v", version, testCase.body, len(ur.Errors["default"]), testCase.errors, ur.Errors)
+			}
+			if created != testCase.silences {
+				t.Errorf("[%s] %s sent %d silence(s) upstream, expected %d", version, testCase.body, created, testCase.silences)
+			}
+		}
+	}
+}
+
+func TestBulkSilenceRateLimit(t *testing.T) {
+	mockConfig()
+	mockAlerts(mock.ListAllMocks()[0])
+	r := ginTestEngine()
+
+	am := alertmanager.GetAlertmanagerByName("default")
+	if err := alertmanager.WithRateLimit(0.001, 2, false)(am); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = alertmanager.WithRateLimit(0, 0, false)(am) }()
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost/api/v1/silences", httpmock.NewStringResponder(200, `{"status":"success","data":{"silenceId":"silence"}}`))
+	httpmock.RegisterResponder("POST", "http://localhost/api/v2/silences", httpmock.NewStringResponder(200, `{"silenceID":"silence"}`))
+
+	// cluster=prod renders 3 silences but only 2 requests are allowed
+	req := httptest.NewRequest("POST", "/silences/bulk", strings.NewReader(`{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`))
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("POST /silences/bulk returned status %d", resp.Code)
+	}
+
+	ur := models.BulkSilenceResponse{}
+	if err := json.Unmarshal(resp.Body.Bytes(), &ur); err != nil {
+		t.Errorf("Failed to unmarshal response: %s", err)
+	}
+	if len(ur.Silences["default"]) != 2 {
+		t.Errorf("Created %d silence(s), expected 2: %v", len(ur.Silences["default"]), ur.Silences)
+	}
+	if diff := cmp.Diff([]string{"rate limit exceeded for Alertmanager 'default'"}, ur.Errors["default"]); diff != "" {
+		t.Errorf("Wrong errors (-want +got):\n%s", diff)
+	}
+	if calls := httpmock.GetTotalCallCount(); calls != 2 {
+		t.Errorf("Got %d request(s) sent to Alertmanager, expected 2", calls)
+	}
+}
+
+func TestBulkSilenceTemplate(t *testing.T) {
+	type bulkSilenceTemplateTest struct {
+		body     string
+		code     int
+		rendered []models.Silence
+	}
+	bulkSilenceTemplateTests := []bulkSilenceTemplateTest{
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"host"}`,
+			code: 200,
+			rendered: []models.Silence{
+				{Matchers: []models.SilenceMatcher{{Name: "instance", Value: "server1"}}, Comment: "bulk on server1"},
+				{Matchers: []models.SilenceMatcher{{Name: "instance", Value: "server2"}}, Comment: "bulk on server2"},
+			},
+		},
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"alert"}`,
+			code: 200,
+			rendered: []models.Silence{
+				{Matchers: []models.SilenceMatcher{{Name: "alertname", Value: "Host_Down.*", IsRegex: true}, {Name: "cluster", Value: "prod"}}, Comment: "bulk"},
+				{Matchers: []models.SilenceMatcher{{Name: "alertname", Value: "Memory_Usage_Too_High.*", IsRegex: true}, {Name: "cluster", Value: "prod"}}, Comment: "bulk"},
+			},
+		},
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","template":"host"}`,
+			code: 200,
+			rendered: []models.Silence{
+				{Matchers: []models.SilenceMatcher{{Name: "instance", Value: "server1"}}, Comment: " on server1"},
+				{Matchers: []models.SilenceMatcher{{Name: "instance", Value: "server2"}}, Comment: " on server2"},
+			},
+		},
+		{
+			// Memory_Usage_Too_High alert has no ip label
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"ip"}`,
+			code: 400,
+		},
+		{
+			body: `{"filters":["cluster=prod","alertname=Host_Down"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"ip"}`,
+			code: 200,
+			rendered: []models.Silence{
+				{Matchers: []models.SilenceMatcher{{Name: "ip", Value: "127.0.0.1"}}, Comment: "bulk"},
+				{Matchers: []models.SilenceMatcher{{Name: "ip", Value: "127.0.0.2"}}, Comment: "bulk"},
+			},
+		},
+		{
+			// label values are escaped in regex matchers
+			body: `{"filters":["cluster=prod","alertname=Host_Down"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"ipregex"}`,
+			code: 200,
+			rendered: []models.Silence{
+				{Matchers: []models.SilenceMatcher{{Name: "ip", Value: `127\.0\.0\.1(:[0-9]+)?`, IsRegex: true}}, Comment: "bulk"},
+				{Matchers: []models.SilenceMatcher{{Name: "ip", Value: `127\.0\.0\.2(:[0-9]+)?`, IsRegex: true}}, Comment: "bulk"},
+			},
+		},
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","template":"alert"}`,
+			code: 400,
+		},
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"call"}`,
+			code: 400,
+		},
+		{
+			body: `{"filters":["cluster=prod"],"duration":"1h","createdBy":"me@example.com","comment":"bulk","template":"foo"}`,
+			code: 400,
+		},
+	}
+
+	configFile, err := ioutil.TempFile("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(configFile.Name())
+	_, err = configFile.WriteString(`silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+      comment: "{{ .Comment }} on {{ .Labels.instance }}"
+    - name: alert
+      matchers:
+        - name: alertname
+          value: "{{ .Labels.alertname }}.*"
+          isRegex: true
+        - name: cluster
+          value: "{{ .Labels.cluster }}"
+    - name: ip
+      matchers:
+        - name: ip
+          value: "{{ .Labels.ip }}"
+    - name: ipregex
+      matchers:
+        - name: ip
+          value: "{{ .Labels.ip }}(:[0-9]+)?"
+          isRegex: true
+    - name: call
+      matchers:
+        - name: instance
+          value: "{{ call .Labels.instance }}"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile.Close()
+
+	os.Setenv("CONFIG_FILE", configFile.Name())
+	defer os.Unsetenv("CONFIG_FILE")
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing bulk silence templates using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, testCase := range bulkSilenceTemplateTests {
+			httpmock.Activate()
+			var created int
+			responder := func(req *http.Request) (*http.Response, error) {
+				created++
+				if req.URL.Path == "/api/v2/silences" {
+					return httpmock.NewStringResponse(200, fmt.Sprintf(`{"silenceID":"silence%d"}`, created)), nil
+				}
+				return httpmock.NewStringResponse(200, fmt.Sprintf(`{"status":"success","data":{"silenceId":"silence%d"}}`, created)), nil
+			}
+			httpmock.RegisterResponder("POST", "http://localhost/api/v1/silences", responder)
+			httpmock.RegisterResponder("POST", "http://localhost/api/v2/silences", responder)
+
+			req := httptest.NewRequest("POST", "/silences/bulk", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			httpmock.DeactivateAndReset()
+
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] POST /silences/bulk with %s returned status %d, expected %d", version, testCase.body, resp.Code, testCase.code)
+				continue
+			}
+			if created != len(testCase.rendered) {
+				t.Errorf("[%s] %s sent %d silence(s) upstream, expected %d", version, testCase.body, created, len(testCase.rendered))
+			}
+			if resp.Code != http.StatusOK {
+				continue
+			}
+
+			ur := models.BulkSilenceResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			rendered := []models.Silence{}
+			for _, silence := range ur.Rendered {
+				if silence.CreatedBy != "me@example.com" || !silence.EndsAt.After(silence.StartsAt) {
+					t.Errorf("[%s] %s rendered invalid silence: %+v", version, testCase.body, silence)
+				}
+				rendered = append(rendered, models.Silence{Matchers: silence.Matchers, Comment: silence.Comment})
+			}
+			if diff := cmp.Diff(testCase.rendered, rendered); diff != "" {
+				t.Errorf("[%s] %s rendered wrong silences (-want +got):\n%s", version, testCase.body, diff)
+			}
+		}
+	}
+}
+
+func TestAlertsInvert(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing inverted filters using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func(query string) models.AlertsResponse {
+			req := httptest.NewRequest("GET", "/alerts.json?"+query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json?%s returned status %d", version, query, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur
+		}
+		clusterHits := func(ur models.AlertsResponse) map[string]int {
+			hits := map[string]int{}
+			for _, nameStats := range ur.Counters {
+				if nameStats.Name == "cluster" {
+					for _, valueStats := range nameStats.Values {
+						hits[valueStats.Value] = valueStats.Hits
+					}
+				}
+			}
+			return hits
+		}
+
+		all := getAlerts("")
+		// invert without any valid filter doesn't change anything
+		if inverted := getAlerts("invert=1"); inverted.TotalAlerts != all.TotalAlerts {
+			t.Errorf("[%s] invert=1 without filters returned %d alert(s), expected %d", version, inverted.TotalAlerts, all.TotalAlerts)
+		}
+
+		for _, query := range []string{"q=cluster=prod", "q=cluster=dev&q=alertname=Host_Down", "q=@state=active", "q=cluster=foo"} {
+			matched := getAlerts(query)
+			inverted := getAlerts(query + "&invert=1")
+			if matched.TotalAlerts+inverted.TotalAlerts != all.TotalAlerts {
+				t.Errorf("[%s] %s returned %d alert(s) and %d when inverted, expected %d in total", version, query, matched.TotalAlerts, inverted.TotalAlerts, all.TotalAlerts)
+			}
+
+			matchedHits := clusterHits(matched)
+			invertedHits := clusterHits(inverted)
+			for cluster, hits := range clusterHits(all) {
+				if matchedHits[cluster]+invertedHits[cluster] != hits {
+					t.Errorf("[%s] %s returned %d hit(s) for cluster=%s and %d when inverted, expected %d in total", version, query, matchedHits[cluster], invertedHits[cluster], cluster, hits)
+				}
+			}
+		}
+
+		if hits := clusterHits(getAlerts("q=cluster=prod&invert=1")); hits["prod"] != 0 {
+			t.Errorf("[%s] cluster=prod with invert=1 returned %d hit(s) for cluster=prod", version, hits["prod"])
+		}
+	}
+}
+
+func TestLabelCrossTab(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing label cross tab using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, query := range []string{"", "&q=cluster=prod", "&q=@state=active"} {
+			req := httptest.NewRequest("GET", "/alerts.json?"+strings.TrimPrefix(query, "&"), nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			ar := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ar)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+
+			uri := "/labelCrossTab.json?row=cluster&column=disk" + query
+			req = httptest.NewRequest("GET", uri, nil)
+			resp = httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+			}
+			crossTab := models.LabelCrossTab{}
+			err = json.Unmarshal(resp.Body.Bytes(), &crossTab)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+
+			if crossTab.Hits != ar.TotalAlerts {
+				t.Errorf("[%s] GET %s returned %d total hits, expected %d", version, uri, crossTab.Hits, ar.TotalAlerts)
+			}
+			var total int
+			for _, row := range crossTab.Rows {
+				for _, hits := range row.Columns {
+					total += hits
+				}
+			}
+			if total != ar.TotalAlerts {
+				t.Errorf("[%s] GET %s returned a matrix with %d hits, expected %d", version, uri, total, ar.TotalAlerts)
+			}
+		}
+
+		for _, uri := range []string{"/labelCrossTab.json", "/labelCrossTab.json?row=cluster", "/labelCrossTab.json?column=cluster"} {
+			req := httptest.NewRequest("GET", uri, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusBadRequest {
+				t.Errorf("[%s] GET %s returned status %d, expected %d", version, uri, resp.Code, http.StatusBadRequest)
+			}
+		}
+	}
+}
+
+func TestActiveSilences(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing active silences using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, testCase := range []struct {
+			query    string
+			silences int
+		}{
+			{query: "", silences: 3},
+			{query: "?author=john", silences: 3},
+			{query: "?author=JOHN@example.com", silences: 3},
+			{query: "?author=bob", silences: 0},
+			{query: "?comment=server7", silences: 1},
+			{query: "?author=john&comment=server7", silences: 1},
+			{query: "?author=bob&comment=server7", silences: 0},
+		} {
+			uri := "/silences" + testCase.query
+			req := httptest.NewRequest("GET", uri, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+			}
+			silences := []models.ManagedSilence{}
+			err := json.Unmarshal(resp.Body.Bytes(), &silences)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if len(silences) != testCase.silences {
+				t.Errorf("[%s] GET %s returned %d silence(s), expected %d", version, uri, len(silences), testCase.silences)
+			}
+
+			for _, ms := range silences {
+				if len(ms.Sources) != 1 || ms.Sources[0].Alertmanager != "default" || ms.Sources[0].ID != ms.Silence.ID {
+					t.Errorf("[%s] GET %s returned silence %s with invalid sources: %v", version, uri, ms.Silence.ID, ms.Sources)
+				}
+
+				req := httptest.NewRequest("GET", "/alerts.json?q=@silence_id="+ms.Silence.ID, nil)
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+				ar := models.AlertsResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ar)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+				if ms.AlertCount != ar.TotalAlerts {
+					t.Errorf("[%s] GET %s returned silence %s with %d alert(s), expected %d", version, uri, ms.Silence.ID, ms.AlertCount, ar.TotalAlerts)
+				}
+			}
+		}
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing alert acknowledgements using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		fingerprint := alertmanager.DedupAlerts()[0].Alerts[0].Fingerprint
+
+		ackedAlerts := func() []models.Alert {
+			req := httptest.NewRequest("GET", "/alerts.json?q=@acked=true", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json?q=@acked=true returned status %d", version, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			alerts := []models.Alert{}
+			for _, ag := range ur.AlertGroups {
+				alerts = append(alerts, ag.Alerts...)
+			}
+			return alerts
+		}
+
+		for _, testCase := range []struct {
+			method string
+			uri    string
+			body   string
+			code   int
+			acked  bool
+		}{
+			{method: "POST", uri: "/ack", body: `{"fingerprint":"` + fingerprint + `"}`, code: 400},
+			{method: "POST", uri: "/ack", body: `{"createdBy":"me@example.com"}`, code: 400},
+			{method: "POST", uri: "/ack", body: `{"fingerprint":`, code: 400},
+			{method: "POST", uri: "/ack", body: `{"fingerprint":"foo","createdBy":"me@example.com"}`, code: 404},
+			{method: "DELETE", uri: "/ack?fingerprint=" + fingerprint, code: 404},
+			{method: "POST", uri: "/ack", body: `{"fingerprint":"` + fingerprint + `","createdBy":"me@example.com"}`, code: 200, acked: true},
+			{method: "DELETE", uri: "/ack", code: 400, acked: true},
+			{method: "DELETE", uri: "/ack?fingerprint=" + fingerprint, code: 200},
+			{method: "DELETE", uri: "/ack?fingerprint=" + fingerprint, code: 404},
+		} {
+			req := httptest.NewRequest(testCase.method, testCase.uri, strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] %s %s with %s returned status %d, expected %d", version, testCase.method, testCase.uri, testCase.body, resp.Code, testCase.code)
+			}
+			if resp.Code == http.StatusOK {
+				ack := models.Acknowledgement{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ack)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+				if ack.Fingerprint != fingerprint || ack.CreatedBy != "me@example.com" {
+					t.Errorf("[%s] %s %s returned invalid acknowledgement: %v", version, testCase.method, testCase.uri, ack)
+				}
+				if ack.ExpiresAt.Sub(ack.CreatedAt) != config.Config.Acknowledgement.TTL {
+					t.Errorf("[%s] %s %s returned acknowledgement with ttl %s, expected %s", version, testCase.method, testCase.uri, ack.ExpiresAt.Sub(ack.CreatedAt), config.Config.Acknowledgement.TTL)
+				}
+			}
+
+			alerts := ackedAlerts()
+			if testCase.acked && len(alerts) == 0 {
+				t.Errorf("[%s] No acknowledged alerts returned after %s %s", version, testCase.method, testCase.uri)
+			}
+			if !testCase.acked && len(alerts) > 0 {
+				t.Errorf("[%s] %d acknowledged alert(s) returned after %s %s", version, len(alerts), testCase.method, testCase.uri)
+			}
+			for _, alert := range alerts {
+				if !alert.Acknowledged {
+					t.Errorf("[%s] @acked=true returned alert that isn't acknowledged: %v", version, alert.Labels)
+				}
+			}
+		}
+	}
+}
+
+func TestAlertsPrivacy(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Read() }()
+
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing privacy settings using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func() models.AlertsResponse {
+			apiCache.Flush()
+			req := httptest.NewRequest("GET", "/alerts.json", nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json returned status %d", version, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur
+		}
+
+		config.Config.Privacy.Labels.Strip = []string{}
+		config.Config.Privacy.Labels.Redact = []string{}
+		config.Config.Privacy.Annotations.Strip = []string{}
+		config.Config.Privacy.StripCounters = false
+		baseline := getAlerts()
+
+		config.Config.Privacy.Labels.Strip = []string{"job"}
+		config.Config.Privacy.Labels.Redact = []string{"instance", "alertname"}
+		config.Config.Privacy.Annotations.Strip = []string{"summary"}
+		ur := getAlerts()
+
+		if ur.TotalAlerts != baseline.TotalAlerts || ur.TotalGroups != baseline.TotalGroups {
+			t.Errorf("[%s] Got %d alert(s) in %d group(s), expected %d in %d", version, ur.TotalAlerts, ur.TotalGroups, baseline.TotalAlerts, baseline.TotalGroups)
+		}
+		for _, ag := range ur.AlertGroups {
+			labelMaps := []map[string]string{ag.Labels, ag.Shared.Labels}
+			annotations := ag.Shared.Annotations
+			for _, alert := range ag.Alerts {
+				labelMaps = append(labelMaps, alert.Labels)
+				annotations = append(annotations, alert.Annotations...)
+			}
+			for _, labels := range labelMaps {
+				if _, found := labels["job"]; found {
+					t.Errorf("[%s] Stripped label job found in group %s: %v", version, ag.ID, labels)
+				}
+				for _, name := range []string{"instance", "alertname"} {
+					if value, found := labels[name]; found && value != config.Config.Privacy.Placeholder {
+						t.Errorf("[%s] Redacted label %s=%s found in group %s", version, name, value, ag.ID)
+					}
+				}
+			}
+			for _, annotation := range annotations {
+				if annotation.Name == "summary" {
+					t.Errorf("[%s] Stripped annotation summary found in group %s", version, ag.ID)
+				}
+			}
+		}
+		if _, found := ur.Colors["alertname"]; found {
+			t.Errorf("[%s] Colors for redacted label alertname found in the response", version)
+		}
+		if diff := cmp.Diff(baseline.Counters, ur.Counters); diff != "" {
+			t.Errorf("[%s] Counters changed with stripCounters=false (-want +got):\n%s", version, diff)
+		}
+
+		config.Config.Privacy.StripCounters = true
+		ur = getAlerts()
+		for _, nameStats := range ur.Counters {
+			switch nameStats.Name {
+			case "job":
+				t.Errorf("[%s] Stripped label job found in counters", version)
+			case "instance", "alertname":
+				if len(nameStats.Values) != 1 || nameStats.Values[0].Value != config.Config.Privacy.Placeholder || nameStats.Values[0].Hits != ur.TotalAlerts {
+					t.Errorf("[%s] Invalid counters for redacted label %s: %v", version, nameStats.Name, nameStats.Values)
+				}
+			}
+		}
+	}
+}
+
+func TestPrivacyEndpoints(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Read() }()
+
+	// values of the instance label used in mock alerts and silences
+	instanceRegex := regexp.MustCompile(`\b(server|web)[0-9]+\b`)
+
+	type endpointTest struct {
+		method string
+		uri    string
+		body   string
+	}
+	endpoints := []endpointTest{
+		{method: "GET", uri: "/alerts.json"},
+		{method: "GET", uri: "/export/alerts.json"},
+		{method: "GET", uri: "/export/labelStats.csv"},
+		{method: "GET", uri: "/autocomplete.json?term=instance"},
+		{method: "GET", uri: "/labelValues.json?name=instance"},
+		{method: "GET", uri: "/labelCrossTab.json?row=instance&column=cluster"},
+		{method: "GET", uri: "/metrics/alerts"},
+		{method: "GET", uri: "/silences"},
+	}
+
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing privacy settings on all endpoints using mock files from Alertmanager %s", version)
+		config.Config.Privacy.Labels.Redact = []string{}
+		config.Config.Privacy.StripCounters = true
+		config.Config.Alerts.Metrics.Labels = []string{"alertname", "instance"}
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		request := func(e endpointTest) *httptest.ResponseRecorder {
+			apiCache.Flush()
+			req := httptest.NewRequest(e.method, e.uri, strings.NewReader(e.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] %s %s returned status %d", version, e.method, e.uri, resp.Code)
+			}
+			return resp
+		}
+		diffMatches := func() int {
+			resp := request(endpointTest{method: "POST", uri: "/filterDiff", body: `{"a": ["instance=server1"], "b": ["cluster=dev"]}`})
+			ur := models.FilterDiffResponse{}
+			if err := json.Unmarshal(resp.Body.Bytes(), &ur); err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur.OnlyA.Count + ur.Both.Count
+		}
+
+		for _, e := range endpoints {
+			if body := request(e).Body.String(); !instanceRegex.MatchString(body) {
+				t.Errorf("[%s] %s %s returned no instance label values without privacy settings: %s", version, e.method, e.uri, body)
+			}
+		}
+		if diffMatches() == 0 {
+			t.Errorf("[%s] POST /filterDiff matched no alerts using instance label without privacy settings", version)
+		}
+
+		config.Config.Privacy.Labels.Redact = []string{"instance"}
+		for _, e := range endpoints {
+			if match := instanceRegex.FindString(request(e).Body.String()); match != "" {
+				t.Errorf("[%s] %s %s returned redacted instance label value %q", version, e.method, e.uri, match)
+			}
+		}
+		if matches := diffMatches(); matches != 0 {
+			t.Errorf("[%s] POST /filterDiff matched %d alert(s) using redacted instance label", version, matches)
+		}
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	mockConfig()
+	defer maintenance.remove()
+
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing maintenance windows using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func(query string) models.AlertsResponse {
+			req := httptest.NewRequest("GET", "/alerts.json?"+query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET /alerts.json?%s returned status %d", version, query, resp.Code)
+			}
+			ur := models.AlertsResponse{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			return ur
+		}
+		countTagged := func(ur models.AlertsResponse) (tagged int) {
+			for _, ag := range ur.AlertGroups {
+				if ag.MaintenanceActive {
+					tagged++
+				}
+			}
+			return tagged
+		}
+
+		allGroups := getAlerts("").TotalGroups
+		scopedGroups := getAlerts("q=alertname=Host_Down").TotalGroups
+		if scopedGroups == 0 || scopedGroups == allGroups {
+			t.Fatalf("[%s] Got %d group(s) with Host_Down alerts out of %d", version, scopedGroups, allGroups)
+		}
+
+		now := time.Now().UTC()
+		ts := func(d time.Duration) string {
+			v, _ := now.Add(d).MarshalText()
+			return string(v)
+		}
+
+		for _, testCase := range []struct {
+			method string
+			body   string
+			code   int
+			groups int
+			tagged int
+		}{
+			{method: "DELETE", code: 404, groups: allGroups},
+			{method: "POST", body: `{"endsAt":"`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(time.Hour) + `"}`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"createdBy":"me@example.com"}`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(-time.Minute) + `","createdBy":"me@example.com"}`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"startsAt":"` + ts(time.Hour) + `","endsAt":"` + ts(time.Minute) + `","createdBy":"me@example.com"}`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(time.Hour) + `","filters":["@link_count=foo"],"createdBy":"me@example.com"}`, code: 400, groups: allGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(time.Hour) + `","createdBy":"me@example.com"}`, code: 200, groups: allGroups, tagged: allGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(time.Hour) + `","filters":["alertname=Host_Down"],"createdBy":"me@example.com"}`, code: 200, groups: allGroups, tagged: scopedGroups},
+			{method: "POST", body: `{"endsAt":"` + ts(time.Hour) + `","filters":["alertname=Host_Down"],"hide":true,"createdBy":"me@example.com"}`, code: 200, groups: allGroups - scopedGroups},
+			{method: "POST", body: `{"startsAt":"` + ts(time.Minute) + `","endsAt":"` + ts(time.Hour) + `","createdBy":"me@example.com"}`, code: 200, groups: allGroups},
+			{method: "DELETE", code: 200, groups: allGroups},
+			{method: "DELETE", code: 404, groups: allGroups},
+		} {
+			req := httptest.NewRequest(testCase.method, "/maintenance", strings.NewReader(testCase.body))
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.code {
+				t.Errorf("[%s] %s /maintenance with %s returned status %d, expected %d", version, testCase.method, testCase.body, resp.Code, testCase.code)
+			}
+
+			ur := getAlerts("")
+			if ur.TotalGroups != testCase.groups {
+				t.Errorf("[%s] Got %d group(s) after %s /maintenance with %s, expected %d", version, ur.TotalGroups, testCase.method, testCase.body, testCase.groups)
+			}
+			if tagged := countTagged(ur); tagged != testCase.tagged {
+				t.Errorf("[%s] Got %d group(s) in maintenance after %s /maintenance with %s, expected %d", version, tagged, testCase.method, testCase.body, testCase.tagged)
+			}
+		}
+
+		// window that already ended is removed and groups are no longer tagged
+		maintenance.set(models.MaintenanceWindow{StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)})
+		if tagged := countTagged(getAlerts("")); tagged != 0 {
+			t.Errorf("[%s] Got %d group(s) in maintenance after the window ended", version, tagged)
+		}
+		if _, found := maintenance.remove(); found {
+			t.Errorf("[%s] Maintenance window wasn't removed after it ended", version)
+		}
+	}
+}
+
+func TestAlertsViews(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Views = []config.ViewConfig{} }()
+	config.Config.Views = []config.ViewConfig{
+		{Name: "prod", Filters: []string{"cluster=prod"}},
+		{Name: "dev", Filters: []string{"cluster=dev", "alertname=Host_Down"}},
+	}
+	config.Config.Views[0].Sorting.Order = "label"
+	config.Config.Views[0].Sorting.Reverse = true
+	config.Config.Views[0].Sorting.Label = "instance"
+
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing views using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		getAlerts := func(query string) (int, models.AlertsResponse) {
+			req := httptest.NewRequest("GET", "/alerts.json?"+query, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			ur := models.AlertsResponse{}
+			if resp.Code == http.StatusOK {
+				err := json.Unmarshal(resp.Body.Bytes(), &ur)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+			}
+			return resp.Code, ur
+		}
+		filterTexts := func(ur models.AlertsResponse) []string {
+			texts := []string{}
+			for _, f := range ur.Filters {
+				texts = append(texts, f.Text)
+			}
+			return texts
+		}
+
+		for _, testCase := range []struct {
+			query    string
+			code     int
+			filters  []string
+			matches  string
+			settings models.GridSettings
+		}{
+			{
+				query:    "view=prod",
+				code:     http.StatusOK,
+				filters:  []string{"cluster=prod"},
+				matches:  "q=cluster=prod",
+				settings: models.GridSettings{Order: "label", Reverse: true, Label: "instance"},
+			},
+			{
+				query:    "view=prod&sortOrder=startsAt&sortReverse=0&sortLabel=job",
+				code:     http.StatusOK,
+				filters:  []string{"cluster=prod"},
+				matches:  "q=cluster=prod",
+				settings: models.GridSettings{Order: "startsAt", Reverse: false, Label: "job"},
+			},
+			{
+				query:    "view=prod&q=cluster=staging",
+				code:     http.StatusOK,
+				filters:  []string{"cluster=staging"},
+				matches:  "q=cluster=staging",
+				settings: models.GridSettings{Order: "label", Reverse: true, Label: "instance"},
+			},
+			{
+				query:    "view=dev",
+				code:     http.StatusOK,
+				filters:  []string{"cluster=dev", "alertname=Host_Down"},
+				matches:  "q=cluster=dev&q=alertname=Host_Down",
+				settings: models.GridSettings{Order: "startsAt", Reverse: true, Label: "alertname"},
+			},
+			{
+				query:    "view=",
+				code:     http.StatusOK,
+				filters:  []string{},
+				matches:  "",
+				settings: models.GridSettings{Order: "startsAt", Reverse: true, Label: "alertname"},
+			},
+			{
+				query: "view=foo",
+				code:  http.StatusBadRequest,
+			},
+		} {
+			code, ur := getAlerts(testCase.query)
+			if code != testCase.code {
+				t.Errorf("[%s] GET /alerts.json?%s returned status %d, expected %d", version, testCase.query, code, testCase.code)
+				continue
+			}
+			if code != http.StatusOK {
+				continue
+			}
+			if diff := cmp.Diff(testCase.filters, filterTexts(ur)); diff != "" {
+				t.Errorf("[%s] Wrong filters for %s (-want +got):\n%s", version, testCase.query, diff)
+			}
+			if _, expected := getAlerts(testCase.matches); ur.TotalAlerts != expected.TotalAlerts {
+				t.Errorf("[%s] %s returned %d alert(s), expected %d", version, testCase.query, ur.TotalAlerts, expected.TotalAlerts)
+			}
+			if diff := cmp.Diff(testCase.settings, ur.SortSettings); diff != "" {
+				t.Errorf("[%s] Wrong sort settings for %s (-want +got):\n%s", version, testCase.query, diff)
+			}
+		}
+	}
+}
+
+func TestFiltersMaxPerRequest(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Filters.MaxPerRequest = 100 }()
+	config.Config.Filters.MaxPerRequest = 3
+
+	mockAlerts(mock.ListAllMocks()[0])
+	r := ginTestEngine()
+
+	for _, testCase := range []struct {
+		query string
+		code  int
+		// only endpoints that are sorting alert groups use pinFilter
+		sortedOnly bool
+	}{
+		{query: "q=cluster=dev&q=alertname=Host_Down&q=@state=active", code: http.StatusOK},
+		{query: "q=cluster=dev&q=alertname=Host_Down&q=@state=active&q=&q=", code: http.StatusOK},
+		{query: "q=cluster=dev&q=alertname=Host_Down&q=@state=active&q=instance=~web", code: http.StatusBadRequest},
+		{query: "q=foo=&q=bar=&q=@state=foo&q=@limit=abc", code: http.StatusBadRequest},
+		{query: "q=cluster=dev%20OR%20cluster=prod&q=alertname=Host_Down", code: http.StatusOK},
+		{query: "q=cluster=dev%20OR%20cluster=prod%20OR%20cluster=staging&q=alertname=Host_Down", code: http.StatusBadRequest},
+		{query: "q=cluster=dev&pinFilter=cluster=dev%20OR%20cluster=prod", code: http.StatusOK, sortedOnly: true},
+		{query: "q=cluster=dev&pinFilter=cluster=dev%20OR%20cluster=prod%20OR%20cluster=staging%20OR%20cluster=test", code: http.StatusBadRequest, sortedOnly: true},
+	} {
+		paths := []string{"/alerts.json", "/export/alerts.json", "/export/labelStats.csv", "/labelCrossTab.json"}
+		if testCase.sortedOnly {
+			paths = paths[:2]
+		}
+		for _, path := range paths {
+			uri := fmt.Sprintf("%s?row=cluster&column=alertname&%s", path, testCase.query)
+			req := httptest.NewRequest("GET", uri, nil)
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.code {
+				t.Errorf("GET %s returned status %d, expected %d", uri, resp.Code, testCase.code)
+			}
+			if testCase.code == http.StatusBadRequest {
+				ur := map[string]string{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ur)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+				if ur["error"] != "too many filters, only 3 are allowed per request" {
+					t.Errorf("GET %s returned invalid error: %q", uri, ur["error"])
+				}
+			}
+		}
+	}
+
+	for _, testCase := range []struct {
+		path string
+		body string
+		code int
+	}{
+		{path: "/filterDiff", body: `{"a":["cluster=dev"],"b":["cluster=dev","alertname=Host_Down"]}`, code: http.StatusOK},
+		{path: "/filterDiff", body: `{"a":["cluster=dev","@state=active"],"b":["cluster=dev","alertname=Host_Down"]}`, code: http.StatusBadRequest},
+		{path: "/filterDiff", body: `{"a":["cluster=dev OR cluster=prod OR cluster=staging OR cluster=test"],"b":["cluster=dev"]}`, code: http.StatusBadRequest},
+		{path: "/silences/bulk", body: `{"filters":["cluster=dev","alertname=Host_Down","@state=active","instance=~web"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`, code: http.StatusBadRequest},
+		{path: "/silences/bulk", body: `{"filters":["cluster=dev OR cluster=prod","alertname=Host_Down OR alertname=Free_Disk_Space_Too_Low"],"duration":"1h","createdBy":"me@example.com","comment":"bulk"}`, code: http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest("POST", testCase.path, strings.NewReader(testCase.body))
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != testCase.code {
+			t.Errorf("POST %s with %s returned status %d, expected %d", testCase.path, testCase.body, resp.Code, testCase.code)
+		}
+		if testCase.code == http.StatusBadRequest {
+			ur := map[string]string{}
+			err := json.Unmarshal(resp.Body.Bytes(), &ur)
+			if err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+			}
+			if ur["error"] != "too many filters, only 3 are allowed per request" {
+				t.Errorf("POST %s with %s returned invalid error: %q", testCase.path, testCase.body, ur["error"])
+			}
+		}
+	}
+}
+
+func TestAlertsCountersOnly(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing counters only responses using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		for _, query := range []string{"", "q=cluster=prod", "q=alertname=Host_Down"} {
+			getResponse := func(uri string) models.AlertsResponse {
+				req := httptest.NewRequest("GET", uri, nil)
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+				if resp.Code != http.StatusOK {
+					t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+				}
+				ar := models.AlertsResponse{}
+				err := json.Unmarshal(resp.Body.Bytes(), &ar)
+				if err != nil {
+					t.Errorf("Failed to unmarshal response: %s", err)
+				}
+				return ar
+			}
+
+			full := getResponse("/alerts.json?" + query)
+			uri := "/alerts.json?countersOnly=1&" + query
+			counters := getResponse(uri)
+
+			if len(counters.AlertGroups) != 0 {
+				t.Errorf("[%s] GET %s returned %d alert group(s), expected none", version, uri, len(counters.AlertGroups))
+			}
+			if len(full.AlertGroups) == 0 {
+				t.Errorf("[%s] GET /alerts.json?%s returned no alert groups", version, query)
+			}
+			if counters.TotalAlerts != full.TotalAlerts {
+				t.Errorf("[%s] GET %s returned totalAlerts=%d, expected %d", version, uri, counters.TotalAlerts, full.TotalAlerts)
+			}
+			if counters.TotalGroups != full.TotalGroups {
+				t.Errorf("[%s] GET %s returned totalGroups=%d, expected %d", version, uri, counters.TotalGroups, full.TotalGroups)
+			}
+			if len(counters.Counters) == 0 {
+				t.Errorf("[%s] GET %s returned no counters", version, uri)
+			}
+			if diff := cmp.Diff(full.Counters, counters.Counters); diff != "" {
+				t.Errorf("[%s] GET %s returned wrong counters (-want +got):\n%s", version, uri, diff)
+			}
+			if diff := cmp.Diff(full.Upstreams, counters.Upstreams); diff != "" {
+				t.Errorf("[%s] GET %s returned wrong upstreams (-want +got):\n%s", version, uri, diff)
+			}
+		}
+	}
+}
+
+func TestAlertsCountersOnlyETag(t *testing.T) {
+	mockConfig()
+	defer func() {
+		config.Config.Labels.Strip = []string{}
+	}()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing counters only ETag using mock files from Alertmanager %s", version)
+		config.Config.Labels.Strip = []string{}
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		uri := "/alerts.json?countersOnly=1"
+		req := httptest.NewRequest("GET", uri, nil)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("[%s] GET %s returned status %d", version, uri, resp.Code)
+		}
+		etag := resp.Header().Get("ETag")
+
+		// alert groups are never returned but counters are different once
+		// cluster label is stripped from alerts
+		config.Config.Labels.Strip = []string{"cluster"}
+		apiCache.Flush()
+		req = httptest.NewRequest("GET", uri, nil)
+		req.Header.Set("If-None-Match", etag)
+		resp = httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Errorf("[%s] GET %s after alerts changed returned status %d", version, uri, resp.Code)
+		}
+		if resp.Header().Get("ETag") == etag {
+			t.Errorf("[%s] GET %s after alerts changed returned the same ETag", version, uri)
+		}
+	}
+}
+
+// countdownContext is a context that reports itself as canceled after Err()
+// was called given number of times, it's used to cancel requests mid-flight
+type countdownContext struct {
+	context.Context
+	lock  sync.Mutex
+	calls int
+}
+
+func (ctx *countdownContext) Err() error {
+	ctx.lock.Lock()
+	defer ctx.lock.Unlock()
+
+	if ctx.calls <= 0 {
+		return context.Canceled
+	}
+	ctx.calls--
+	return nil
+}
+
+func TestAlertsCanceled(t *testing.T) {
+	mockConfig()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing canceled requests using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+		r := ginTestEngine()
+
+		// cancel while parsing filters, matching alerts and after sorting all
+		// 10 alert groups
+		for _, calls := range []int{0, 1, 3, 11} {
+			uri := fmt.Sprintf("/alerts.json?q=@state=active&canceled=%d", calls)
+			req := httptest.NewRequest("GET", uri, nil)
+			req = req.WithContext(&countdownContext{Context: context.Background(), calls: calls})
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusServiceUnavailable {
+				t.Errorf("[%s] GET %s returned status %d, expected %d", version, uri, resp.Code, http.StatusServiceUnavailable)
+			}
+
+			// canceled responses must not be cached
+			req = httptest.NewRequest("GET", uri, nil)
+			resp = httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != http.StatusOK {
+				t.Errorf("[%s] GET %s returned status %d, expected %d", version, uri, resp.Code, http.StatusOK)
+			}
+		}
+	}
+}
+
+func TestAlertsRequestTimeout(t *testing.T) {
+	mockConfig()
+	defer func() { config.Config.Listen.RequestTimeout = time.Second * 30 }()
+	for _, version := range mock.ListAllMocks() {
+		t.Logf("Testing request timeout using mock files from Alertmanager %s", version)
+		mockAlerts(version)
+
+		for _, testCase := range []struct {
+			timeout time.Duration
+			code    int
+		}{
+			{timeout: time.Nanosecond, code: http.StatusServiceUnavailable},
+			{timeout: 0, code: http.StatusOK},
+			{timeout: time.Minute, code: http.StatusOK},
+		} {
+			config.Config.Listen.RequestTimeout = testCase.timeout
+			r := ginTestEngine()
+			for _, uri := range []string{"/alerts.json?q=@state=active", "/export/alerts.json?q=@state=active", "/labelCrossTab.json?row=cluster&column=job"} {
+				apiCache.Flush()
+				req := httptest.NewRequest("GET", uri, nil)
+				resp := httptest.NewRecorder()
+				r.ServeHTTP(resp, req)
+				if resp.Code != testCase.code {
+					t.Errorf("[%s] GET %s with timeout=%s returned status %d, expected %d", version, uri, testCase.timeout, resp.Code, testCase.code)
+				}
+			}
+		}
+	}
+}
+
+func TestFilterAlertsCanceled(t *testing.T) {
+	mockConfig()
+	mockAlerts("0.19.0")
+
+	ctx := &countdownContext{Context: context.Background(), calls: 2}
+	_, err := filterAlerts(ctx, []filters.FilterT{}, false, "", "", true, false, nil)
+	if err != context.Canceled {
+		t.Errorf("filterAlerts() returned %v, expected %v", err, context.Canceled)
+	}
+	if ctx.calls != 0 {
+		t.Errorf("filterAlerts() returned before context was canceled, %d call(s) left", ctx.calls)
+	}
+}
+
+func TestSortGroupsCanceled(t *testing.T) {
+	groups := generateAlertGroups(10000)
+	ctx := &countdownContext{Context: context.Background(), calls: 1}
+	stats := sortGroups(ctx, groups, models.GridSettings{Order: "startsAt"})
+	if stats.comparisons != sortCancelCheckInterval*2 {
+		t.Errorf("sortGroups() made %d comparison(s) before stopping, expected %d", stats.comparisons, sortCancelCheckInterval*2)
+	}
+}
+
+func TestAlertsAPIVersion(t *testing.T) {
+	mockConfig()
+	mockAlerts("0.19.0")
+	r := ginTestEngine()
+
+	for _, testCase := range []struct {
+		uri        string
+		accept     string
+		statusCode int
+		groupsKey  string
+	}{
+		{uri: "/alerts.json", statusCode: http.StatusOK, groupsKey: "groups"},
+		{uri: "/alerts.json?apiVersion=2", statusCode: http.StatusOK, groupsKey: "groups"},
+		{uri: "/alerts.json?apiVersion=1", statusCode: http.StatusOK, groupsKey: "alertGroups"},
+		{uri: "/alerts.json", accept: "application/vnd.karma.v1+json", statusCode: http.StatusOK, groupsKey: "alertGroups"},
+		{uri: "/alerts.json", accept: "application/vnd.karma.v2+json", statusCode: http.StatusOK, groupsKey: "groups"},
+		{uri: "/alerts.json?apiVersion=2", accept: "application/vnd.karma.v1+json", statusCode: http.StatusOK, groupsKey: "groups"},
+		{uri: "/alerts.json?apiVersion=0", statusCode: http.StatusBadRequest},
+		{uri: "/alerts.json?apiVersion=3", statusCode: http.StatusBadRequest},
+		{uri: "/alerts.json?apiVersion=foo", statusCode: http.StatusBadRequest},
+		{uri: "/alerts.json", accept: "application/vnd.karma.v9+json", statusCode: http.StatusBadRequest},
+	} {
+		// send every request twice so both cached and uncached responses are
+		// tested
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", testCase.uri, nil)
+			if testCase.accept != "" {
+				req.Header.Set("Accept", testCase.accept)
+			}
+			resp := httptest.NewRecorder()
+			r.ServeHTTP(resp, req)
+			if resp.Code != testCase.statusCode {
+				t.Errorf("GET %s with Accept=%q returned status %d, expected %d", testCase.uri, testCase.accept, resp.Code, testCase.statusCode)
+				continue
+			}
+			if resp.Header().Get("Vary") != "Accept" {
+				t.Errorf("GET %s with Accept=%q returned Vary=%q, expected %q", testCase.uri, testCase.accept, resp.Header().Get("Vary"), "Accept")
+			}
+			if testCase.statusCode != http.StatusOK {
+				continue
+			}
+
+			fields := map[string]json.RawMessage{}
+			if err := json.Unmarshal(resp.Body.Bytes(), &fields); err != nil {
+				t.Errorf("Failed to unmarshal response: %s", err)
+				continue
+			}
+			groups := []models.APIAlertGroup{}
+			if err := json.Unmarshal(fields[testCase.groupsKey], &groups); err != nil || len(groups) == 0 {
+				t.Errorf("GET %s with Accept=%q returned no alert groups in '%s'", testCase.uri, testCase.accept, testCase.groupsKey)
+			}
+		}
+	}
+
+	// responses for the legacy version are cached separately
+	for _, key := range []string{"/alerts.json", "/alerts.json#v1"} {
+		if _, found := apiCache.Get(key); !found {
+			t.Errorf("Response for %s wasn't cached", key)
+		}
+	}
+}
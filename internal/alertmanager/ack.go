@@ -0,0 +1,86 @@
+package alertmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// ackStore keeps all alert acknowledgements, they are keyed by the alert
+// fingerprint and only kept in memory, so restarting karma will remove them
+type ackStore struct {
+	lock sync.Mutex
+	acks map[string]models.Acknowledgement
+}
+
+func newAckStore() *ackStore {
+	return &ackStore{acks: map[string]models.Acknowledgement{}}
+}
+
+var acks = newAckStore()
+
+func (s *ackStore) add(ack models.Acknowledgement) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.acks[ack.Fingerprint] = ack
+}
+
+func (s *ackStore) remove(fp string) (models.Acknowledgement, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ack, found := s.acks[fp]
+	if found {
+		delete(s.acks, fp)
+	}
+	return ack, found
+}
+
+// isAcknowledged returns true if there's an acknowledgement for given alert
+// that didn't expire yet
+func (s *ackStore) isAcknowledged(fp string, now time.Time) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ack, found := s.acks[fp]
+	return found && ack.ExpiresAt.After(now)
+}
+
+// expire removes all acknowledgements that expired before given time
+func (s *ackStore) expire(now time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for fp, ack := range s.acks {
+		if !ack.ExpiresAt.After(now) {
+			delete(s.acks, fp)
+		}
+	}
+}
+
+// Acknowledge marks alert with given fingerprint as acknowledged by given
+// user, acknowledgement will expire after ttl
+func Acknowledge(fingerprint, createdBy string, ttl time.Duration) models.Acknowledgement {
+	now := time.Now().UTC()
+	ack := models.Acknowledgement{
+		Fingerprint: fingerprint,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	acks.add(ack)
+	return ack
+}
+
+// Unacknowledge removes the acknowledgement for alert with given fingerprint,
+// it returns false if that alert wasn't acknowledged
+func Unacknowledge(fingerprint string) (models.Acknowledgement, bool) {
+	return acks.remove(fingerprint)
+}
+
+// ExpireAcknowledgements removes all acknowledgements that already expired
+func ExpireAcknowledgements() {
+	acks.expire(time.Now())
+}
@@ -0,0 +1,68 @@
+package alertmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+func TestAckStore(t *testing.T) {
+	now := time.Now()
+	s := newAckStore()
+
+	if s.isAcknowledged("1", now) {
+		t.Errorf("isAcknowledged() returned true for an unknown alert")
+	}
+
+	s.add(models.Acknowledgement{Fingerprint: "1", CreatedBy: "me", CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	s.add(models.Acknowledgement{Fingerprint: "2", CreatedBy: "me", CreatedAt: now, ExpiresAt: now.Add(time.Hour)})
+	if !s.isAcknowledged("1", now) {
+		t.Errorf("isAcknowledged() returned false for an acknowledged alert")
+	}
+	if s.isAcknowledged("1", now.Add(time.Minute)) {
+		t.Errorf("isAcknowledged() returned true for an expired acknowledgement")
+	}
+
+	s.expire(now.Add(time.Minute * 2))
+	if len(s.acks) != 1 {
+		t.Errorf("Got %d acknowledgement(s) after expire(), expected 1", len(s.acks))
+	}
+	if _, found := s.remove("1"); found {
+		t.Errorf("remove() returned true for an expired acknowledgement")
+	}
+
+	ack, found := s.remove("2")
+	if !found {
+		t.Errorf("remove() returned false for an acknowledged alert")
+	}
+	if ack.Fingerprint != "2" || ack.CreatedBy != "me" {
+		t.Errorf("remove() returned invalid acknowledgement: %v", ack)
+	}
+	if s.isAcknowledged("2", now) {
+		t.Errorf("isAcknowledged() returned true after remove()")
+	}
+	if _, found := s.remove("2"); found {
+		t.Errorf("remove() returned true for an alert that was already removed")
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	ack := Acknowledge("foo", "me@example.com", time.Minute*5)
+	defer Unacknowledge("foo")
+
+	if ack.Fingerprint != "foo" || ack.CreatedBy != "me@example.com" {
+		t.Errorf("Acknowledge() returned invalid acknowledgement: %v", ack)
+	}
+	if ack.ExpiresAt.Sub(ack.CreatedAt) != time.Minute*5 {
+		t.Errorf("Acknowledge() returned acknowledgement with ttl %s, expected %s", ack.ExpiresAt.Sub(ack.CreatedAt), time.Minute*5)
+	}
+	if !acks.isAcknowledged("foo", time.Now()) {
+		t.Errorf("Alert is not acknowledged after Acknowledge()")
+	}
+
+	ExpireAcknowledgements()
+	if !acks.isAcknowledged("foo", time.Now()) {
+		t.Errorf("Acknowledgement was removed by ExpireAcknowledgements() before it expired")
+	}
+}
@@ -1,21 +1,87 @@
 package alertmanager
 
 import (
+	"reflect"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/filters"
+	"github.com/prymitive/karma/internal/mapper"
 	"github.com/prymitive/karma/internal/models"
 	"github.com/prymitive/karma/internal/slices"
 	"github.com/prymitive/karma/internal/transform"
 )
 
+// dedupStats holds the number of alerts before and after deduplication, it's
+// updated after every collection so all numbers are always from the same
+// snapshot of alerts
+type dedupStats struct {
+	lock    sync.RWMutex
+	raw     int
+	deduped int
+	dropped int
+}
+
+var lastDedupStats = dedupStats{}
+
 // DedupAlerts will collect alert groups from all defined Alertmanager
 // upstreams and deduplicate them, so we only return unique alerts
 func DedupAlerts() []models.AlertGroup {
+	groups, _, _ := dedupAlerts()
+	return groups
+}
+
+// UpdateDedupStats deduplicates alerts from all upstreams and records the
+// number of alerts before and after it
+func UpdateDedupStats() {
+	groups, raw, dropped := dedupAlerts()
+	deduped := 0
+	for _, ag := range groups {
+		deduped += len(ag.Alerts)
+	}
+
+	lastDedupStats.lock.Lock()
+	defer lastDedupStats.lock.Unlock()
+	lastDedupStats.raw = raw
+	lastDedupStats.deduped = deduped
+	lastDedupStats.dropped = dropped
+}
+
+// DedupStats returns the number of alerts collected from all upstreams, the
+// number of unique alerts left after deduplication and the number of unique
+// alerts dropped because of the alerts.maxTotal limit, as recorded by the last
+// UpdateDedupStats() call
+func DedupStats() (raw int, deduped int, dropped int) {
+	lastDedupStats.lock.RLock()
+	defer lastDedupStats.lock.RUnlock()
+	return lastDedupStats.raw, lastDedupStats.deduped, lastDedupStats.dropped
+}
+
+// dedupAlerts returns deduplicated alert groups, the number of alerts that
+// were merged into those groups and the number of alerts dropped to stay
+// within the alerts.maxTotal limit
+func dedupAlerts() ([]models.AlertGroup, int, int) {
 	uniqueGroups := map[string][]models.AlertGroup{}
+	var raw int
 
 	upstreams := GetAlertmanagers()
+	// cluster ID -> number of healthy members, upstreams with errors have no
+	// alerts so they are not expected to report any
+	healthyMembers := map[string]int{}
+	// upstream name -> URI, used to count distinct sources of every alert
+	uris := map[string]string{}
+	// alerts dropped by any upstream because of the alerts.maxTotal limit
+	droppedAlerts := map[string]bool{}
 	for _, am := range upstreams {
+		if am.Error() == "" {
+			healthyMembers[am.ClusterID()]++
+		}
+		uris[am.Name] = am.URI
+		for _, key := range am.DroppedAlerts() {
+			droppedAlerts[key] = true
+		}
 		groups := am.Alerts()
 		for _, ag := range groups {
 			if _, found := uniqueGroups[ag.ID]; !found {
@@ -25,6 +91,7 @@ func DedupAlerts() []models.AlertGroup {
 		}
 	}
 
+	now := time.Now()
 	dedupedGroups := []models.AlertGroup{}
 	alertStates := map[string][]string{}
 	for _, agList := range uniqueGroups {
@@ -37,6 +104,7 @@ func DedupAlerts() []models.AlertGroup {
 				if transform.StripReceivers(config.Config.Receivers.Keep, config.Config.Receivers.Strip, alert.Receiver) {
 					continue
 				}
+				raw++
 				alertLFP := alert.LabelsFingerprint()
 				a, found := alerts[alertLFP]
 				if found {
@@ -49,6 +117,16 @@ func DedupAlerts() []models.AlertGroup {
 					if alert.StartsAt.Before(a.StartsAt) {
 						a.StartsAt = alert.StartsAt
 					}
+					// alert is flapping if any instance reports it as flapping
+					a.Flapping = a.Flapping || alert.Flapping
+					// use the earliest first seen timestamp from all instances
+					if !alert.KarmaFirstSeen.IsZero() && (a.KarmaFirstSeen.IsZero() || alert.KarmaFirstSeen.Before(a.KarmaFirstSeen)) {
+						a.KarmaFirstSeen = alert.KarmaFirstSeen
+					}
+					// use the most recent state change from all instances
+					if alert.StateChangedAt.After(a.StateChangedAt) {
+						a.StateChangedAt = alert.StateChangedAt
+					}
 					// update map
 					alerts[alertLFP] = a
 					// and append alert state to the slice
@@ -68,9 +146,20 @@ func DedupAlerts() []models.AlertGroup {
 		ag.Alerts = models.AlertList{}
 		for _, alert := range alerts {
 			alert := alert // scopelint pin
+			// acknowledgements are tracked by karma so they are the same for all
+			// instances, content fingerprint needs to be updated so that clients can
+			// tell that the alert changed
+			alert.Acknowledged = acks.isAcknowledged(alert.Fingerprint, now)
+			alert.ClusterDisagreement = isClusterDisagreement(alert.Alertmanager, healthyMembers)
+			alert.Inconsistent = isInconsistent(alert.Alertmanager)
+			alert.Sources = countSources(alert.Alertmanager, uris)
+			if alert.Acknowledged || alert.Inconsistent || alert.ClusterDisagreement {
+				alert.UpdateFingerprints()
+			}
 			// strip labels and annotations user doesn't want to see in the UI
 			alert.Labels = transform.StripLables(config.Config.Labels.Keep, config.Config.Labels.Strip, alert.Labels)
 			alert.Annotations = transform.StripAnnotations(config.Config.Annotations.Keep, config.Config.Annotations.Strip, alert.Annotations)
+			alert.Annotations = transform.ApplyAnnotationRules(config.Config.Annotations.Rules, alert.Annotations)
 			// calculate final alert state based on the most important value found
 			// in the list of states from all instances
 			alertLFP := alert.LabelsFingerprint()
@@ -85,13 +174,154 @@ func DedupAlerts() []models.AlertGroup {
 			sort.Slice(alert.Alertmanager, func(i, j int) bool {
 				return alert.Alertmanager[i].Name < alert.Alertmanager[j].Name
 			})
+			alert.GroupID = ag.ID
 			ag.Alerts = append(ag.Alerts, alert)
 		}
 		ag.Hash = ag.ContentFingerprint()
 		dedupedGroups = append(dedupedGroups, ag)
 	}
 
-	return dedupedGroups
+	// every upstream only stores up to alerts.maxTotal alerts, but alerts merged
+	// from multiple upstreams can still exceed it
+	severity := config.Config.Grid.Sorting.SeverityScore
+	dedupedGroups, dropped := dropAlerts(dedupedGroups, config.Config.Alerts.MaxTotal, severity.Label, severity.Weights)
+	for _, key := range dropped {
+		droppedAlerts[key] = true
+	}
+	// alerts dropped by some upstreams might still be collected from others
+	for _, ag := range dedupedGroups {
+		for _, alert := range ag.Alerts {
+			delete(droppedAlerts, droppedAlertKey(ag.ID, alert.LabelsFingerprint()))
+		}
+	}
+
+	return dedupedGroups, raw, len(droppedAlerts)
+}
+
+// droppedAlertKey returns a string identifying an alert dropped because of
+// the alerts.maxTotal limit, the same alert can belong to multiple groups so
+// the group ID is part of it
+func droppedAlertKey(groupID, fingerprint string) string {
+	return groupID + "/" + fingerprint
+}
+
+// dropAlerts removes the lowest priority alerts so that no more than limit
+// alerts are left, it returns remaining groups and keys of dropped alerts,
+// groups left with no alerts are removed
+// Alerts with the lowest weight of the severity label value are dropped first,
+// alerts with the same weight are dropped starting from the most recent one
+// and fingerprints are compared last, so the same set of alerts is always
+// dropped no matter the order of groups
+func dropAlerts(groups []models.AlertGroup, limit int, label string, weights map[string]int) ([]models.AlertGroup, []string) {
+	if limit <= 0 {
+		return groups, nil
+	}
+
+	type alertRef struct {
+		fp       string
+		group    string
+		weight   int
+		startsAt time.Time
+	}
+
+	refs := []alertRef{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			ref := alertRef{fp: alert.LabelsFingerprint(), group: ag.ID, startsAt: alert.StartsAt}
+			if v, found := models.LookupLabel(label, alert.Labels); found {
+				ref.weight = weights[v]
+			}
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) <= limit {
+		return groups, nil
+	}
+
+	// sort from the highest to the lowest priority
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].weight != refs[j].weight {
+			return refs[i].weight > refs[j].weight
+		}
+		if !refs[i].startsAt.Equal(refs[j].startsAt) {
+			return refs[i].startsAt.Before(refs[j].startsAt)
+		}
+		if refs[i].fp != refs[j].fp {
+			return refs[i].fp < refs[j].fp
+		}
+		return refs[i].group < refs[j].group
+	})
+	// group ID -> alert fingerprint -> true
+	keep := map[string]map[string]bool{}
+	for _, ref := range refs[:limit] {
+		if _, found := keep[ref.group]; !found {
+			keep[ref.group] = map[string]bool{}
+		}
+		keep[ref.group][ref.fp] = true
+	}
+
+	result := make([]models.AlertGroup, 0, len(keep))
+	for _, ag := range groups {
+		if _, found := keep[ag.ID]; !found {
+			continue
+		}
+		alerts := models.AlertList{}
+		for _, alert := range ag.Alerts {
+			if keep[ag.ID][alert.LabelsFingerprint()] {
+				alerts = append(alerts, alert)
+			}
+		}
+		ag.Alerts = alerts
+		ag.Hash = ag.ContentFingerprint()
+		result = append(result, ag)
+	}
+
+	dropped := make([]string, 0, len(refs)-limit)
+	for _, ref := range refs[limit:] {
+		dropped = append(dropped, droppedAlertKey(ref.group, ref.fp))
+	}
+	return result, dropped
+}
+
+// isClusterDisagreement returns true if an alert wasn't collected from all
+// healthy members of any cluster it was collected from
+func isClusterDisagreement(instances []models.AlertmanagerInstance, healthyMembers map[string]int) bool {
+	members := map[string]map[string]bool{}
+	for _, am := range instances {
+		if _, found := members[am.Cluster]; !found {
+			members[am.Cluster] = map[string]bool{}
+		}
+		members[am.Cluster][am.Name] = true
+	}
+	for cluster, names := range members {
+		if len(names) < healthyMembers[cluster] {
+			return true
+		}
+	}
+	return false
+}
+
+// countSources returns the number of distinct upstream URIs an alert was
+// collected from
+func countSources(instances []models.AlertmanagerInstance, uris map[string]string) int {
+	sources := map[string]bool{}
+	for _, am := range instances {
+		sources[uris[am.Name]] = true
+	}
+	return len(sources)
+}
+
+// isInconsistent returns true if Alertmanager instances reported an alert with
+// different labels, this is only possible when some labels are normalized or
+// ignored for deduplication, otherwise alerts with different labels are never
+// merged
+func isInconsistent(instances []models.AlertmanagerInstance) bool {
+	for i := 1; i < len(instances); i++ {
+		if !reflect.DeepEqual(instances[0].Labels, instances[i].Labels) {
+			return true
+		}
+	}
+	return false
 }
 
 // DedupColors returns a color map merged from all Alertmanager upstream color
@@ -120,15 +350,24 @@ func DedupColors() models.LabelsColorMap {
 }
 
 // DedupAutocomplete returns a list of autocomplete hints merged from all
-// Alertmanager upstreams
+// Alertmanager upstreams and hints for filters that need deduplicated alerts
 func DedupAutocomplete() []models.Autocomplete {
 	dedupedAutocomplete := []models.Autocomplete{}
 	uniqueAutocomplete := map[string]*models.Autocomplete{}
 
 	upstreams := GetAlertmanagers()
 
+	hints := [][]models.Autocomplete{}
 	for _, am := range upstreams {
-		ac := am.Autocomplete()
+		hints = append(hints, am.Autocomplete())
+	}
+	alerts := []models.Alert{}
+	for _, ag := range DedupAlerts() {
+		alerts = append(alerts, ag.Alerts...)
+	}
+	hints = append(hints, filters.BuildDeduplicatedAutocomplete(alerts))
+
+	for _, ac := range hints {
 		for _, hint := range ac {
 			h, found := uniqueAutocomplete[hint.Value]
 			if found {
@@ -192,3 +431,156 @@ func DedupKnownLabelValues(name string) []string {
 	}
 	return flatValues
 }
+
+// DedupSilences returns all active silences collected from all Alertmanager
+// upstreams, silences with the same ID found on members of the same cluster
+// are merged, distinct silences with identical matchers are kept separate, every silence includes the number of alerts it matches
+func DedupSilences() []models.ManagedSilence {
+	now := time.Now()
+
+	upstreams := GetAlertmanagers()
+	sort.Slice(upstreams, func(i, j int) bool {
+		return upstreams[i].Name < upstreams[j].Name
+	})
+
+	// cluster ID -> silence ID -> silence
+	silenceIndex := map[string]map[string]*models.ManagedSilence{}
+	dedupedSilences := []*models.ManagedSilence{}
+	for _, am := range upstreams {
+		cluster := am.ClusterID()
+		if _, found := silenceIndex[cluster]; !found {
+			silenceIndex[cluster] = map[string]*models.ManagedSilence{}
+		}
+
+		ids := []string{}
+		silences := am.Silences()
+		for id := range silences {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			silence := silences[id]
+			if silence.StartsAt.After(now) || !silence.EndsAt.After(now) {
+				continue
+			}
+			// members of the same cluster share silences via gossip so the
+			// same silence will have the same ID on all of them
+			ms, found := silenceIndex[cluster][silence.ID]
+			if !found {
+				ms = &models.ManagedSilence{
+					Silence: silence,
+					Cluster: cluster,
+					Sources: []models.SilenceSource{},
+				}
+				silenceIndex[cluster][silence.ID] = ms
+				dedupedSilences = append(dedupedSilences, ms)
+			}
+			ms.Sources = append(ms.Sources, models.SilenceSource{Alertmanager: am.Name, ID: silence.ID})
+		}
+	}
+
+	for _, ag := range DedupAlerts() {
+		for _, alert := range ag.Alerts {
+			// every alert is counted only once for each silence, even if it's
+			// silenced by multiple members of the same cluster
+			matched := map[*models.ManagedSilence]bool{}
+			for _, am := range alert.Alertmanager {
+				for _, silenceID := range am.SilencedBy {
+					if ms, found := silenceIndex[am.Cluster][silenceID]; found && !matched[ms] {
+						matched[ms] = true
+						ms.AlertCount++
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(dedupedSilences, func(i, j int) bool {
+		if dedupedSilences[i].Silence.EndsAt.Equal(dedupedSilences[j].Silence.EndsAt) {
+			return dedupedSilences[i].Silence.ID < dedupedSilences[j].Silence.ID
+		}
+		return dedupedSilences[i].Silence.EndsAt.Before(dedupedSilences[j].Silence.EndsAt)
+	})
+
+	managedSilences := make([]models.ManagedSilence, 0, len(dedupedSilences))
+	for _, ms := range dedupedSilences {
+		managedSilences = append(managedSilences, *ms)
+	}
+	return managedSilences
+}
+
+// mergeIgnoredLabels removes all ignored labels from alert groups and alerts,
+// groups and alerts that are left with the same labels are merged
+func mergeIgnoredLabels(groups []models.AlertGroup, ignoredLabels []string) []models.AlertGroup {
+	return mergeRelabeled(groups, func(labels map[string]string) map[string]string {
+		return transform.StripLables([]string{}, ignoredLabels, labels)
+	})
+}
+
+// mergeNormalizedLabels replaces label values on alert groups and alerts with
+// normalized ones, groups and alerts that end up with the same labels are
+// merged
+func mergeNormalizedLabels(groups []models.AlertGroup, lowercase []string, mapping map[string]map[string]string) []models.AlertGroup {
+	return mergeRelabeled(groups, func(labels map[string]string) map[string]string {
+		return transform.NormalizeLabelValues(lowercase, mapping, labels)
+	})
+}
+
+// mergeRelabeled replaces labels on alert groups and alerts with the result
+// of the relabel function and merges those that are left with the same labels
+// When multiple alerts are merged the one that started first is kept, if they
+// started at the same time the one with the lowest Alertmanager fingerprint is
+// used, so the result doesn't depend on the order alerts were collected in
+func mergeRelabeled(groups []models.AlertGroup, relabel func(map[string]string) map[string]string) []models.AlertGroup {
+	groupIDs := []string{}
+	uniqueGroups := map[string]models.AlertGroup{}
+	uniqueAlerts := map[string]map[string]models.Alert{}
+	for _, ag := range groups {
+		ag.Labels = relabel(ag.Labels)
+		agID := ag.LabelsFingerprint()
+		if _, found := uniqueGroups[agID]; !found {
+			groupIDs = append(groupIDs, agID)
+			uniqueGroups[agID] = ag
+			uniqueAlerts[agID] = map[string]models.Alert{}
+		}
+		for _, alert := range ag.Alerts {
+			if alert.SourceLabels == nil {
+				alert.SourceLabels = alert.Labels
+			}
+			alert.Labels = relabel(alert.Labels)
+			alert.UpdateFingerprints()
+			alertLFP := alert.LabelsFingerprint()
+			if a, found := uniqueAlerts[agID][alertLFP]; found {
+				if a.StartsAt.Before(alert.StartsAt) {
+					continue
+				}
+				if a.StartsAt.Equal(alert.StartsAt) && a.Fingerprint < alert.Fingerprint {
+					continue
+				}
+			}
+			uniqueAlerts[agID][alertLFP] = alert
+		}
+	}
+
+	merged := make([]models.AlertGroup, 0, len(groupIDs))
+	for _, agID := range groupIDs {
+		ag := uniqueGroups[agID]
+		alertLFPs := []string{}
+		for alertLFP := range uniqueAlerts[agID] {
+			alertLFPs = append(alertLFPs, alertLFP)
+		}
+		sort.Strings(alertLFPs)
+		ag.Alerts = models.AlertList{}
+		for _, alertLFP := range alertLFPs {
+			alert := uniqueAlerts[agID][alertLFP]
+			// fingerprint must match deduplicated labels so that alerts merged
+			// from different upstreams can be matched by it
+			alert.Fingerprint = mapper.AlertFingerprint(alert.Labels)
+			alert.UpdateFingerprints()
+			ag.Alerts = append(ag.Alerts, alert)
+		}
+		merged = append(merged, ag)
+	}
+	return merged
+}
@@ -63,6 +63,12 @@ func TestDedupAlerts(t *testing.T) {
 	totalAlerts := 0
 	for _, ag := range alertGroups {
 		totalAlerts += len(ag.Alerts)
+		for _, alert := range ag.Alerts {
+			// every mock upstream has a different URI
+			if alert.Sources != len(alert.Alertmanager) {
+				t.Errorf("Expected %d sources, got %d", len(alert.Alertmanager), alert.Sources)
+			}
+		}
 	}
 	if totalAlerts != 24 {
 		t.Errorf("Expected %d total alerts, got %d", 24, totalAlerts)
@@ -90,6 +96,110 @@ func TestDedupAlertsWithoutLabels(t *testing.T) {
 	}
 }
 
+func TestDedupStats(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	alertmanager.UpdateDedupStats()
+
+	// every upstream returns the same set of 24 alerts
+	raw, deduped, dropped := alertmanager.DedupStats()
+	if expected := 24 * len(mock.ListAllMocks()); raw != expected {
+		t.Errorf("Expected %d raw alerts, got %d", expected, raw)
+	}
+	if deduped != 24 {
+		t.Errorf("Expected %d deduplicated alerts, got %d", 24, deduped)
+	}
+	if dropped != 0 {
+		t.Errorf("Expected %d dropped alerts, got %d", 0, dropped)
+	}
+}
+
+func TestDedupAlertsMaxTotal(t *testing.T) {
+	config.Config.Alerts.MaxTotal = 10
+	defer func() { config.Config.Alerts.MaxTotal = 0 }()
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+	alertmanager.UpdateDedupStats()
+
+	// alerts above the limit are never stored
+	for _, am := range alertmanager.GetAlertmanagers() {
+		if am.AlertCount() > 10 {
+			t.Errorf("[%s] Got %d stored alerts, expected at most %d", am.Name, am.AlertCount(), 10)
+		}
+	}
+
+	_, deduped, dropped := alertmanager.DedupStats()
+	if deduped != 10 {
+		t.Errorf("Expected %d deduplicated alerts, got %d", 10, deduped)
+	}
+	if dropped != 14 {
+		t.Errorf("Expected %d dropped alerts, got %d", 14, dropped)
+	}
+
+	fingerprints := func() map[string]bool {
+		fps := map[string]bool{}
+		for _, ag := range alertmanager.DedupAlerts() {
+			for _, alert := range ag.Alerts {
+				fps[alert.LabelsFingerprint()] = true
+			}
+		}
+		return fps
+	}
+	first := fingerprints()
+	if len(first) != 10 {
+		t.Errorf("Expected %d alerts, got %d", 10, len(first))
+	}
+	// the same alerts must be dropped every time
+	for i := 0; i < 5; i++ {
+		for fp := range fingerprints() {
+			if !first[fp] {
+				t.Errorf("Alert %s wasn't returned by the first DedupAlerts() call", fp)
+			}
+		}
+	}
+}
+
+func TestDedupSilences(t *testing.T) {
+	if err := pullAlerts(); err != nil {
+		t.Error(err)
+	}
+
+	// every upstream returns the same 3 silences, upstreams from the same
+	// cluster should have them merged
+	clusters := map[string][]string{}
+	for _, am := range alertmanager.GetAlertmanagers() {
+		clusters[am.ClusterID()] = append(clusters[am.ClusterID()], am.Name)
+	}
+	if len(clusters) == len(alertmanager.GetAlertmanagers()) {
+		t.Fatalf("All upstreams are in different clusters, can't test deduplication")
+	}
+
+	silences := alertmanager.DedupSilences()
+	if expected := 3 * len(clusters); len(silences) != expected {
+		t.Errorf("Expected %d silences, got %d", expected, len(silences))
+	}
+
+	perCluster := map[string]int{}
+	for _, ms := range silences {
+		perCluster[ms.Cluster]++
+		if len(ms.Sources) != len(clusters[ms.Cluster]) {
+			t.Errorf("Silence %s in cluster %s has %d source(s), expected %d", ms.Silence.ID, ms.Cluster, len(ms.Sources), len(clusters[ms.Cluster]))
+		}
+		for _, source := range ms.Sources {
+			if alertmanager.GetAlertmanagerByName(source.Alertmanager).ClusterID() != ms.Cluster {
+				t.Errorf("Silence %s in cluster %s has source %s from a different cluster", ms.Silence.ID, ms.Cluster, source.Alertmanager)
+			}
+		}
+	}
+	for cluster, count := range perCluster {
+		if count != 3 {
+			t.Errorf("Expected %d silences in cluster %s, got %d", 3, cluster, count)
+		}
+	}
+}
+
 func TestDedupAutocomplete(t *testing.T) {
 	if err := pullAlerts(); err != nil {
 		t.Error(err)
@@ -0,0 +1,58 @@
+package alertmanager
+
+import (
+	"sync"
+	"time"
+)
+
+// firstSeenRetention is how long we remember alerts that are no longer
+// returned by Alertmanager, if an alert fires again within that time it will
+// keep the original first seen timestamp
+const firstSeenRetention = time.Hour
+
+// firstSeenTracker records when karma first observed each alert, unlike
+// StartsAt it doesn't change when Alertmanager resets it
+// Alerts are tracked using labels fingerprint
+type firstSeenTracker struct {
+	lock      sync.Mutex
+	firstSeen map[string]time.Time
+	lastSeen  map[string]time.Time
+}
+
+func newFirstSeenTracker() *firstSeenTracker {
+	return &firstSeenTracker{
+		firstSeen: map[string]time.Time{},
+		lastSeen:  map[string]time.Time{},
+	}
+}
+
+// update records all alerts from the most recent collection cycle and forgets
+// alerts that were not seen for longer than the retention period
+func (fst *firstSeenTracker) update(now time.Time, fingerprints []string) {
+	fst.lock.Lock()
+	defer fst.lock.Unlock()
+
+	for _, fp := range fingerprints {
+		if _, found := fst.firstSeen[fp]; !found {
+			fst.firstSeen[fp] = now
+		}
+		fst.lastSeen[fp] = now
+	}
+
+	since := now.Add(-firstSeenRetention)
+	for fp, ts := range fst.lastSeen {
+		if ts.Before(since) {
+			delete(fst.firstSeen, fp)
+			delete(fst.lastSeen, fp)
+		}
+	}
+}
+
+// get returns the first seen timestamp for given alert, it will be zero if
+// the alert is not known
+func (fst *firstSeenTracker) get(fp string) time.Time {
+	fst.lock.Lock()
+	defer fst.lock.Unlock()
+
+	return fst.firstSeen[fp]
+}
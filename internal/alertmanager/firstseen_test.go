@@ -0,0 +1,102 @@
+package alertmanager
+
+import (
+	"io/ioutil"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prymitive/karma/internal/mock"
+)
+
+func TestFirstSeenTracker(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	fst := newFirstSeenTracker()
+
+	if ts := fst.get("a"); !ts.IsZero() {
+		t.Errorf("get() returned %s for unknown alert", ts)
+	}
+
+	fst.update(now, []string{"a"})
+	fst.update(now.Add(time.Minute), []string{"a", "b"})
+	fst.update(now.Add(time.Minute*2), []string{"b"})
+
+	if ts := fst.get("a"); !ts.Equal(now) {
+		t.Errorf("get(a) returned %s, expected %s", ts, now)
+	}
+	if ts := fst.get("b"); !ts.Equal(now.Add(time.Minute)) {
+		t.Errorf("get(b) returned %s, expected %s", ts, now.Add(time.Minute))
+	}
+
+	// alert that fires again within the retention period keeps first seen
+	fst.update(now.Add(firstSeenRetention), []string{"a", "b"})
+	if ts := fst.get("a"); !ts.Equal(now) {
+		t.Errorf("get(a) returned %s after firing again, expected %s", ts, now)
+	}
+
+	// alert that wasn't seen for longer than the retention period is forgotten
+	resetAt := now.Add(firstSeenRetention*2 + time.Minute)
+	fst.update(now.Add(firstSeenRetention*2), []string{"b"})
+	fst.update(resetAt, []string{"a", "b"})
+	if ts := fst.get("a"); !ts.Equal(resetAt) {
+		t.Errorf("get(a) returned %s after retention period, expected %s", ts, resetAt)
+	}
+	if ts := fst.get("b"); !ts.Equal(now.Add(time.Minute)) {
+		t.Errorf("get(b) returned %s, expected %s", ts, now.Add(time.Minute))
+	}
+}
+
+func TestFirstSeenAfterStartsAtReset(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	am, err := NewAlertmanager("test", "http://localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadFile(mock.GetAbsoluteMockPath("api/v1/alerts/groups", "0.15.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(payload []byte) (map[string]time.Time, map[string]time.Time) {
+		httpmock.Reset()
+		httpmock.RegisterResponder("GET", "http://localhost/api/v1/alerts/groups", httpmock.NewBytesResponder(200, payload))
+		if err := am.pullAlerts("0.15.3"); err != nil {
+			t.Fatalf("pullAlerts() returned an error: %s", err)
+		}
+		startsAt := map[string]time.Time{}
+		firstSeen := map[string]time.Time{}
+		for _, ag := range am.Alerts() {
+			for _, alert := range ag.Alerts {
+				startsAt[alert.LabelsFingerprint()] = alert.StartsAt
+				firstSeen[alert.LabelsFingerprint()] = alert.KarmaFirstSeen
+			}
+		}
+		return startsAt, firstSeen
+	}
+
+	startsAt, firstSeen := collect(body)
+	if len(firstSeen) == 0 {
+		t.Fatal("No alerts collected")
+	}
+	for fp, ts := range firstSeen {
+		if ts.IsZero() {
+			t.Errorf("Alert %s has empty first seen timestamp", fp)
+		}
+	}
+
+	// Alertmanager reset startsAt on all alerts
+	reset := regexp.MustCompile(`"startsAt": "[^"]+"`).ReplaceAll(body, []byte(`"startsAt": "2019-08-06T10:00:00Z"`))
+	resetStartsAt, resetFirstSeen := collect(reset)
+	for fp, ts := range resetFirstSeen {
+		if resetStartsAt[fp].Equal(startsAt[fp]) {
+			t.Errorf("Alert %s startsAt wasn't updated: %s", fp, resetStartsAt[fp])
+		}
+		if !ts.Equal(firstSeen[fp]) {
+			t.Errorf("Alert %s first seen timestamp changed from %s to %s after startsAt reset", fp, firstSeen[fp], ts)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package alertmanager
+
+import (
+	"sync"
+	"time"
+)
+
+// flapDetector tracks alert state changes between collection cycles, it's used
+// to find alerts that rapidly fire and resolve
+// Alerts are tracked using labels fingerprint, an alert that is missing from
+// the collected data is considered resolved
+type flapDetector struct {
+	lock sync.Mutex
+	// false until the first update, alerts present in the first collection
+	// cycle are not counted as state changes
+	initialized bool
+	// last known state for every alert that is not resolved
+	states map[string]string
+	// timestamps of all state changes within the window
+	changes map[string][]time.Time
+	// timestamp of the most recent state change for every alert that is not
+	// resolved, it's kept even if it's older than the window
+	lastChanges map[string]time.Time
+}
+
+func newFlapDetector() *flapDetector {
+	return &flapDetector{
+		states:      map[string]string{},
+		changes:     map[string][]time.Time{},
+		lastChanges: map[string]time.Time{},
+	}
+}
+
+// update records alert states from the most recent collection cycle and
+// forgets all changes older than the window
+func (fd *flapDetector) update(now time.Time, window time.Duration, states map[string]string) {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	if fd.initialized {
+		for fp, state := range states {
+			if fd.states[fp] != state {
+				fd.changes[fp] = append(fd.changes[fp], now)
+				fd.lastChanges[fp] = now
+			}
+		}
+		for fp := range fd.states {
+			if _, found := states[fp]; !found {
+				// alert was resolved
+				fd.changes[fp] = append(fd.changes[fp], now)
+			}
+		}
+	}
+	fd.initialized = true
+
+	fd.states = make(map[string]string, len(states))
+	for fp, state := range states {
+		fd.states[fp] = state
+	}
+	for fp := range fd.lastChanges {
+		if _, found := states[fp]; !found {
+			delete(fd.lastChanges, fp)
+		}
+	}
+
+	since := now.Add(-window)
+	for fp, timestamps := range fd.changes {
+		recent := []time.Time{}
+		for _, ts := range timestamps {
+			if ts.After(since) {
+				recent = append(recent, ts)
+			}
+		}
+		if len(recent) == 0 {
+			delete(fd.changes, fp)
+		} else {
+			fd.changes[fp] = recent
+		}
+	}
+}
+
+// changeCount returns the number of state changes for given alert within the
+// window
+func (fd *flapDetector) changeCount(fp string) int {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	return len(fd.changes[fp])
+}
+
+// lastChange returns the timestamp of the most recent state change for given
+// alert, it's zero if no change was recorded
+func (fd *flapDetector) lastChange(fp string) time.Time {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+
+	return fd.lastChanges[fp]
+}
@@ -0,0 +1,142 @@
+package alertmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+const (
+	active     = models.AlertStateActive
+	suppressed = models.AlertStateSuppressed
+)
+
+type flapDetectorTest struct {
+	name    string
+	history []map[string]string
+	changes map[string]int
+}
+
+var flapDetectorTests = []flapDetectorTest{
+	{
+		name: "alerts present on the first update are not counted",
+		history: []map[string]string{
+			{"a": active, "b": suppressed},
+		},
+		changes: map[string]int{"a": 0, "b": 0},
+	},
+	{
+		name: "stable alerts have no changes",
+		history: []map[string]string{
+			{"a": active},
+			{"a": active},
+			{"a": active},
+		},
+		changes: map[string]int{"a": 0},
+	},
+	{
+		name: "new alert is a change",
+		history: []map[string]string{
+			{},
+			{"a": active},
+		},
+		changes: map[string]int{"a": 1},
+	},
+	{
+		name: "firing and resolving alert",
+		history: []map[string]string{
+			{"a": active},
+			{},
+			{"a": active},
+			{},
+			{"a": active},
+		},
+		changes: map[string]int{"a": 4},
+	},
+	{
+		name: "state changes are counted",
+		history: []map[string]string{
+			{"a": active, "b": active},
+			{"a": suppressed, "b": active},
+			{"a": active, "b": active},
+		},
+		changes: map[string]int{"a": 2, "b": 0},
+	},
+	{
+		name: "changes older than the window are forgotten",
+		history: []map[string]string{
+			{"a": active},
+			{},
+			{"a": active},
+			{"a": active},
+			{"a": active},
+			{"a": active},
+			{"a": active},
+			{"a": active},
+		},
+		changes: map[string]int{"a": 1},
+	},
+}
+
+func TestFlapDetector(t *testing.T) {
+	// updates are one minute apart, window is 5 minutes
+	window := time.Minute * 5
+	for _, testCase := range flapDetectorTests {
+		fd := newFlapDetector()
+		now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		for _, states := range testCase.history {
+			now = now.Add(time.Minute)
+			fd.update(now, window, states)
+		}
+		for fp, expected := range testCase.changes {
+			if count := fd.changeCount(fp); count != expected {
+				t.Errorf("[%s] Alert '%s' has %d change(s), expected %d", testCase.name, fp, count, expected)
+			}
+		}
+	}
+}
+
+func TestFlapDetectorForgetsResolvedAlerts(t *testing.T) {
+	fd := newFlapDetector()
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	fd.update(now, time.Minute, map[string]string{"a": active})
+	fd.update(now.Add(time.Second), time.Minute, map[string]string{})
+	fd.update(now.Add(time.Minute*2), time.Minute, map[string]string{})
+	if len(fd.states) != 0 || len(fd.changes) != 0 || len(fd.lastChanges) != 0 {
+		t.Errorf("Resolved alert was not removed, states=%v changes=%v lastChanges=%v", fd.states, fd.changes, fd.lastChanges)
+	}
+}
+
+func TestFlapDetectorLastChange(t *testing.T) {
+	// updates are one minute apart, the window is shorter than the log so last
+	// changes must be kept after they are no longer counted as flapping
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []map[string]string{
+		{"a": active, "b": active},
+		{"a": suppressed, "b": active, "c": active},
+		{"a": suppressed, "b": active, "c": active},
+		{"a": active, "b": active, "c": active},
+		{"a": active, "b": active, "c": active},
+		{"a": active, "b": active, "c": active},
+	}
+	fd := newFlapDetector()
+	for i, states := range history {
+		fd.update(start.Add(time.Minute*time.Duration(i)), time.Minute*2, states)
+	}
+
+	for fp, expected := range map[string]time.Time{
+		// changed state twice, only the last change is reported
+		"a": start.Add(time.Minute * 3),
+		// present on the first update and never changed
+		"b": {},
+		// fired after the first update
+		"c": start.Add(time.Minute),
+		// never seen
+		"d": {},
+	} {
+		if ts := fd.lastChange(fp); !ts.Equal(expected) {
+			t.Errorf("Alert '%s' last changed state at %s, expected %s", fp, ts, expected)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package alertmanager
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// jitterRand uses a dedicated source since the global one is re-seeded with
+// label values when generating colors, which would make every karma replica
+// use the same jitter sequence
+var (
+	jitterLock sync.Mutex
+	jitterRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// jitteredInterval returns the interval moved by up to jitter percent of it in
+// either direction, random must be in the [0, 1) range and selects where in
+// that range the result is
+func jitteredInterval(interval time.Duration, jitter int, random float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	delta := float64(interval) * float64(jitter) / 100
+	return interval + time.Duration(delta*(random*2-1))
+}
+
+// PullInterval returns how long to wait before the next pull, it's the
+// interval randomized by up to jitter percent so that multiple karma instances
+// pulling from the same Alertmanager servers won't synchronize
+func PullInterval(interval time.Duration, jitter int) time.Duration {
+	jitterLock.Lock()
+	random := jitterRand.Float64()
+	jitterLock.Unlock()
+
+	return jitteredInterval(interval, jitter, random)
+}
@@ -0,0 +1,54 @@
+package alertmanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitteredInterval(t *testing.T) {
+	for _, testCase := range []struct {
+		interval time.Duration
+		jitter   int
+		random   float64
+		expected time.Duration
+	}{
+		{interval: time.Minute, jitter: 0, random: 0, expected: time.Minute},
+		{interval: time.Minute, jitter: 0, random: 0.99, expected: time.Minute},
+		{interval: time.Minute, jitter: -10, random: 0, expected: time.Minute},
+		{interval: time.Minute, jitter: 10, random: 0, expected: time.Second * 54},
+		{interval: time.Minute, jitter: 10, random: 0.5, expected: time.Minute},
+		{interval: time.Minute, jitter: 10, random: 0.75, expected: time.Second * 63},
+		{interval: time.Minute, jitter: 50, random: 0, expected: time.Second * 30},
+	} {
+		if got := jitteredInterval(testCase.interval, testCase.jitter, testCase.random); got != testCase.expected {
+			t.Errorf("jitteredInterval(%s, %d, %f) returned %s, expected %s",
+				testCase.interval, testCase.jitter, testCase.random, got, testCase.expected)
+		}
+	}
+}
+
+func TestPullInterval(t *testing.T) {
+	for _, testCase := range []struct {
+		interval time.Duration
+		jitter   int
+		min      time.Duration
+		max      time.Duration
+	}{
+		{interval: time.Minute, jitter: 0, min: time.Minute, max: time.Minute},
+		{interval: time.Minute, jitter: 10, min: time.Second * 54, max: time.Second * 66},
+		{interval: time.Second * 30, jitter: 50, min: time.Second * 15, max: time.Second * 45},
+	} {
+		seen := map[time.Duration]bool{}
+		for i := 0; i < 1000; i++ {
+			got := PullInterval(testCase.interval, testCase.jitter)
+			if got < testCase.min || got > testCase.max {
+				t.Fatalf("PullInterval(%s, %d) returned %s, expected a value between %s and %s",
+					testCase.interval, testCase.jitter, got, testCase.min, testCase.max)
+			}
+			seen[got] = true
+		}
+		if testCase.jitter > 0 && len(seen) == 1 {
+			t.Errorf("PullInterval(%s, %d) returned the same value on every call", testCase.interval, testCase.jitter)
+		}
+	}
+}
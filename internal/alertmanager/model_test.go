@@ -1,7 +1,21 @@
 package alertmanager
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/mapper"
+	"github.com/prymitive/karma/internal/mock"
+	"github.com/prymitive/karma/internal/models"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jarcoal/httpmock"
 )
 
 type uriTest struct {
@@ -85,3 +99,789 @@ func TestAlertmanagerURI(t *testing.T) {
 		}
 	}
 }
+
+func TestAlertmanagerCollectionTimeBeforePull(t *testing.T) {
+	am, err := NewAlertmanager("test", "http://localhost")
+	if err != nil {
+		t.Error(err)
+	}
+	if am.LastCollectionDuration() != 0 {
+		t.Errorf("LastCollectionDuration() returned %s before any pull, expected 0", am.LastCollectionDuration())
+	}
+	if !am.LastCollectionTimestamp().IsZero() {
+		t.Errorf("LastCollectionTimestamp() returned %s before any pull, expected zero time", am.LastCollectionTimestamp())
+	}
+}
+
+const v2StatusPayload = `{
+  "cluster": {
+    "name": "01DKVXM2HWFKMWZPCN8HAJSKDA",
+    "peers": [
+      {"address": "172.17.0.2:9094", "name": "01DKVXM2HWFKMWZPCN8HAJSKDA"},
+      {"address": "172.17.0.3:9094", "name": "01DKVXM2HWFKMWZPCN8HAJSKDB"}
+    ]%s
+  },
+  "config": {"original": "global: {}"},
+  "uptime": "2019-09-03T09:33:45.426Z",
+  "versionInfo": {
+    "branch": "HEAD",
+    "buildDate": "20190903-15:01:40",
+    "buildUser": "root@1e8a6a8c3a84",
+    "goVersion": "go1.12.8",
+    "revision": "7aa5d19fea3f58e3d27dbdeb0f2883037168914a",
+    "version": "0.19.0"
+  }
+}`
+
+const v1StatusPayload = `{
+  "status": "success",
+  "data": {
+    "clusterStatus": {
+      "name": "01DHJ0AGWSMBHSJHHW2A73TR43",
+      "peers": [{"address": "172.17.0.2:9094", "name": "01DHJ0AGWSMBHSJHHW2A73TR43"}]%s
+    },
+    "versionInfo": {"version": "0.15.3"}
+  }
+}`
+
+func TestClusterPeerStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, testCase := range []struct {
+		version string
+		path    string
+		payload string
+		status  string
+	}{
+		{version: "0.19.0", path: "/api/v2/status", payload: fmt.Sprintf(v2StatusPayload, `, "status": "ready"`), status: "ready"},
+		{version: "0.19.0", path: "/api/v2/status", payload: fmt.Sprintf(v2StatusPayload, `, "status": "settling"`), status: "settling"},
+		{version: "0.19.0", path: "/api/v2/status", payload: fmt.Sprintf(v2StatusPayload, `, "status": "disabled"`), status: "disabled"},
+		{version: "0.19.0", path: "/api/v2/status", payload: fmt.Sprintf(v2StatusPayload, ""), status: "unknown"},
+		{version: "0.15.3", path: "/api/v1/status", payload: fmt.Sprintf(v1StatusPayload, `, "status": "settling"`), status: "settling"},
+		{version: "0.15.3", path: "/api/v1/status", payload: fmt.Sprintf(v1StatusPayload, ""), status: "unknown"},
+	} {
+		am, err := NewAlertmanager("test", "http://localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if am.ClusterPeerStatus() != "unknown" {
+			t.Errorf("[%s] ClusterPeerStatus() returned %q before collecting status", testCase.version, am.ClusterPeerStatus())
+		}
+
+		httpmock.Reset()
+		httpmock.RegisterResponder("GET", "http://localhost"+testCase.path, httpmock.NewStringResponder(200, testCase.payload))
+
+		status, err := am.fetchStatus(testCase.version)
+		if err != nil {
+			t.Errorf("[%s] fetchStatus() returned an error: %s", testCase.version, err)
+			continue
+		}
+		am.status = *status
+		if am.ClusterPeerStatus() != testCase.status {
+			t.Errorf("[%s] ClusterPeerStatus() returned %q, expected %q, payload: %s", testCase.version, am.ClusterPeerStatus(), testCase.status, testCase.payload)
+		}
+	}
+}
+
+func TestReadyClusterMembers(t *testing.T) {
+	defaultUpstreams := upstreams
+	defer func() {
+		upstreams = defaultUpstreams
+	}()
+
+	upstreams = map[string]*Alertmanager{}
+	for _, member := range []struct {
+		name     string
+		status   string
+		err      string
+		priority int
+		peers    []string
+	}{
+		{name: "am1", status: "ready", peers: []string{"peer1", "peer2", "peer3", "peer4"}},
+		{name: "am2", status: "settling", peers: []string{"peer1", "peer2", "peer3", "peer4"}},
+		{name: "am3", status: "ready", err: "connection refused", peers: []string{"peer1", "peer2", "peer3", "peer4"}},
+		{name: "am4", status: "ready", priority: 10, peers: []string{"peer1", "peer2", "peer3", "peer4"}},
+		{name: "other", status: "ready", peers: []string{"other"}},
+	} {
+		am, err := NewAlertmanager(member.name, fmt.Sprintf("http://%s.localhost", member.name), WithPriority(member.priority))
+		if err != nil {
+			t.Fatal(err)
+		}
+		am.status = models.AlertmanagerStatus{ClusterStatus: member.status, PeerIDs: member.peers}
+		if member.err != "" {
+			am.setError(member.err)
+		}
+		upstreams[member.name] = am
+	}
+
+	for _, testCase := range []struct {
+		name    string
+		members []string
+	}{
+		{name: "am1", members: []string{"am1", "am4"}},
+		{name: "am2", members: []string{"am1", "am4"}},
+		{name: "other", members: []string{"other"}},
+	} {
+		names := []string{}
+		for _, am := range upstreams[testCase.name].ReadyClusterMembers() {
+			names = append(names, am.Name)
+		}
+		if diff := cmp.Diff(testCase.members, names); diff != "" {
+			t.Errorf("[%s] ReadyClusterMembers() returned wrong members (-want +got):\n%s", testCase.name, diff)
+		}
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	// responder that will only reply after a second unless request is cancelled
+	httpmock.RegisterResponder("GET", "http://localhost/api/v1/status", func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-req.Cancel:
+			return nil, fmt.Errorf("request cancelled")
+		case <-time.After(time.Second):
+			return httpmock.NewStringResponse(200, fmt.Sprintf(v1StatusPayload, "")), nil
+		}
+	})
+
+	for _, testCase := range []struct {
+		timeout  time.Duration
+		expected time.Duration
+		isError  bool
+	}{
+		{timeout: time.Millisecond * 50, expected: time.Millisecond * 50, isError: true},
+		{timeout: time.Second * 5, expected: time.Second * 5, isError: false},
+		{timeout: 0, expected: time.Second * 10, isError: false},
+		{timeout: -time.Second, expected: time.Second * 10, isError: false},
+	} {
+		am, err := NewAlertmanager("test", "http://localhost", WithRequestTimeout(testCase.timeout))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if am.RequestTimeout != testCase.expected {
+			t.Errorf("[%v] Got request timeout %v, expected %v", testCase.timeout, am.RequestTimeout, testCase.expected)
+		}
+
+		_, err = am.fetchStatus("0.15.3")
+		if testCase.isError && err == nil {
+			t.Errorf("[%v] fetchStatus() didn't return any error", testCase.timeout)
+		}
+		if !testCase.isError && err != nil {
+			t.Errorf("[%v] fetchStatus() returned an error: %s", testCase.timeout, err)
+		}
+	}
+}
+
+func TestStaticFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sample, err := ioutil.ReadFile(mock.GetAbsoluteMockPath("static.json", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "alerts.json")
+	err = ioutil.WriteFile(filename, sample, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	am, err := NewAlertmanager("static", "file://"+filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = am.Pull()
+	if err != nil {
+		t.Errorf("Pull() returned an error: %s", err)
+	}
+	if am.Version() != "static" {
+		t.Errorf("Version() returned %q, expected %q", am.Version(), "static")
+	}
+	if am.Error() != "" {
+		t.Errorf("Error() returned %q", am.Error())
+	}
+	if am.AlertCount() != 24 {
+		t.Errorf("Got %d alert(s), expected %d", am.AlertCount(), 24)
+	}
+	if len(am.Silences()) != 3 {
+		t.Errorf("Got %d silence(s), expected %d", len(am.Silences()), 3)
+	}
+	for _, ag := range am.Alerts() {
+		for _, alert := range ag.Alerts {
+			for _, silenceID := range alert.SilencedBy {
+				if _, found := alert.Alertmanager[0].Silences[silenceID]; !found {
+					t.Errorf("Silence %s is missing on alert %v", silenceID, alert.Labels)
+				}
+			}
+		}
+	}
+	if _, err = am.CreateSilence(models.Silence{}); err == nil {
+		t.Errorf("CreateSilence() didn't return any error")
+	}
+
+	// file is re-read on every pull
+	err = ioutil.WriteFile(filename, []byte(`{"alerts": [], "silences": []}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = am.Pull()
+	if err != nil {
+		t.Errorf("Pull() returned an error: %s", err)
+	}
+	if am.AlertCount() != 0 {
+		t.Errorf("Got %d alert(s) after the file was modified, expected 0", am.AlertCount())
+	}
+
+	// invalid files are not reported as upstream errors and old data is kept
+	err = ioutil.WriteFile(filename, sample, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = am.Pull(); err != nil {
+		t.Errorf("Pull() returned an error: %s", err)
+	}
+	for _, content := range []string{`{"alerts": `, `{"alerts": [{"labels": {}}]}`} {
+		err = ioutil.WriteFile(filename, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = am.Pull(); err == nil {
+			t.Errorf("Pull() didn't return any error for %s", content)
+		}
+		if am.Error() != "" {
+			t.Errorf("Error() returned %q for %s", am.Error(), content)
+		}
+		if am.AlertCount() != 24 {
+			t.Errorf("Got %d alert(s) after reading %s, expected %d", am.AlertCount(), content, 24)
+		}
+	}
+
+	os.Remove(filename)
+	if err = am.Pull(); err == nil {
+		t.Errorf("Pull() didn't return any error for a missing file")
+	}
+	if am.Error() != "" {
+		t.Errorf("Error() returned %q for a missing file", am.Error())
+	}
+}
+
+func TestDedupAlertsInconsistent(t *testing.T) {
+	defaultUpstreams := upstreams
+	defer func() {
+		upstreams = defaultUpstreams
+		config.Config.Dedup.IgnoreLabels = []string{}
+	}()
+
+	newAlert := func(labels map[string]string) models.Alert {
+		alert := models.Alert{Labels: labels, State: models.AlertStateActive, Receiver: "default"}
+		alert.UpdateFingerprints()
+		return alert
+	}
+	alertA := newAlert(map[string]string{"alertname": "Foo", "instance": "a", "env": "prod"})
+	alertB := newAlert(map[string]string{"alertname": "Foo", "instance": "b", "env": "prod"})
+	alertADrift := newAlert(map[string]string{"alertname": "Foo", "instance": "a", "env": "staging"})
+
+	type testCaseT struct {
+		name         string
+		ignoreLabels []string
+		am1          []models.Alert
+		am2          []models.Alert
+		inconsistent map[string]bool
+	}
+	for _, testCase := range []testCaseT{
+		{
+			name:         "sources agree on labels",
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertA, alertB},
+			inconsistent: map[string]bool{"a/prod": false, "b/prod": false},
+		},
+		{
+			name:         "sources agree on labels with ignored labels",
+			ignoreLabels: []string{"env"},
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertA, alertB},
+			inconsistent: map[string]bool{"a": false, "b": false},
+		},
+		{
+			name:         "sources disagree on ignored labels",
+			ignoreLabels: []string{"env"},
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertADrift, alertB},
+			inconsistent: map[string]bool{"a": true, "b": false},
+		},
+		{
+			name:         "alerts with different labels are not merged",
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertADrift, alertB},
+			inconsistent: map[string]bool{"a/prod": false, "a/staging": false, "b/prod": false},
+		},
+		{
+			name:         "alert reported by a single source",
+			ignoreLabels: []string{"env"},
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertB},
+			inconsistent: map[string]bool{"a": false, "b": false},
+		},
+	} {
+		testCase := testCase // scopelint pin
+		t.Run(testCase.name, func(t *testing.T) {
+			config.Config.Dedup.IgnoreLabels = testCase.ignoreLabels
+			upstreams = map[string]*Alertmanager{}
+			ams := []*Alertmanager{}
+			for _, name := range []string{"am1", "am2"} {
+				am, err := NewAlertmanager(name, fmt.Sprintf("http://%s.localhost", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				am.status = models.AlertmanagerStatus{PeerIDs: []string{"peer1", "peer2"}}
+				upstreams[name] = am
+				ams = append(ams, am)
+			}
+			for i, alerts := range [][]models.Alert{testCase.am1, testCase.am2} {
+				ams[i].setAlertGroups([]models.AlertGroup{
+					{
+						Receiver: "default",
+						Labels:   map[string]string{"alertname": "Foo"},
+						Alerts:   alerts,
+					},
+				})
+			}
+
+			inconsistent := map[string]bool{}
+			for _, ag := range DedupAlerts() {
+				for _, alert := range ag.Alerts {
+					key := alert.Labels["instance"]
+					if env, found := alert.Labels["env"]; found {
+						key += "/" + env
+					}
+					inconsistent[key] = alert.Inconsistent
+				}
+			}
+			if diff := cmp.Diff(testCase.inconsistent, inconsistent); diff != "" {
+				t.Errorf("Wrong inconsistent alerts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDedupAlertsClusterDisagreement(t *testing.T) {
+	defaultUpstreams := upstreams
+	defer func() {
+		upstreams = defaultUpstreams
+	}()
+
+	newAlert := func(labels map[string]string) models.Alert {
+		alert := models.Alert{Labels: labels, State: models.AlertStateActive, Receiver: "default"}
+		alert.UpdateFingerprints()
+		return alert
+	}
+	alertA := newAlert(map[string]string{"alertname": "Foo", "instance": "a"})
+	alertB := newAlert(map[string]string{"alertname": "Foo", "instance": "b"})
+
+	type testCaseT struct {
+		name         string
+		am1          []models.Alert
+		am2          []models.Alert
+		am2Error     string
+		disagreement map[string]bool
+	}
+	for _, testCase := range []testCaseT{
+		{
+			name:         "all members report all alerts",
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertA, alertB},
+			disagreement: map[string]bool{"a": false, "b": false},
+		},
+		{
+			name:         "member is missing an alert",
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{alertB},
+			disagreement: map[string]bool{"a": true, "b": false},
+		},
+		{
+			name:         "each member is missing a different alert",
+			am1:          []models.Alert{alertA},
+			am2:          []models.Alert{alertB},
+			disagreement: map[string]bool{"a": true, "b": true},
+		},
+		{
+			name:         "unhealthy member is ignored",
+			am1:          []models.Alert{alertA, alertB},
+			am2:          []models.Alert{},
+			am2Error:     "connection refused",
+			disagreement: map[string]bool{"a": false, "b": false},
+		},
+	} {
+		testCase := testCase // scopelint pin
+		t.Run(testCase.name, func(t *testing.T) {
+			upstreams = map[string]*Alertmanager{}
+			ams := []*Alertmanager{}
+			for _, name := range []string{"am1", "am2"} {
+				am, err := NewAlertmanager(name, fmt.Sprintf("http://%s.localhost", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				am.status = models.AlertmanagerStatus{PeerIDs: []string{"peer1", "peer2"}}
+				upstreams[name] = am
+				ams = append(ams, am)
+			}
+			for i, alerts := range [][]models.Alert{testCase.am1, testCase.am2} {
+				ams[i].setAlertGroups([]models.AlertGroup{
+					{
+						Receiver: "default",
+						Labels:   map[string]string{"alertname": "Foo"},
+						Alerts:   alerts,
+					},
+				})
+			}
+			if testCase.am2Error != "" {
+				ams[1].setError(testCase.am2Error)
+			}
+
+			disagreement := map[string]bool{}
+			for _, ag := range DedupAlerts() {
+				for _, alert := range ag.Alerts {
+					disagreement[alert.Labels["instance"]] = alert.ClusterDisagreement
+				}
+			}
+			if diff := cmp.Diff(testCase.disagreement, disagreement); diff != "" {
+				t.Errorf("Wrong cluster disagreement alerts (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeIgnoredLabels(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(instance string, startsAt time.Duration, extra map[string]string) models.AlertGroup {
+		labels := map[string]string{"alertname": "Foo", "instance": instance}
+		for k, v := range extra {
+			labels[k] = v
+		}
+		alert := models.Alert{
+			Labels:      labels,
+			StartsAt:    ts.Add(startsAt),
+			State:       models.AlertStateActive,
+			Receiver:    "default",
+			Fingerprint: mapper.AlertFingerprint(labels),
+		}
+		alert.UpdateFingerprints()
+		return models.AlertGroup{
+			Receiver: "default",
+			Labels:   map[string]string{"alertname": "Foo", "instance": instance},
+			Alerts:   models.AlertList{alert},
+		}
+	}
+	groups := []models.AlertGroup{
+		newGroup("a", time.Minute, nil),
+		newGroup("b", 0, nil),
+		newGroup("c", time.Minute, map[string]string{"env": "staging"}),
+	}
+
+	for _, source := range [][]models.AlertGroup{groups, {groups[2], groups[1], groups[0]}} {
+		merged := mergeIgnoredLabels(source, []string{"instance"})
+		if len(merged) != 1 {
+			t.Fatalf("Got %d group(s), expected 1", len(merged))
+		}
+		if diff := cmp.Diff(map[string]string{"alertname": "Foo"}, merged[0].Labels); diff != "" {
+			t.Errorf("Wrong group labels (-want +got):\n%s", diff)
+		}
+		if len(merged[0].Alerts) != 2 {
+			t.Fatalf("Got %d alert(s), expected 2", len(merged[0].Alerts))
+		}
+		for _, alert := range merged[0].Alerts {
+			if _, found := alert.Labels["instance"]; found {
+				t.Errorf("Ignored label is present on alert: %v", alert.Labels)
+			}
+			if alert.Fingerprint != mapper.AlertFingerprint(alert.Labels) {
+				t.Errorf("Fingerprint %s doesn't match labels %v", alert.Fingerprint, alert.Labels)
+			}
+			if alert.Labels["env"] == "" && !alert.StartsAt.Equal(ts) {
+				t.Errorf("Merged alert has startsAt=%s, expected the earliest one %s", alert.StartsAt, ts)
+			}
+		}
+	}
+
+	config.Config.Dedup.IgnoreLabels = []string{"instance"}
+	defer func() {
+		config.Config.Dedup.IgnoreLabels = []string{}
+	}()
+	am, err := NewAlertmanager("dedup", "http://dedup.localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.setAlertGroups(groups[:2])
+	if am.AlertCount() != 1 {
+		t.Errorf("Got %d alert(s) after setAlertGroups(), expected 1", am.AlertCount())
+	}
+}
+
+func TestMergeNormalizedLabels(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newGroup := func(severity string, startsAt time.Duration) models.AlertGroup {
+		labels := map[string]string{"alertname": "Foo", "severity": severity}
+		alert := models.Alert{
+			Labels:      labels,
+			StartsAt:    ts.Add(startsAt),
+			State:       models.AlertStateActive,
+			Receiver:    "default",
+			Fingerprint: mapper.AlertFingerprint(labels),
+		}
+		alert.UpdateFingerprints()
+		return models.AlertGroup{
+			Receiver: "default",
+			Labels:   map[string]string{"severity": severity},
+			Alerts:   models.AlertList{alert},
+		}
+	}
+	groups := []models.AlertGroup{
+		newGroup("Critical", time.Minute),
+		newGroup("critical", 0),
+		newGroup("CRIT", time.Minute),
+		newGroup("warning", 0),
+	}
+	mapping := map[string]map[string]string{"severity": {"crit": "critical"}}
+
+	merged := mergeNormalizedLabels(groups, []string{"severity"}, mapping)
+	if len(merged) != 2 {
+		t.Fatalf("Got %d group(s), expected 2", len(merged))
+	}
+	if diff := cmp.Diff(map[string]string{"severity": "critical"}, merged[0].Labels); diff != "" {
+		t.Errorf("Wrong group labels (-want +got):\n%s", diff)
+	}
+	if len(merged[0].Alerts) != 1 {
+		t.Fatalf("Got %d alert(s) in the critical group, expected 1", len(merged[0].Alerts))
+	}
+	alert := merged[0].Alerts[0]
+	if alert.Fingerprint != mapper.AlertFingerprint(map[string]string{"alertname": "Foo", "severity": "critical"}) {
+		t.Errorf("Fingerprint %s doesn't match normalized labels %v", alert.Fingerprint, alert.Labels)
+	}
+	if !alert.StartsAt.Equal(ts) {
+		t.Errorf("Merged alert has startsAt=%s, expected the earliest one %s", alert.StartsAt, ts)
+	}
+	if diff := cmp.Diff(map[string]string{"severity": "warning"}, merged[1].Labels); diff != "" {
+		t.Errorf("Wrong group labels (-want +got):\n%s", diff)
+	}
+
+	config.Config.Labels.Normalize.Lowercase = []string{"severity"}
+	config.Config.Labels.Normalize.Values = mapping
+	defer func() {
+		config.Config.Labels.Normalize.Lowercase = []string{}
+		config.Config.Labels.Normalize.Values = nil
+	}()
+	am, err := NewAlertmanager("normalize", "http://normalize.localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.setAlertGroups(groups)
+	if am.AlertCount() != 2 {
+		t.Errorf("Got %d alert(s) after setAlertGroups(), expected 2", am.AlertCount())
+	}
+	if len(am.Alerts()) != 2 {
+		t.Errorf("Got %d alert group(s) after setAlertGroups(), expected 2", len(am.Alerts()))
+	}
+}
+
+func TestDropAlerts(t *testing.T) {
+	ts := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	newAlert := func(name, severity string, age time.Duration) models.Alert {
+		alert := models.Alert{
+			Labels:   map[string]string{"alertname": name},
+			StartsAt: ts.Add(-age),
+		}
+		if severity != "" {
+			alert.Labels["severity"] = severity
+		}
+		alert.UpdateFingerprints()
+		return alert
+	}
+	groups := []models.AlertGroup{
+		{
+			ID: "1",
+			Alerts: models.AlertList{
+				newAlert("info-old", "info", time.Hour),
+				newAlert("critical-new", "critical", time.Minute),
+				newAlert("none", "", time.Hour*24),
+			},
+		},
+		{
+			ID: "2",
+			Alerts: models.AlertList{
+				newAlert("warning-old", "warning", time.Hour),
+				newAlert("warning-new", "warning", time.Minute),
+				newAlert("warning-new2", "warning", time.Minute),
+			},
+		},
+		{
+			ID: "3",
+			Alerts: models.AlertList{
+				newAlert("info-new", "info", time.Minute),
+			},
+		},
+	}
+	weights := map[string]int{"critical": 10, "warning": 5, "info": 1}
+
+	type testCaseT struct {
+		max     int
+		dropped int
+		alerts  map[string][]string
+	}
+	for _, testCase := range []testCaseT{
+		{
+			max:     0,
+			dropped: 0,
+			alerts: map[string][]string{
+				"1": {"info-old", "critical-new", "none"},
+				"2": {"warning-old", "warning-new", "warning-new2"},
+				"3": {"info-new"},
+			},
+		},
+		{
+			max:     7,
+			dropped: 0,
+			alerts: map[string][]string{
+				"1": {"info-old", "critical-new", "none"},
+				"2": {"warning-old", "warning-new", "warning-new2"},
+				"3": {"info-new"},
+			},
+		},
+		{
+			max:     5,
+			dropped: 2,
+			alerts: map[string][]string{
+				"1": {"info-old", "critical-new"},
+				"2": {"warning-old", "warning-new", "warning-new2"},
+			},
+		},
+		{
+			max:     4,
+			dropped: 3,
+			alerts: map[string][]string{
+				"1": {"critical-new"},
+				"2": {"warning-old", "warning-new", "warning-new2"},
+			},
+		},
+		{
+			max:     2,
+			dropped: 5,
+			alerts: map[string][]string{
+				"1": {"critical-new"},
+				"2": {"warning-old"},
+			},
+		},
+		{
+			max:     1,
+			dropped: 6,
+			alerts: map[string][]string{
+				"1": {"critical-new"},
+			},
+		},
+	} {
+		// the result must not depend on the order of groups
+		for _, order := range [][]int{{0, 1, 2}, {2, 1, 0}} {
+			input := []models.AlertGroup{}
+			for _, i := range order {
+				input = append(input, groups[i])
+			}
+			result, dropped := dropAlerts(input, testCase.max, "severity", weights)
+			if len(dropped) != testCase.dropped {
+				t.Errorf("dropAlerts(max=%d) dropped %d alert(s), expected %d", testCase.max, len(dropped), testCase.dropped)
+			}
+			alerts := map[string][]string{}
+			for _, ag := range result {
+				for _, alert := range ag.Alerts {
+					alerts[ag.ID] = append(alerts[ag.ID], alert.Labels["alertname"])
+				}
+			}
+			if diff := cmp.Diff(testCase.alerts, alerts); diff != "" {
+				t.Errorf("dropAlerts(max=%d) returned wrong alerts (-want +got):\n%s", testCase.max, diff)
+			}
+		}
+	}
+}
+
+func TestSetAlertGroupsMaxTotal(t *testing.T) {
+	config.Config.Alerts.MaxTotal = 1
+	defer func() {
+		config.Config.Alerts.MaxTotal = 0
+	}()
+
+	newAlert := func(name string) models.Alert {
+		alert := models.Alert{Labels: map[string]string{"alertname": name}, State: models.AlertStateActive, Receiver: "default"}
+		alert.UpdateFingerprints()
+		return alert
+	}
+	am, err := NewAlertmanager("maxTotal", "http://maxtotal.localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	am.setAlertGroups([]models.AlertGroup{
+		{
+			Receiver: "default",
+			Labels:   map[string]string{"alertname": "Foo"},
+			Alerts:   models.AlertList{newAlert("Foo"), newAlert("Bar")},
+		},
+	})
+	if am.AlertCount() != 1 {
+		t.Errorf("Got %d alert(s) after setAlertGroups(), expected 1", am.AlertCount())
+	}
+	if len(am.DroppedAlerts()) != 1 {
+		t.Errorf("Got %d dropped alert(s) after setAlertGroups(), expected 1", len(am.DroppedAlerts()))
+	}
+}
+
+func TestDedupSilencesIdenticalMatchers(t *testing.T) {
+	defaultUpstreams := upstreams
+	defer func() {
+		upstreams = defaultUpstreams
+	}()
+
+	now := time.Now()
+	newSilence := func(id string) models.Silence {
+		return models.Silence{
+			ID:        id,
+			Matchers:  []models.SilenceMatcher{{Name: "alertname", Value: "Foo"}},
+			StartsAt:  now.Add(-time.Hour),
+			EndsAt:    now.Add(time.Hour),
+			CreatedBy: "me@example.com",
+			Comment:   "maintenance",
+		}
+	}
+
+	upstreams = map[string]*Alertmanager{}
+	ams := []*Alertmanager{}
+	for _, name := range []string{"am1", "am2"} {
+		am, err := NewAlertmanager(name, fmt.Sprintf("http://%s.localhost", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		am.status = models.AlertmanagerStatus{PeerIDs: []string{"peer1", "peer2"}}
+		upstreams[name] = am
+		ams = append(ams, am)
+	}
+	// silence 1 was gossiped to both members, silence 2 has identical matchers
+	// but it's a different silence that only am1 knows about
+	ams[0].setSilences([]models.Silence{newSilence("1"), newSilence("2")})
+	ams[1].setSilences([]models.Silence{newSilence("1")})
+
+	sources := map[string][]models.SilenceSource{}
+	for _, ms := range DedupSilences() {
+		sources[ms.Silence.ID] = ms.Sources
+	}
+	expected := map[string][]models.SilenceSource{
+		"1": {{Alertmanager: "am1", ID: "1"}, {Alertmanager: "am2", ID: "1"}},
+		"2": {{Alertmanager: "am1", ID: "2"}},
+	}
+	if diff := cmp.Diff(expected, sources); diff != "" {
+		t.Errorf("Wrong silences (-want +got):\n%s", diff)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/prymitive/karma/internal/config"
 	"github.com/prymitive/karma/internal/filters"
 	"github.com/prymitive/karma/internal/mapper"
+	v017 "github.com/prymitive/karma/internal/mapper/v017"
 	"github.com/prymitive/karma/internal/models"
 	"github.com/prymitive/karma/internal/slices"
 	"github.com/prymitive/karma/internal/transform"
@@ -39,6 +40,8 @@ type Alertmanager struct {
 	Name           string        `json:"name"`
 	// whenever this instance should be proxied
 	ProxyRequests bool `json:"proxyRequests"`
+	// instances with higher priority are listed first
+	Priority int `json:"priority"`
 	// reader instances are specific to URI scheme we collect from
 	reader uri.Reader
 	// implements how we fetch requests from the Alertmanager, we don't set it
@@ -54,10 +57,25 @@ type Alertmanager struct {
 	knownLabels  []string
 	lastError    string
 	status       models.AlertmanagerStatus
+	// alerts dropped because of the alerts.maxTotal limit
+	droppedAlerts []string
+	// duration and completion time of the most recent collection cycle
+	lastCollectionDuration  time.Duration
+	lastCollectionTimestamp time.Time
 	// metrics tracked per alertmanager instance
 	Metrics alertmanagerMetrics
 	// headers to send with each AlertManager request
 	HTTPHeaders map[string]string
+	// basic auth credentials with password read from a file
+	basicAuthFile *uri.BasicAuthFile
+	// tracks alert state changes, used to tell which alerts are flapping
+	flapDetector *flapDetector
+	// tracks when each alert was first collected from this instance
+	firstSeen *firstSeenTracker
+	// tracks when each alert was last collected from this instance
+	lastSeen *staleTracker
+	// limits the number of proxied requests, nil if there's no limit
+	rateLimiter *rateLimiter
 }
 
 func (am *Alertmanager) probeVersion() string {
@@ -69,7 +87,7 @@ func (am *Alertmanager) probeVersion() string {
 		return fakeVersion
 	}
 
-	source, err := am.reader.Read(url, am.HTTPHeaders)
+	source, err := am.reader.Read(url, am.requestHeaders())
 	if err != nil {
 		log.Errorf("[%s] %s request failed: %s", am.Name, uri.SanitizeURI(url), err)
 		return fakeVersion
@@ -93,7 +111,7 @@ func (am *Alertmanager) fetchStatus(version string) (*models.AlertmanagerStatus,
 	var status models.AlertmanagerStatus
 
 	if mapper.IsOpenAPI() {
-		status, err = mapper.Collect(am.URI, am.HTTPHeaders, am.RequestTimeout, am.HTTPTransport)
+		status, err = mapper.Collect(am.URI, am.requestHeaders(), am.RequestTimeout, am.HTTPTransport)
 		if err != nil {
 			return nil, err
 		}
@@ -105,7 +123,7 @@ func (am *Alertmanager) fetchStatus(version string) (*models.AlertmanagerStatus,
 			return nil, err
 		}
 		// read raw body from the source
-		source, err := am.reader.Read(url, am.HTTPHeaders)
+		source, err := am.reader.Read(url, am.requestHeaders())
 		if err != nil {
 			log.Errorf("[%s] %s request failed: %s", am.Name, uri.SanitizeURI(url), err)
 			return nil, err
@@ -129,14 +147,26 @@ func (am *Alertmanager) clearData() {
 	am.colors = models.LabelsColorMap{}
 	am.autocomplete = []models.Autocomplete{}
 	am.knownLabels = []string{}
+	am.droppedAlerts = []string{}
 	am.status = models.AlertmanagerStatus{
-		Version: "",
-		ID:      "",
-		PeerIDs: []string{},
+		Version:       "",
+		ID:            "",
+		PeerIDs:       []string{},
+		ClusterStatus: "",
 	}
 	am.lock.Unlock()
 }
 
+// clearDataOnError drops all data after a failed collection cycle, unless
+// alerts.staleTimeout is set, in that case data from the last successful
+// cycle is kept and alerts are only removed once they become stale
+func (am *Alertmanager) clearDataOnError() {
+	if config.Config.Alerts.StaleTimeout > 0 {
+		return
+	}
+	am.clearData()
+}
+
 func (am *Alertmanager) pullSilences(version string) error {
 	mapper, err := mapper.GetSilenceMapper(version)
 	if err != nil {
@@ -147,7 +177,7 @@ func (am *Alertmanager) pullSilences(version string) error {
 
 	start := time.Now()
 	if mapper.IsOpenAPI() {
-		silences, err = mapper.Collect(am.URI, am.HTTPHeaders, am.RequestTimeout, am.HTTPTransport)
+		silences, err = mapper.Collect(am.URI, am.requestHeaders(), am.RequestTimeout, am.HTTPTransport)
 		if err != nil {
 			return err
 		}
@@ -165,7 +195,7 @@ func (am *Alertmanager) pullSilences(version string) error {
 		}
 
 		// read raw body from the source
-		source, err := am.reader.Read(url, am.HTTPHeaders)
+		source, err := am.reader.Read(url, am.requestHeaders())
 		if err != nil {
 			log.Errorf("[%s] %s request failed: %s", am.Name, uri.SanitizeURI(url), err)
 			return err
@@ -180,6 +210,13 @@ func (am *Alertmanager) pullSilences(version string) error {
 	}
 	log.Infof("[%s] Got %d silences(s) in %s", am.Name, len(silences), time.Since(start))
 
+	am.setSilences(silences)
+
+	return nil
+}
+
+// setSilences stores silences collected from this instance
+func (am *Alertmanager) setSilences(silences []models.Silence) {
 	log.Infof("[%s] Detecting JIRA links in silences (%d)", am.Name, len(silences))
 	silenceMap := map[string]models.Silence{}
 	for _, silence := range silences {
@@ -191,8 +228,6 @@ func (am *Alertmanager) pullSilences(version string) error {
 	am.lock.Lock()
 	am.silences = silenceMap
 	am.lock.Unlock()
-
-	return nil
 }
 
 // PublicURI is the URI of this Alertmanager we put in JSON response
@@ -224,7 +259,7 @@ func (am *Alertmanager) pullAlerts(version string) error {
 
 	start := time.Now()
 	if mapper.IsOpenAPI() {
-		groups, err = mapper.Collect(am.URI, am.HTTPHeaders, am.RequestTimeout, am.HTTPTransport)
+		groups, err = mapper.Collect(am.URI, am.requestHeaders(), am.RequestTimeout, am.HTTPTransport)
 		if err != nil {
 			return err
 		}
@@ -244,7 +279,7 @@ func (am *Alertmanager) pullAlerts(version string) error {
 		}
 
 		// read raw body from the source
-		source, err := am.reader.Read(url, am.HTTPHeaders)
+		source, err := am.reader.Read(url, am.requestHeaders())
 		if err != nil {
 			log.Errorf("[%s] %s request failed: %s", am.Name, uri.SanitizeURI(url), err)
 			return err
@@ -259,6 +294,22 @@ func (am *Alertmanager) pullAlerts(version string) error {
 	}
 	log.Infof("[%s] Got %d alert group(s) in %s", am.Name, len(groups), time.Since(start))
 
+	am.setAlertGroups(groups)
+
+	return nil
+}
+
+// setAlertGroups deduplicates and stores alert groups collected from this
+// instance, silences must be already set since alerts will reference them
+func (am *Alertmanager) setAlertGroups(groups []models.AlertGroup) {
+	normalize := config.Config.Labels.Normalize
+	if len(normalize.Lowercase) > 0 || len(normalize.Values) > 0 {
+		groups = mergeNormalizedLabels(groups, normalize.Lowercase, normalize.Values)
+	}
+	if len(config.Config.Dedup.IgnoreLabels) > 0 {
+		groups = mergeIgnoredLabels(groups, config.Config.Dedup.IgnoreLabels)
+	}
+
 	log.Infof("[%s] Deduplicating alert groups (%d)", am.Name, len(groups))
 	uniqueGroups := map[string]models.AlertGroup{}
 	uniqueAlerts := map[string]map[string]models.Alert{}
@@ -287,14 +338,49 @@ func (am *Alertmanager) pullAlerts(version string) error {
 
 	}
 
+	// state changes are always tracked since they are also used by the
+	// @state_changed filter, flapping detection is only done if it's enabled
+	flapping := config.Config.Alertmanager.Flapping
+	states := map[string]string{}
+	for _, alerts := range uniqueAlerts {
+		for _, alert := range alerts {
+			states[alert.LabelsFingerprint()] = alert.State
+		}
+	}
+	am.flapDetector.update(time.Now(), flapping.Window, states)
+
+	fingerprints := []string{}
+	for _, alerts := range uniqueAlerts {
+		for _, alert := range alerts {
+			fingerprints = append(fingerprints, alert.LabelsFingerprint())
+		}
+	}
+	am.firstSeen.update(time.Now(), fingerprints)
+	am.lastSeen.update(time.Now(), fingerprints)
+
+	// lowest priority alerts above the alerts.maxTotal limit are dropped before
+	// they are processed, so they are never stored
+	keptGroups := make([]models.AlertGroup, 0, len(uniqueGroups))
+	for _, ag := range uniqueGroups {
+		for _, alert := range uniqueAlerts[ag.ID] {
+			ag.Alerts = append(ag.Alerts, alert)
+		}
+		keptGroups = append(keptGroups, ag)
+	}
+	severity := config.Config.Grid.Sorting.SeverityScore
+	keptGroups, droppedAlerts := dropAlerts(keptGroups, config.Config.Alerts.MaxTotal, severity.Label, severity.Weights)
+	if len(droppedAlerts) > 0 {
+		log.Warningf("[%s] Dropped %d alert(s) above the alerts.maxTotal limit", am.Name, len(droppedAlerts))
+	}
+
 	dedupedGroups := []models.AlertGroup{}
 	colors := models.LabelsColorMap{}
 	autocompleteMap := map[string]models.Autocomplete{}
 
-	log.Infof("[%s] Processing unique alert groups (%d)", am.Name, len(uniqueGroups))
-	for _, ag := range uniqueGroups {
+	log.Infof("[%s] Processing unique alert groups (%d)", am.Name, len(keptGroups))
+	for _, ag := range keptGroups {
 		alerts := models.AlertList{}
-		for _, alert := range uniqueAlerts[ag.ID] {
+		for _, alert := range ag.Alerts {
 
 			silences := map[string]*models.Silence{}
 			for _, silenceID := range alert.SilencedBy {
@@ -304,6 +390,10 @@ func (am *Alertmanager) pullAlerts(version string) error {
 				}
 			}
 
+			labels := alert.Labels
+			if alert.SourceLabels != nil {
+				labels = alert.SourceLabels
+			}
 			alert.Alertmanager = []models.AlertmanagerInstance{
 				{
 					Name:        am.Name,
@@ -311,6 +401,7 @@ func (am *Alertmanager) pullAlerts(version string) error {
 					State:       alert.State,
 					StartsAt:    alert.StartsAt,
 					Source:      alert.GeneratorURL,
+					Labels:      labels,
 					Silences:    silences,
 					SilencedBy:  alert.SilencedBy,
 					InhibitedBy: alert.InhibitedBy,
@@ -325,6 +416,10 @@ func (am *Alertmanager) pullAlerts(version string) error {
 				transform.ColorLabel(colors, k, v)
 			}
 
+			alert.Flapping = flapping.Window > 0 && am.flapDetector.changeCount(alert.LabelsFingerprint()) > flapping.Threshold
+			alert.KarmaFirstSeen = am.firstSeen.get(alert.LabelsFingerprint())
+			alert.StateChangedAt = am.flapDetector.lastChange(alert.LabelsFingerprint())
+
 			alert.UpdateFingerprints()
 			alerts = append(alerts, alert)
 		}
@@ -358,20 +453,31 @@ func (am *Alertmanager) pullAlerts(version string) error {
 	am.colors = colors
 	am.autocomplete = autocomplete
 	am.knownLabels = knownLabels
+	am.droppedAlerts = droppedAlerts
 	am.lock.Unlock()
-
-	return nil
 }
 
 // Pull data from upstream Alertmanager instance
 func (am *Alertmanager) Pull() error {
 	am.Metrics.Cycles++
 
+	start := time.Now()
+	defer am.setCollectionTime(start)
+	defer func() {
+		if timeout := config.Config.Alerts.StaleTimeout; timeout > 0 {
+			am.evictStaleAlerts(time.Now(), timeout)
+		}
+	}()
+
+	if uri.IsStaticFile(am.URI) {
+		return am.pullStatic()
+	}
+
 	version := am.probeVersion()
 
 	status, err := am.fetchStatus(version)
 	if err != nil {
-		am.clearData()
+		am.clearDataOnError()
 		am.setError(err.Error())
 		am.Metrics.Errors[labelValueErrorsSilences]++
 		return err
@@ -379,7 +485,7 @@ func (am *Alertmanager) Pull() error {
 
 	err = am.pullSilences(version)
 	if err != nil {
-		am.clearData()
+		am.clearDataOnError()
 		am.setError(err.Error())
 		am.Metrics.Errors[labelValueErrorsSilences]++
 		return err
@@ -387,7 +493,7 @@ func (am *Alertmanager) Pull() error {
 
 	err = am.pullAlerts(version)
 	if err != nil {
-		am.clearData()
+		am.clearDataOnError()
 		am.setError(err.Error())
 		am.Metrics.Errors[labelValueErrorsAlerts]++
 		return err
@@ -401,6 +507,40 @@ func (am *Alertmanager) Pull() error {
 	return nil
 }
 
+// pullStatic reads alerts and silences from a static file, it's re-read on
+// every collection cycle so any changes made to it will be picked up
+// There's no connection that could fail so errors are only logged and the
+// data read previously is kept
+func (am *Alertmanager) pullStatic() error {
+	source, err := am.reader.Read(am.URI, nil)
+	if err != nil {
+		am.Metrics.Errors[labelValueErrorsAlerts]++
+		return err
+	}
+	defer source.Close()
+
+	groups, silences, err := v017.DecodeStatic(source)
+	if err != nil {
+		am.Metrics.Errors[labelValueErrorsAlerts]++
+		return fmt.Errorf("failed to decode %s: %s", am.SanitizedURI(), err)
+	}
+	log.Infof("[%s] Got %d alert group(s) and %d silences(s) from a static file", am.Name, len(groups), len(silences))
+
+	am.setSilences(silences)
+	am.setAlertGroups(groups)
+
+	am.lock.Lock()
+	am.status = models.AlertmanagerStatus{
+		Version: v017.StaticVersion,
+		ID:      "",
+		PeerIDs: []string{},
+	}
+	am.lastError = ""
+	am.lock.Unlock()
+
+	return nil
+}
+
 // Alerts returns a copy of all alert groups
 func (am *Alertmanager) Alerts() []models.AlertGroup {
 	am.lock.RLock()
@@ -411,6 +551,19 @@ func (am *Alertmanager) Alerts() []models.AlertGroup {
 	return alerts
 }
 
+// AlertCount returns the number of alerts collected from this instance, alerts
+// are deduplicated per group when pulled so this won't include duplicates
+func (am *Alertmanager) AlertCount() int {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	var count int
+	for _, ag := range am.alertGroups {
+		count += len(ag.Alerts)
+	}
+	return count
+}
+
 // Silences returns a copy of all silences
 func (am *Alertmanager) Silences() map[string]models.Silence {
 	am.lock.RLock()
@@ -460,6 +613,19 @@ func (am *Alertmanager) Autocomplete() []models.Autocomplete {
 	return autocomplete
 }
 
+// DroppedAlerts returns a copy of the list of alerts dropped because of the
+// alerts.maxTotal limit, every alert is identified by the key returned from
+// droppedAlertKey()
+func (am *Alertmanager) DroppedAlerts() []string {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	droppedAlerts := make([]string, len(am.droppedAlerts))
+	copy(droppedAlerts, am.droppedAlerts)
+
+	return droppedAlerts
+}
+
 // KnownLabels returns a copy of a map with known labels
 func (am *Alertmanager) KnownLabels() []string {
 	am.lock.RLock()
@@ -478,6 +644,32 @@ func (am *Alertmanager) setError(err string) {
 	am.lastError = err
 }
 
+func (am *Alertmanager) setCollectionTime(start time.Time) {
+	am.lock.Lock()
+	defer am.lock.Unlock()
+
+	am.lastCollectionTimestamp = time.Now()
+	am.lastCollectionDuration = am.lastCollectionTimestamp.Sub(start)
+}
+
+// LastCollectionDuration returns how long the most recent collection cycle
+// took, it will be zero if no collection was done yet
+func (am *Alertmanager) LastCollectionDuration() time.Duration {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	return am.lastCollectionDuration
+}
+
+// LastCollectionTimestamp returns the time when the most recent collection
+// cycle finished, it will be zero if no collection was done yet
+func (am *Alertmanager) LastCollectionTimestamp() time.Time {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	return am.lastCollectionTimestamp
+}
+
 func (am *Alertmanager) Error() string {
 	am.lock.RLock()
 	defer am.lock.RUnlock()
@@ -485,6 +677,31 @@ func (am *Alertmanager) Error() string {
 	return am.lastError
 }
 
+// requestHeaders returns headers that should be sent with every request to
+// this Alertmanager
+func (am *Alertmanager) requestHeaders() map[string]string {
+	if am.basicAuthFile == nil {
+		return am.HTTPHeaders
+	}
+	headers := map[string]string{}
+	for k, v := range am.HTTPHeaders {
+		headers[k] = v
+	}
+	for k, v := range am.basicAuthFile.Headers() {
+		headers[k] = v
+	}
+	return headers
+}
+
+// BasicAuthHeaders returns Basic Auth headers generated from the password
+// file, if one was configured for this Alertmanager
+func (am *Alertmanager) BasicAuthHeaders() map[string]string {
+	if am.basicAuthFile == nil {
+		return map[string]string{}
+	}
+	return am.basicAuthFile.Headers()
+}
+
 // SanitizedURI returns a copy of Alertmanager.URI with password replaced by
 // "xxx"
 func (am *Alertmanager) SanitizedURI() string {
@@ -502,6 +719,18 @@ func (am *Alertmanager) Version() string {
 	return am.status.Version
 }
 
+// ClusterPeerStatus returns the gossip status of this instance as reported by
+// Alertmanager, "unknown" is returned if the status can't be determined
+func (am *Alertmanager) ClusterPeerStatus() string {
+	am.lock.RLock()
+	defer am.lock.RUnlock()
+
+	if am.status.ClusterStatus == "" {
+		return models.ClusterPeerStatusUnknown
+	}
+	return am.status.ClusterStatus
+}
+
 // ClusterPeers returns a list of IDs of all peers this instance
 // is connected to.
 // IDs are the same as in Alertmanager API.
@@ -539,6 +768,24 @@ func (am *Alertmanager) ClusterMemberNames() []string {
 	return members
 }
 
+// ReadyClusterMembers returns all Alertmanager instances that are in the same
+// cluster as this instance (including self), are ready according to their
+// gossip status and didn't fail the last collection
+func (am *Alertmanager) ReadyClusterMembers() []*Alertmanager {
+	members := []*Alertmanager{}
+	for _, name := range am.ClusterMemberNames() {
+		member := GetAlertmanagerByName(name)
+		if member == nil {
+			continue
+		}
+		if member.ClusterPeerStatus() != models.ClusterPeerStatusReady || member.Error() != "" {
+			continue
+		}
+		members = append(members, member)
+	}
+	return members
+}
+
 // ClusterID returns the ID (sha1) of the cluster this Alertmanager instance
 // belongs to
 func (am *Alertmanager) ClusterID() string {
@@ -0,0 +1,72 @@
+package alertmanager
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket, it holds up to burst tokens and refills them
+// at the rate of tokens per second, every allowed request takes one token
+type rateLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// if false then read-only requests are never limited
+	limitReads bool
+}
+
+func newRateLimiter(rate float64, burst int, limitReads bool) *rateLimiter {
+	return &rateLimiter{
+		rate:       rate,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		limitReads: limitReads,
+	}
+}
+
+// allowAt returns true if there's a token left at given time, taking it
+func (rl *rateLimiter) allowAt(now time.Time) bool {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if !rl.last.IsZero() && now.After(rl.last) {
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+		if rl.tokens > rl.burst {
+			rl.tokens = rl.burst
+		}
+	}
+	if now.After(rl.last) {
+		rl.last = now
+	}
+
+	if rl.tokens < 1 {
+		return false
+	}
+	rl.tokens--
+	return true
+}
+
+func isReadRequest(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// AllowProxyRequest returns true if a proxied request using given HTTP method
+// can be passed to this Alertmanager instance, false is returned when the rate
+// limit for it was exceeded
+func (am *Alertmanager) AllowProxyRequest(method string) bool {
+	if am.rateLimiter == nil {
+		return true
+	}
+	if isReadRequest(method) && !am.rateLimiter.limitReads {
+		return true
+	}
+	return am.rateLimiter.allowAt(time.Now())
+}
@@ -0,0 +1,112 @@
+package alertmanager
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowAt(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(2, 3, false)
+
+	for _, step := range []struct {
+		at      time.Duration
+		allowed bool
+	}{
+		// full bucket
+		{at: 0, allowed: true},
+		{at: 0, allowed: true},
+		{at: 0, allowed: true},
+		{at: 0, allowed: false},
+		// 2 tokens per second, so one is added every 500ms
+		{at: time.Millisecond * 250, allowed: false},
+		{at: time.Millisecond * 500, allowed: true},
+		{at: time.Millisecond * 500, allowed: false},
+		// timestamps going back in time don't add any tokens
+		{at: 0, allowed: false},
+		// bucket is never refilled over the burst size
+		{at: time.Minute, allowed: true},
+		{at: time.Minute, allowed: true},
+		{at: time.Minute, allowed: true},
+		{at: time.Minute, allowed: false},
+	} {
+		if allowed := rl.allowAt(now.Add(step.at)); allowed != step.allowed {
+			t.Errorf("allowAt(+%s) returned %v, expected %v", step.at, allowed, step.allowed)
+		}
+	}
+}
+
+func TestRateLimiterConcurrent(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	rl := newRateLimiter(1, 50, false)
+
+	var allowed int
+	var lock sync.Mutex
+	wg := sync.WaitGroup{}
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.allowAt(now) {
+				lock.Lock()
+				allowed++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("%d request(s) were allowed, expected 50", allowed)
+	}
+}
+
+func TestAllowProxyRequest(t *testing.T) {
+	for _, testCase := range []struct {
+		rate       float64
+		limitReads bool
+		method     string
+		allowed    int
+	}{
+		{rate: 0, method: http.MethodPost, allowed: 10},
+		{rate: 0, method: http.MethodGet, allowed: 10},
+		{rate: 0.001, method: http.MethodPost, allowed: 2},
+		{rate: 0.001, method: http.MethodDelete, allowed: 2},
+		{rate: 0.001, method: http.MethodGet, allowed: 10},
+		{rate: 0.001, method: http.MethodHead, allowed: 10},
+		{rate: 0.001, limitReads: true, method: http.MethodGet, allowed: 2},
+		{rate: 0.001, limitReads: true, method: http.MethodPost, allowed: 2},
+	} {
+		am, err := NewAlertmanager("test", "http://localhost", WithRateLimit(testCase.rate, 2, testCase.limitReads))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var allowed int
+		for i := 0; i < 10; i++ {
+			if am.AllowProxyRequest(testCase.method) {
+				allowed++
+			}
+		}
+		if allowed != testCase.allowed {
+			t.Errorf("[rate=%v reads=%v] %d %s request(s) were allowed, expected %d",
+				testCase.rate, testCase.limitReads, allowed, testCase.method, testCase.allowed)
+		}
+	}
+}
+
+func TestWithRateLimitInvalid(t *testing.T) {
+	for _, testCase := range []struct {
+		rate  float64
+		burst int
+	}{
+		{rate: -1, burst: 1},
+		{rate: 1, burst: 0},
+		{rate: 1, burst: -5},
+	} {
+		if _, err := NewAlertmanager("test", "http://localhost", WithRateLimit(testCase.rate, testCase.burst, false)); err == nil {
+			t.Errorf("[rate=%v burst=%d] NewAlertmanager() didn't return any error", testCase.rate, testCase.burst)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package alertmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/prymitive/karma/internal/mapper"
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/uri"
+)
+
+// silencePayload is the body of a silence create request, it's the same for
+// both v1 and v2 APIs
+type silencePayload struct {
+	Matchers  []models.SilenceMatcher `json:"matchers"`
+	StartsAt  time.Time               `json:"startsAt"`
+	EndsAt    time.Time               `json:"endsAt"`
+	CreatedBy string                  `json:"createdBy"`
+	Comment   string                  `json:"comment"`
+}
+
+// v2 API response
+type silenceCreateOpenAPIResponse struct {
+	SilenceID string `json:"silenceID"`
+}
+
+// v1 API response, silenceId is a number in Alertmanager 0.4 and a string in
+// all later versions
+type silenceCreateResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		SilenceID interface{} `json:"silenceId"`
+	} `json:"data"`
+	Error string `json:"error"`
+}
+
+// isOpenAPI returns true if this instance should be talked to using v2 API
+// uses the same version range as the UI
+func (am *Alertmanager) isOpenAPI() bool {
+	version, err := semver.NewVersion(am.Version())
+	if err != nil {
+		return false
+	}
+	return !version.LessThan(semver.MustParse("0.16.0"))
+}
+
+// CreateSilence sends given silence to the Alertmanager API and returns the ID
+// of the newly created silence
+func (am *Alertmanager) CreateSilence(silence models.Silence) (string, error) {
+	if uri.IsStaticFile(am.URI) {
+		return "", fmt.Errorf("alertmanager upstream '%s' reads from a static file, silences can't be created", am.Name)
+	}
+
+	isOpenAPI := am.isOpenAPI()
+
+	apiPath := "api/v1/silences"
+	if isOpenAPI {
+		apiPath = "api/v2/silences"
+	}
+	url, err := uri.JoinURL(am.URI, apiPath)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(silencePayload{
+		Matchers:  silence.Matchers,
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range am.requestHeaders() {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Transport: am.HTTPTransport, Timeout: am.RequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if isOpenAPI {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("request failed with status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		}
+		r := silenceCreateOpenAPIResponse{}
+		if err = json.Unmarshal(respBody, &r); err != nil {
+			return "", err
+		}
+		return r.SilenceID, nil
+	}
+
+	r := silenceCreateResponse{}
+	if err = json.Unmarshal(respBody, &r); err != nil {
+		return "", fmt.Errorf("request failed with status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if r.Status != mapper.AlertmanagerStatusString {
+		return "", fmt.Errorf("request failed with status code %d: %s", resp.StatusCode, r.Error)
+	}
+	switch id := r.Data.SilenceID.(type) {
+	case string:
+		return id, nil
+	case float64:
+		return fmt.Sprintf("%.0f", id), nil
+	default:
+		return "", fmt.Errorf("unexpected silence ID in response: %v", r.Data.SilenceID)
+	}
+}
@@ -0,0 +1,118 @@
+package alertmanager
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prymitive/karma/internal/models"
+)
+
+type createSilenceTest struct {
+	version  string
+	path     string
+	code     int
+	response string
+	id       string
+	isError  bool
+}
+
+var createSilenceTests = []createSilenceTest{
+	{
+		version:  "0.4.2",
+		path:     "/api/v1/silences",
+		code:     200,
+		response: `{"status":"success","data":{"silenceId":12}}`,
+		id:       "12",
+	},
+	{
+		version:  "0.15.3",
+		path:     "/api/v1/silences",
+		code:     200,
+		response: `{"status":"success","data":{"silenceId":"d0109f6b-0a56-4b0f-9b90-fd4bc2a92b6c"}}`,
+		id:       "d0109f6b-0a56-4b0f-9b90-fd4bc2a92b6c",
+	},
+	{
+		version:  "0.15.3",
+		path:     "/api/v1/silences",
+		code:     400,
+		response: `{"status":"error","errorType":"bad_data","error":"invalid silence"}`,
+		isError:  true,
+	},
+	{
+		version:  "0.15.3",
+		path:     "/api/v1/silences",
+		code:     500,
+		response: `Internal Server Error`,
+		isError:  true,
+	},
+	{
+		version:  "0.16.0",
+		path:     "/api/v2/silences",
+		code:     200,
+		response: `{"silenceID":"9e9e8f0e-8c2d-4c1a-b1a5-4bba7b4e2a65"}`,
+		id:       "9e9e8f0e-8c2d-4c1a-b1a5-4bba7b4e2a65",
+	},
+	{
+		version:  "0.19.0",
+		path:     "/api/v2/silences",
+		code:     400,
+		response: `"silence invalid: comment missing"`,
+		isError:  true,
+	},
+	{
+		// unknown version, v1 API is used
+		version:  "",
+		path:     "/api/v1/silences",
+		code:     200,
+		response: `{"status":"success","data":{"silenceId":"abc"}}`,
+		id:       "abc",
+	},
+}
+
+func TestCreateSilence(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	silence := models.Silence{
+		Matchers:  []models.SilenceMatcher{{Name: "alertname", Value: "Fake Alert"}},
+		StartsAt:  time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:    time.Date(2019, 1, 1, 1, 0, 0, 0, time.UTC),
+		CreatedBy: "me@example.com",
+		Comment:   "bulk silence",
+	}
+
+	for _, testCase := range createSilenceTests {
+		testCase := testCase // scopelint pin
+		am, err := NewAlertmanager("test", "http://localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		am.status.Version = testCase.version
+
+		httpmock.Reset()
+		httpmock.RegisterResponder("POST", "http://localhost"+testCase.path, func(req *http.Request) (*http.Response, error) {
+			payload := silencePayload{}
+			if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+				t.Errorf("[%s] Failed to decode request body: %s", testCase.version, err)
+			}
+			if payload.CreatedBy != silence.CreatedBy || payload.Comment != silence.Comment || len(payload.Matchers) != 1 {
+				t.Errorf("[%s] Invalid payload: %v", testCase.version, payload)
+			}
+			return httpmock.NewStringResponse(testCase.code, testCase.response), nil
+		})
+
+		id, err := am.CreateSilence(silence)
+		if testCase.isError && err == nil {
+			t.Errorf("[%s] CreateSilence() didn't return any error, response: %s", testCase.version, testCase.response)
+		}
+		if !testCase.isError && err != nil {
+			t.Errorf("[%s] CreateSilence() returned an error: %s", testCase.version, err)
+		}
+		if id != testCase.id {
+			t.Errorf("[%s] CreateSilence() returned ID '%s', expected '%s'", testCase.version, id, testCase.id)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package alertmanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// staleTracker records when each alert was last returned by a successful
+// collection cycle, it's used to evict alerts that are kept in memory after
+// their upstream stopped reporting them
+// Alerts are tracked using labels fingerprint
+type staleTracker struct {
+	lock     sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+func newStaleTracker() *staleTracker {
+	return &staleTracker{lastSeen: map[string]time.Time{}}
+}
+
+// update marks all alerts from the most recent collection cycle as seen and
+// forgets all other alerts, those are no longer stored
+func (st *staleTracker) update(now time.Time, fingerprints []string) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	lastSeen := make(map[string]time.Time, len(fingerprints))
+	for _, fp := range fingerprints {
+		lastSeen[fp] = now
+	}
+	st.lastSeen = lastSeen
+}
+
+// isStale returns true if given alert wasn't seen for longer than timeout,
+// unknown alerts are never stale
+func (st *staleTracker) isStale(fp string, now time.Time, timeout time.Duration) bool {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	ts, found := st.lastSeen[fp]
+	return found && now.Sub(ts) > timeout
+}
+
+// evictStaleAlerts removes all stored alerts that were not seen for longer
+// than timeout, groups left without any alert are removed too
+// It returns the number of evicted alerts
+func (am *Alertmanager) evictStaleAlerts(now time.Time, timeout time.Duration) int {
+	am.lock.Lock()
+	defer am.lock.Unlock()
+
+	var evicted int
+	groups := []models.AlertGroup{}
+	for _, ag := range am.alertGroups {
+		alerts := models.AlertList{}
+		for _, alert := range ag.Alerts {
+			if am.lastSeen.isStale(alert.LabelsFingerprint(), now, timeout) {
+				evicted++
+				continue
+			}
+			alerts = append(alerts, alert)
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+		if len(alerts) != len(ag.Alerts) {
+			ag.Alerts = alerts
+			ag.Hash = ag.ContentFingerprint()
+		}
+		groups = append(groups, ag)
+	}
+
+	if evicted > 0 {
+		log.Infof("[%s] Evicted %d stale alert(s)", am.Name, evicted)
+		am.alertGroups = groups
+	}
+	return evicted
+}
@@ -0,0 +1,160 @@
+package alertmanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/mock"
+
+	"github.com/jarcoal/httpmock"
+)
+
+func TestStaleTracker(t *testing.T) {
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	st := newStaleTracker()
+
+	if st.isStale("a", now, time.Minute) {
+		t.Errorf("isStale() returned true for unknown alert")
+	}
+
+	st.update(now, []string{"a", "b"})
+	if st.isStale("a", now.Add(time.Minute), time.Minute) {
+		t.Errorf("isStale(a) returned true before timeout passed")
+	}
+	if !st.isStale("a", now.Add(time.Minute*2), time.Minute) {
+		t.Errorf("isStale(a) returned false after timeout passed")
+	}
+
+	// alert reported again resets the timer
+	st.update(now.Add(time.Minute*2), []string{"a"})
+	if st.isStale("a", now.Add(time.Minute*3), time.Minute) {
+		t.Errorf("isStale(a) returned true after alert was reported again")
+	}
+	if st.isStale("b", now.Add(time.Minute*3), time.Minute) {
+		t.Errorf("isStale(b) returned true for alert that is no longer stored")
+	}
+}
+
+func TestEvictStaleAlerts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sample, err := ioutil.ReadFile(mock.GetAbsoluteMockPath("static.json", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "alerts.json")
+	err = ioutil.WriteFile(filename, sample, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	am, err := NewAlertmanager("static", "file://"+filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = am.Pull(); err != nil {
+		t.Fatalf("Pull() returned an error: %s", err)
+	}
+	pulledAt := time.Now()
+
+	// alerts are no longer reported, old data is kept until they get stale
+	os.Remove(filename)
+	if err = am.Pull(); err == nil {
+		t.Errorf("Pull() didn't return any error for a missing file")
+	}
+	if am.AlertCount() != 24 {
+		t.Errorf("Got %d alert(s) after failed pull, expected %d", am.AlertCount(), 24)
+	}
+
+	if evicted := am.evictStaleAlerts(pulledAt.Add(-time.Minute), time.Minute); evicted != 0 {
+		t.Errorf("evictStaleAlerts() evicted %d alert(s) before timeout passed", evicted)
+	}
+	if evicted := am.evictStaleAlerts(pulledAt.Add(time.Minute*2), time.Minute); evicted != 24 {
+		t.Errorf("evictStaleAlerts() evicted %d alert(s), expected %d", evicted, 24)
+	}
+	if am.AlertCount() != 0 {
+		t.Errorf("Got %d alert(s) after eviction, expected 0", am.AlertCount())
+	}
+	if len(am.Alerts()) != 0 {
+		t.Errorf("Got %d alert group(s) after eviction, expected 0", len(am.Alerts()))
+	}
+
+	// eviction runs after every pull once the timeout is configured
+	config.Config.Alerts.StaleTimeout = time.Millisecond * 100
+	defer func() {
+		config.Config.Alerts.StaleTimeout = 0
+	}()
+	err = ioutil.WriteFile(filename, sample, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = am.Pull(); err != nil {
+		t.Fatalf("Pull() returned an error: %s", err)
+	}
+	if am.AlertCount() != 24 {
+		t.Errorf("Got %d alert(s) after pull with staleTimeout set, expected %d", am.AlertCount(), 24)
+	}
+	os.Remove(filename)
+	time.Sleep(time.Millisecond * 200)
+	if err = am.Pull(); err == nil {
+		t.Errorf("Pull() didn't return any error for a missing file")
+	}
+	if am.AlertCount() != 0 {
+		t.Errorf("Got %d alert(s) after failed pull with staleTimeout set, expected 0", am.AlertCount())
+	}
+}
+
+func TestEvictStaleAlertsAfterFailedHTTPPull(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	config.Config.Alerts.StaleTimeout = time.Millisecond * 100
+	defer func() {
+		config.Config.Alerts.StaleTimeout = 0
+	}()
+
+	uri := "http://localhost/stale"
+	am, err := NewAlertmanager("stale", uri, WithRequestTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.RegisterURL(uri+"/metrics", "0.19.0", "metrics")
+	mock.RegisterURL(uri+"/api/v2/status", "0.19.0", "api/v2/status")
+	mock.RegisterURL(uri+"/api/v2/silences", "0.19.0", "api/v2/silences")
+	mock.RegisterURL(uri+"/api/v2/alerts/groups", "0.19.0", "api/v2/alerts/groups")
+	if err = am.Pull(); err != nil {
+		t.Fatalf("Pull() returned an error: %s", err)
+	}
+	if am.AlertCount() != 24 {
+		t.Errorf("Got %d alert(s) after pull, expected %d", am.AlertCount(), 24)
+	}
+
+	// upstream is down, old alerts are kept until they get stale
+	httpmock.Reset()
+	if err = am.Pull(); err == nil {
+		t.Errorf("Pull() didn't return any error for a failing upstream")
+	}
+	if am.Error() == "" {
+		t.Errorf("Got empty error string after failed pull")
+	}
+	if am.AlertCount() != 24 {
+		t.Errorf("Got %d alert(s) after failed pull, expected %d", am.AlertCount(), 24)
+	}
+
+	time.Sleep(time.Millisecond * 200)
+	if err = am.Pull(); err == nil {
+		t.Errorf("Pull() didn't return any error for a failing upstream")
+	}
+	if am.AlertCount() != 0 {
+		t.Errorf("Got %d alert(s) after failed pull with stale alerts, expected 0", am.AlertCount())
+	}
+}
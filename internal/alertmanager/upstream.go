@@ -34,6 +34,9 @@ func NewAlertmanager(name, upstreamURI string, opts ...Option) (*Alertmanager, e
 		autocomplete:   []models.Autocomplete{},
 		knownLabels:    []string{},
 		HTTPHeaders:    map[string]string{},
+		flapDetector:   newFlapDetector(),
+		firstSeen:      newFirstSeenTracker(),
+		lastSeen:       newStaleTracker(),
 		Metrics: alertmanagerMetrics{
 			Errors: map[string]float64{
 				labelValueErrorsAlerts:   0,
@@ -109,10 +112,13 @@ func WithProxy(proxied bool) Option {
 }
 
 // WithRequestTimeout option can be passed to NewAlertmanager in order to set
-// a custom timeout for Alertmanager upstream requests
+// a custom timeout for Alertmanager upstream requests, zero or negative value
+// will keep the default timeout
 func WithRequestTimeout(timeout time.Duration) Option {
 	return func(am *Alertmanager) error {
-		am.RequestTimeout = timeout
+		if timeout > 0 {
+			am.RequestTimeout = timeout
+		}
 		return nil
 	}
 }
@@ -135,6 +141,48 @@ func WithHTTPTransport(httpTransport http.RoundTripper) Option {
 	}
 }
 
+// WithBasicAuthFile option can be passed to NewAlertmanager in order to use
+// Basic Auth with the password read from a file, file changes are picked up
+// on the next request
+func WithBasicAuthFile(username, path string) Option {
+	return func(am *Alertmanager) error {
+		if path != "" {
+			am.basicAuthFile = uri.NewBasicAuthFile(username, path)
+		}
+		return nil
+	}
+}
+
+// WithPriority option can be passed to NewAlertmanager in order to set
+// the priority used when listing Alertmanager instances
+func WithPriority(priority int) Option {
+	return func(am *Alertmanager) error {
+		am.Priority = priority
+		return nil
+	}
+}
+
+// WithRateLimit option allows to limit the number of requests per second
+// proxied to this Alertmanager instance, burst is the number of requests that
+// can be passed at once, rate set to 0 disables rate limiting, read-only
+// requests are only limited if limitReads is true
+func WithRateLimit(rate float64, burst int, limitReads bool) Option {
+	return func(am *Alertmanager) error {
+		if rate < 0 {
+			return fmt.Errorf("invalid rate limit %v, it must be >= 0", rate)
+		}
+		if rate == 0 {
+			am.rateLimiter = nil
+			return nil
+		}
+		if burst < 1 {
+			return fmt.Errorf("invalid rate limit burst %d, it must be >= 1", burst)
+		}
+		am.rateLimiter = newRateLimiter(rate, burst, limitReads)
+		return nil
+	}
+}
+
 // WithExternalURI option allows to set custom ExternalURI on our instance
 func WithExternalURI(uri string) Option {
 	return func(am *Alertmanager) error {
@@ -3,16 +3,19 @@ package config
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/prymitive/karma/internal/slices"
 	"github.com/prymitive/karma/internal/uri"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"golang.org/x/text/language"
 
 	log "github.com/sirupsen/logrus"
 	yaml "gopkg.in/yaml.v2"
@@ -21,11 +24,29 @@ import (
 var (
 	// Config will hold final configuration read from the file and flags
 	Config configSchema
+
+	metricLabelNameRe = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+	// SortOrders lists all sort orders that can be set as the default in
+	// grid.sorting.order or in a view, "annotation" sort order can only be
+	// requested by the UI since it also needs the annotation name
+	SortOrders = []string{"disabled", "startsAt", "firstSeen", "oldestStartsAt", "label", "alertCount", "severityScore"}
 )
 
 func init() {
+	pflag.Duration("acknowledgement.ttl", time.Hour,
+		"How long alert acknowledgements are kept before they expire")
+
 	pflag.Duration("alertmanager.interval", time.Minute,
 		"Interval for fetching data from Alertmanager servers")
+	pflag.Int("alertmanager.jitter", 0,
+		"Randomize the interval between pulls by up to this percentage of it, 0 disables jitter")
+	pflag.Int("alertmanager.minHealthy", 1,
+		"Minimal number of healthy Alertmanager servers required for karma to report as ready")
+	pflag.Duration("alertmanager.flapping.window", time.Minute*10,
+		"Time window used to count alert state changes when detecting flapping alerts, 0 disables flapping detection")
+	pflag.Int("alertmanager.flapping.threshold", 3,
+		"Alerts that changed state more than this number of times within the window are flapping")
 	pflag.String("alertmanager.name", "default",
 		"Name for the Alertmanager server (only used with simplified config)")
 	pflag.String("alertmanager.uri", "",
@@ -37,6 +58,13 @@ func init() {
 	pflag.Bool("alertmanager.proxy", false,
 		"Proxy all client requests to Alertmanager via karma (only used with simplified config)")
 
+	pflag.Duration("alerts.staleTimeout", 0,
+		"Evict alerts that were not collected for longer than this duration, 0 disables eviction")
+	pflag.StringSlice("alerts.metrics.labels", []string{"alertname"},
+		"List of alert labels used as labels of firing alert metrics")
+	pflag.Int("alerts.maxTotal", 0,
+		"Maximum number of unique alerts kept after deduplication, lowest priority alerts are dropped, 0 disables the limit")
+
 	pflag.Bool(
 		"annotations.default.hidden", false,
 		"Hide all annotations by default unless explicitly listed in the 'visible' list")
@@ -55,7 +83,14 @@ func init() {
 
 	pflag.Bool("debug", false, "Enable debug mode")
 
+	pflag.StringSlice("dedup.ignoreLabels", []string{},
+		"List of labels ignored when deduplicating alerts, alerts that differ only by those labels are merged")
+
 	pflag.StringSlice("filters.default", []string{}, "List of default filters")
+	pflag.String("filters.timezone", "UTC", "Timezone used by filters matching on the time of day, like @started_between")
+	pflag.Int("filters.maxPerRequest", 100, "Maximum number of filters allowed in a single request")
+	pflag.String("filters.severity.label", "severity", "Label used by the @severity_at_least filter")
+	pflag.StringSlice("filters.severity.order", []string{"info", "warning", "critical"}, "List of severity values ranked from the least to the most severe, used by the @severity_at_least filter")
 
 	pflag.StringSlice("labels.color.static", []string{},
 		"List of label names that should have the same (but distinct) color")
@@ -64,10 +99,19 @@ func init() {
 	pflag.StringSlice("labels.keep", []string{},
 		"List of labels to keep, all other labels will be stripped")
 	pflag.StringSlice("labels.strip", []string{}, "List of labels to ignore")
+	pflag.StringSlice("labels.normalize.lowercase", []string{},
+		"List of labels with values that will be converted to lower case when collecting alerts")
+	pflag.Int("labelStats.maxValuesPerName", 0,
+		"Maximum number of values per label name returned in label stats, all other values will be grouped together, set to 0 to return all values")
 
+	pflag.Bool("grid.showResolved", true, "Show resolved alerts on the alert grid")
 	pflag.String("grid.sorting.order", "startsAt", "Default sort order for alert grid")
 	pflag.Bool("grid.sorting.reverse", true, "Reverse sort order")
 	pflag.String("grid.sorting.label", "alertname", "Label name to use when sorting alert grid by label")
+	pflag.String("grid.sorting.secondaryLabel", "", "Label name to use when sorting alert grid by label and primary label values are equal")
+	pflag.String("grid.sorting.collation", "natural", "Collation used when comparing label values, 'natural' or a language tag like 'en'")
+	pflag.String("grid.sorting.severityScore.label", "severity", "Label name used to calculate the severity score of alert groups")
+	pflag.String("grid.sorting.severityScore.mode", "max", "How alert weights are combined into the severity score of alert groups, 'max' or 'sum'")
 
 	pflag.Bool("log.config", true, "Log used configuration to log on startup")
 	pflag.String("log.level", "info",
@@ -75,6 +119,17 @@ func init() {
 	pflag.String("log.format", "text",
 		"Log format, one of: text, json")
 
+	pflag.StringSlice("privacy.labels.strip", []string{},
+		"List of labels to remove from API responses")
+	pflag.StringSlice("privacy.labels.redact", []string{},
+		"List of labels with values replaced by a placeholder in API responses")
+	pflag.StringSlice("privacy.annotations.strip", []string{},
+		"List of annotations to remove from API responses")
+	pflag.StringSlice("privacy.annotations.redact", []string{},
+		"List of annotations with values replaced by a placeholder in API responses")
+	pflag.String("privacy.placeholder", "[redacted]", "Value used for redacted labels and annotations")
+	pflag.Bool("privacy.stripCounters", false, "Also remove stripped and redacted label values from label counters")
+
 	pflag.StringSlice("receivers.keep", []string{},
 		"List of receivers to keep, all alerts with different receivers will be ignored")
 	pflag.StringSlice("receivers.strip", []string{},
@@ -87,9 +142,14 @@ func init() {
 	pflag.String("listen.address", "", "IP/Hostname to listen on")
 	pflag.Int("listen.port", 8080, "HTTP port to listen on")
 	pflag.String("listen.prefix", "/", "URL prefix")
+	pflag.Duration("listen.requestTimeout", time.Second*30,
+		"Timeout for filtering and sorting alerts when handling a single request, set to 0 to disable")
 
 	pflag.String("sentry.public", "", "Sentry DSN for Go exceptions")
 	pflag.String("sentry.private", "", "Sentry DSN for JavaScript exceptions")
+
+	pflag.String("webhook.uri", "", "URI to send a POST request to when new alerts are collected")
+	pflag.Duration("webhook.timeout", time.Second*10, "Timeout for webhook requests")
 }
 
 // ReadConfig will read all sources of configuration, merge all keys and
@@ -141,8 +201,16 @@ func (config *configSchema) Read() {
 		log.Fatal(err)
 	}
 
+	config.Acknowledgement.TTL = v.GetDuration("acknowledgement.ttl")
 	config.Alertmanager.Servers = []alertmanagerConfig{}
 	config.Alertmanager.Interval = v.GetDuration("alertmanager.interval")
+	config.Alertmanager.Jitter = v.GetInt("alertmanager.jitter")
+	config.Alertmanager.MinHealthy = v.GetInt("alertmanager.minHealthy")
+	config.Alertmanager.Flapping.Window = v.GetDuration("alertmanager.flapping.window")
+	config.Alertmanager.Flapping.Threshold = v.GetInt("alertmanager.flapping.threshold")
+	config.Alerts.StaleTimeout = v.GetDuration("alerts.staleTimeout")
+	config.Alerts.Metrics.Labels = v.GetStringSlice("alerts.metrics.labels")
+	config.Alerts.MaxTotal = v.GetInt("alerts.maxTotal")
 	config.Annotations.Default.Hidden = v.GetBool("annotations.default.hidden")
 	config.Annotations.Hidden = v.GetStringSlice("annotations.hidden")
 	config.Annotations.Visible = v.GetStringSlice("annotations.visible")
@@ -151,21 +219,40 @@ func (config *configSchema) Read() {
 	config.Custom.CSS = v.GetString("custom.css")
 	config.Custom.JS = v.GetString("custom.js")
 	config.Debug = v.GetBool("debug")
+	config.Dedup.IgnoreLabels = v.GetStringSlice("dedup.ignoreLabels")
 	config.Filters.Default = v.GetStringSlice("filters.default")
+	config.Filters.Timezone = v.GetString("filters.timezone")
+	config.Filters.MaxPerRequest = v.GetInt("filters.maxPerRequest")
+	config.Filters.Severity.Label = v.GetString("filters.severity.label")
+	config.Filters.Severity.Order = v.GetStringSlice("filters.severity.order")
+	config.Grid.ShowResolved = v.GetBool("grid.showResolved")
 	config.Grid.Sorting.Order = v.GetString("grid.sorting.order")
 	config.Grid.Sorting.Reverse = v.GetBool("grid.sorting.reverse")
 	config.Grid.Sorting.Label = v.GetString("grid.sorting.label")
+	config.Grid.Sorting.SecondaryLabel = v.GetString("grid.sorting.secondaryLabel")
+	config.Grid.Sorting.Collation = v.GetString("grid.sorting.collation")
+	config.Grid.Sorting.SeverityScore.Label = v.GetString("grid.sorting.severityScore.label")
+	config.Grid.Sorting.SeverityScore.Mode = v.GetString("grid.sorting.severityScore.mode")
 	config.Labels.Color.Custom = CustomLabelColors{}
 	config.Labels.Color.Static = v.GetStringSlice("labels.color.static")
 	config.Labels.Color.Unique = v.GetStringSlice("labels.color.unique")
 	config.Labels.Keep = v.GetStringSlice("labels.keep")
 	config.Labels.Strip = v.GetStringSlice("labels.strip")
+	config.LabelStats.MaxValuesPerName = v.GetInt("labelStats.maxValuesPerName")
+	config.Labels.Normalize.Lowercase = v.GetStringSlice("labels.normalize.lowercase")
 	config.Listen.Address = v.GetString("listen.address")
 	config.Listen.Port = v.GetInt("listen.port")
 	config.Listen.Prefix = v.GetString("listen.prefix")
+	config.Listen.RequestTimeout = v.GetDuration("listen.requestTimeout")
 	config.Log.Config = v.GetBool("log.config")
 	config.Log.Level = v.GetString("log.level")
 	config.Log.Format = v.GetString("log.format")
+	config.Privacy.Labels.Strip = v.GetStringSlice("privacy.labels.strip")
+	config.Privacy.Labels.Redact = v.GetStringSlice("privacy.labels.redact")
+	config.Privacy.Annotations.Strip = v.GetStringSlice("privacy.annotations.strip")
+	config.Privacy.Annotations.Redact = v.GetStringSlice("privacy.annotations.redact")
+	config.Privacy.Placeholder = v.GetString("privacy.placeholder")
+	config.Privacy.StripCounters = v.GetBool("privacy.stripCounters")
 	config.Receivers.Keep = v.GetStringSlice("receivers.keep")
 	config.Receivers.Strip = v.GetStringSlice("receivers.strip")
 	config.Sentry.Private = v.GetString("sentry.private")
@@ -173,6 +260,8 @@ func (config *configSchema) Read() {
 	config.SilenceForm.Strip.Labels = v.GetStringSlice("silenceform.strip.labels")
 	config.SilenceForm.Author.PopulateFromHeader.Header = v.GetString("silenceform.author.populate_from_header.header")
 	config.SilenceForm.Author.PopulateFromHeader.ValueRegex = v.GetString("silenceform.author.populate_from_header.value_re")
+	config.Webhook.URI = v.GetString("webhook.uri")
+	config.Webhook.Timeout = v.GetDuration("webhook.timeout")
 
 	if config.SilenceForm.Author.PopulateFromHeader.ValueRegex != "" {
 		_, err = regexp.Compile(config.SilenceForm.Author.PopulateFromHeader.ValueRegex)
@@ -186,7 +275,8 @@ func (config *configSchema) Read() {
 		log.Fatal(err)
 	}
 	for i, s := range config.Alertmanager.Servers {
-		if s.Timeout.Seconds() == 0 {
+		// servers without a timeout set will use the global default
+		if s.Timeout <= 0 {
 			config.Alertmanager.Servers[i].Timeout = v.GetDuration("alertmanager.timeout")
 		}
 	}
@@ -196,6 +286,51 @@ func (config *configSchema) Read() {
 		log.Fatal(err)
 	}
 
+	config.Views = []ViewConfig{}
+	err = v.UnmarshalKey("views", &config.Views)
+	if err != nil {
+		log.Fatal(err)
+	}
+	viewNames := map[string]bool{}
+	for _, view := range config.Views {
+		if view.Name == "" {
+			log.Fatal("View with an empty name")
+		}
+		if viewNames[view.Name] {
+			log.Fatalf("Duplicated view name '%s'", view.Name)
+		}
+		viewNames[view.Name] = true
+		if view.Sorting.Order != "" && !slices.StringInSlice(SortOrders, view.Sorting.Order) {
+			log.Fatalf("Invalid sorting.order value '%s' for view '%s', allowed options: %s", view.Sorting.Order, view.Name, strings.Join(SortOrders, ", "))
+		}
+	}
+
+	config.Annotations.Rules = []AnnotationRule{}
+	err = v.UnmarshalKey("annotations.rules", &config.Annotations.Rules)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, rule := range config.Annotations.Rules {
+		if rule.NameRegex == "" && rule.ValueRegex == "" {
+			log.Fatalf("Annotation rule %d is missing 'name_re' or 'value_re'", i)
+		}
+		if rule.Visible == nil && rule.IsLink == nil {
+			log.Fatalf("Annotation rule %d is missing 'visible' or 'isLink'", i)
+		}
+		if rule.NameRegex != "" {
+			config.Annotations.Rules[i].CompiledNameRegex, err = regexp.Compile(rule.NameRegex)
+			if err != nil {
+				log.Fatalf("Failed to parse annotation rule name regex '%s': %s", rule.NameRegex, err)
+			}
+		}
+		if rule.ValueRegex != "" {
+			config.Annotations.Rules[i].CompiledValueRegex, err = regexp.Compile(rule.ValueRegex)
+			if err != nil {
+				log.Fatalf("Failed to parse annotation rule value regex '%s': %s", rule.ValueRegex, err)
+			}
+		}
+	}
+
 	err = v.UnmarshalKey("labels.color.custom", &config.Labels.Color.Custom)
 	if err != nil {
 		log.Fatal(err)
@@ -214,13 +349,141 @@ func (config *configSchema) Read() {
 		}
 	}
 
+	err = v.UnmarshalKey("labels.normalize.values", &config.Labels.Normalize.Values)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	err = v.UnmarshalKey("grid.sorting.customValues.labels", &config.Grid.Sorting.CustomValues.Labels)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if !slices.StringInSlice([]string{"disabled", "startsAt", "label"}, config.Grid.Sorting.Order) {
-		log.Fatalf("Invalid grid.sorting.order value '%s', allowed options: disabled, startsAt, label", config.Grid.Sorting.Order)
+	err = v.UnmarshalKey("grid.sorting.customValues.regex", &config.Grid.Sorting.CustomValues.Regex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = v.UnmarshalKey("grid.sorting.customValues.order", &config.Grid.Sorting.CustomValues.Order)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = v.UnmarshalKey("grid.sorting.severityScore.weights", &config.Grid.Sorting.SeverityScore.Weights)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = v.UnmarshalKey("webhook.headers", &config.Webhook.Headers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if config.LabelStats.MaxValuesPerName < 0 {
+		log.Fatalf("Invalid labelStats.maxValuesPerName value '%d', it must be >= 0", config.LabelStats.MaxValuesPerName)
+	}
+
+	if config.Acknowledgement.TTL <= 0 {
+		log.Fatalf("Invalid acknowledgement.ttl value '%s', it must be > 0", config.Acknowledgement.TTL)
+	}
+
+	if config.Alertmanager.Jitter < 0 || config.Alertmanager.Jitter >= 100 {
+		log.Fatalf("Invalid alertmanager.jitter value '%d', it must be >= 0 and < 100", config.Alertmanager.Jitter)
+	}
+
+	if config.Webhook.Timeout <= 0 {
+		log.Fatalf("Invalid webhook.timeout value '%s', it must be > 0", config.Webhook.Timeout)
+	}
+
+	if config.Alerts.StaleTimeout < 0 {
+		log.Fatalf("Invalid alerts.staleTimeout value '%s', it must be >= 0", config.Alerts.StaleTimeout)
+	}
+
+	if config.Listen.RequestTimeout < 0 {
+		log.Fatalf("Invalid listen.requestTimeout value '%s', it must be >= 0", config.Listen.RequestTimeout)
+	}
+
+	if config.Alerts.MaxTotal < 0 {
+		log.Fatalf("Invalid alerts.maxTotal value '%d', it must be >= 0", config.Alerts.MaxTotal)
+	}
+
+	metricLabels := map[string]bool{}
+	for _, name := range config.Alerts.Metrics.Labels {
+		if !metricLabelNameRe.MatchString(name) || strings.HasPrefix(name, "__") {
+			log.Fatalf("Invalid alerts.metrics.labels value '%s', it must be a valid Prometheus label name", name)
+		}
+		if metricLabels[name] {
+			log.Fatalf("Invalid alerts.metrics.labels value '%s', it's listed more than once", name)
+		}
+		metricLabels[name] = true
+	}
+
+	if config.Alertmanager.MinHealthy < 0 {
+		log.Fatalf("Invalid alertmanager.minHealthy value '%d', it must be >= 0", config.Alertmanager.MinHealthy)
+	}
+
+	if config.Alertmanager.Flapping.Window < 0 {
+		log.Fatalf("Invalid alertmanager.flapping.window value '%s', it must be >= 0", config.Alertmanager.Flapping.Window)
+	}
+
+	if config.Alertmanager.Flapping.Threshold < 0 {
+		log.Fatalf("Invalid alertmanager.flapping.threshold value '%d', it must be >= 0", config.Alertmanager.Flapping.Threshold)
+	}
+
+	if !slices.StringInSlice(SortOrders, config.Grid.Sorting.Order) {
+		log.Fatalf("Invalid grid.sorting.order value '%s', allowed options: %s", config.Grid.Sorting.Order, strings.Join(SortOrders, ", "))
+	}
+
+	if !slices.StringInSlice([]string{"max", "sum"}, config.Grid.Sorting.SeverityScore.Mode) {
+		log.Fatalf("Invalid grid.sorting.severityScore.mode value '%s', allowed options: max, sum", config.Grid.Sorting.SeverityScore.Mode)
+	}
+
+	if config.Filters.MaxPerRequest <= 0 {
+		log.Fatalf("Invalid filters.maxPerRequest value '%d', it must be > 0", config.Filters.MaxPerRequest)
+	}
+
+	if _, err = time.LoadLocation(config.Filters.Timezone); err != nil {
+		log.Fatalf("Invalid filters.timezone value '%s': %s", config.Filters.Timezone, err)
+	}
+
+	if config.Grid.Sorting.Collation != "natural" {
+		if _, err = language.Parse(config.Grid.Sorting.Collation); err != nil {
+			log.Fatalf("Invalid grid.sorting.collation value '%s': %s", config.Grid.Sorting.Collation, err)
+		}
+	}
+
+	config.Silences.Templates = []SilenceTemplate{}
+	err = v.UnmarshalKey("silences.templates", &config.Silences.Templates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	templateNames := map[string]bool{}
+	for i, tmpl := range config.Silences.Templates {
+		if tmpl.Name == "" {
+			log.Fatal("Silence template with an empty name")
+		}
+		if templateNames[tmpl.Name] {
+			log.Fatalf("Duplicated silence template name '%s'", tmpl.Name)
+		}
+		templateNames[tmpl.Name] = true
+		if len(tmpl.Matchers) == 0 {
+			log.Fatalf("Silence template '%s' has no matchers", tmpl.Name)
+		}
+		for j, matcher := range tmpl.Matchers {
+			if matcher.Name == "" || matcher.Value == "" {
+				log.Fatalf("Silence template '%s' matcher %d is missing 'name' or 'value'", tmpl.Name, j)
+			}
+			config.Silences.Templates[i].Matchers[j].CompiledValue, err = parseSilenceTemplate(matcher.Name, matcher.Value)
+			if err != nil {
+				log.Fatalf("Failed to parse silence template '%s' matcher '%s': %s", tmpl.Name, matcher.Name, err)
+			}
+		}
+		if tmpl.Comment != "" {
+			config.Silences.Templates[i].CompiledComment, err = parseSilenceTemplate("comment", tmpl.Comment)
+			if err != nil {
+				log.Fatalf("Failed to parse silence template '%s' comment: %s", tmpl.Name, err)
+			}
+		}
 	}
 
 	// FIXME workaround  for https://github.com/prymitive/karma/issues/507
@@ -242,6 +505,22 @@ func (config *configSchema) Read() {
 		}
 
 		config.Grid.Sorting.CustomValues.Labels = raw.Grid.Sorting.CustomValues.Labels
+		config.Grid.Sorting.CustomValues.Regex = raw.Grid.Sorting.CustomValues.Regex
+		config.Grid.Sorting.CustomValues.Order = raw.Grid.Sorting.CustomValues.Order
+		config.Grid.Sorting.SeverityScore.Weights = raw.Grid.Sorting.SeverityScore.Weights
+		config.Labels.Normalize.Values = raw.Labels.Normalize.Values
+	}
+
+	for labelName, rules := range config.Grid.Sorting.CustomValues.Regex {
+		for i, rule := range rules {
+			if rule.ValueRegex == "" {
+				log.Fatalf("Custom sorting value rule for '%s' is missing 'value_re'", labelName)
+			}
+			config.Grid.Sorting.CustomValues.Regex[labelName][i].CompiledRegex, err = regexp.Compile(rule.ValueRegex)
+			if err != nil {
+				log.Fatalf("Failed to parse custom sorting value regex rule '%s' for '%s' label: %s", rule.ValueRegex, labelName, err)
+			}
+		}
 	}
 
 	// accept single Alertmanager server from flag/env if nothing is set yet
@@ -260,6 +539,20 @@ func (config *configSchema) Read() {
 	}
 }
 
+// parseSilenceTemplate parses a single silence template, only builtin template
+// functions are available and call is disabled, so templates can't run any
+// code, referencing a missing label is an error when rendering
+func parseSilenceTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"call": func(...interface{}) (string, error) {
+				return "", errors.New("call is not allowed in silence templates")
+			},
+		}).
+		Parse(text)
+}
+
 // LogValues will dump runtime config to logs
 func (config *configSchema) LogValues() {
 	// make a copy of our config so we can edit it
@@ -276,6 +569,7 @@ func (config *configSchema) LogValues() {
 			TLS:         s.TLS,
 			Proxy:       s.Proxy,
 			Headers:     s.Headers,
+			RateLimit:   s.RateLimit,
 		}
 		servers = append(servers, server)
 	}
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -17,7 +19,10 @@ import (
 // config from previous test run
 func resetEnv() {
 	karmaEnvVariables := []string{
+		"ALERTS_MAXTOTAL",
+		"ALERTS_METRICS_LABELS",
 		"ALERTMANAGER_INTERVAL",
+		"ALERTMANAGER_MINHEALTHY",
 		"ALERTMANAGER_URI",
 		"ALERTMANAGER_EXTERNAL_URI",
 		"ALERTMANAGER_NAME",
@@ -34,9 +39,11 @@ func resetEnv() {
 		"LABELS_COLOR_UNIQUE",
 		"LABELS_KEEP",
 		"LABELS_STRIP",
+		"LABELSTATS_MAXVALUESPERNAME",
 		"LISTEN_ADDRESS",
 		"LISTEN_PORT",
 		"LISTEN_PREFIX",
+		"LISTEN_REQUESTTIMEOUT",
 		"LOG_CONFIG",
 		"LOG_LEVEL",
 		"RECEIVERS_KEEP",
@@ -54,8 +61,15 @@ func resetEnv() {
 }
 
 func testReadConfig(t *testing.T) {
-	expectedConfig := `alertmanager:
+	expectedConfig := `acknowledgement:
+  ttl: 1h0m0s
+alertmanager:
   interval: 1s
+  jitter: 0
+  minHealthy: 1
+  flapping:
+    window: 10m0s
+    threshold: 3
   servers:
   - name: default
     uri: http://localhost
@@ -68,6 +82,20 @@ func testReadConfig(t *testing.T) {
       key: ""
       insecureSkipVerify: false
     headers: {}
+    basicAuth:
+      username: ""
+      passwordFile: ""
+    priority: 0
+    rateLimit:
+      rate: 0
+      burst: 0
+      reads: false
+alerts:
+  staleTimeout: 0s
+  metrics:
+    labels:
+    - alertname
+  maxTotal: 0
 annotations:
   default:
     hidden: true
@@ -76,21 +104,41 @@ annotations:
   - summary
   keep: []
   strip: []
+  rules: []
 custom:
   css: /custom.css
   js: /custom.js
 debug: true
+dedup:
+  ignoreLabels: []
 filters:
   default:
   - '@state=active'
   - foo=bar
+  timezone: UTC
+  maxPerRequest: 100
+  severity:
+    label: severity
+    order:
+    - info
+    - warning
+    - critical
 grid:
+  showResolved: true
   sorting:
     order: startsAt
     reverse: true
     label: alertname
+    secondaryLabel: ""
+    collation: natural
     customValues:
       labels: {}
+      regex: {}
+      order: {}
+    severityScore:
+      label: severity
+      mode: max
+      weights: {}
 labels:
   keep:
   - foo
@@ -107,15 +155,30 @@ labels:
     unique:
     - f
     - gg
+  normalize:
+    lowercase: []
+    values: {}
+labelStats:
+  maxValuesPerName: 0
 listen:
   address: 0.0.0.0
   port: 80
   prefix: /
+  requestTimeout: 30s
 log:
   config: true
   level: info
   format: text
 jira: []
+privacy:
+  labels:
+    strip: []
+    redact: []
+  annotations:
+    strip: []
+    redact: []
+  placeholder: '[redacted]'
+  stripCounters: false
 receivers:
   keep: []
   strip: []
@@ -129,6 +192,13 @@ silenceForm:
       value_re: ""
   strip:
     labels: []
+silences:
+  templates: []
+views: []
+webhook:
+  uri: ""
+  headers: {}
+  timeout: 10s
 `
 
 	configDump, err := yaml.Marshal(Config)
@@ -201,6 +271,173 @@ func TestReadSimpleConfig(t *testing.T) {
 	}
 }
 
+func TestServerTimeoutFallback(t *testing.T) {
+	resetEnv()
+	log.SetLevel(log.ErrorLevel)
+
+	dir, err := ioutil.TempDir("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "karma.yaml")
+	err = ioutil.WriteFile(configFile, []byte(`alertmanager:
+  servers:
+    - name: fast
+      uri: http://fast.example.com
+    - name: slow
+      uri: http://slow.example.com
+      timeout: 2m
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("CONFIG_FILE", configFile)
+	os.Setenv("ALERTMANAGER_TIMEOUT", "15s")
+	defer os.Unsetenv("CONFIG_FILE")
+	defer os.Unsetenv("ALERTMANAGER_TIMEOUT")
+	Config.Read()
+
+	expected := map[string]time.Duration{
+		"fast": time.Second * 15,
+		"slow": time.Minute * 2,
+	}
+	if len(Config.Alertmanager.Servers) != len(expected) {
+		t.Fatalf("Expected %d Alertmanager servers, got %d", len(expected), len(Config.Alertmanager.Servers))
+	}
+	for _, am := range Config.Alertmanager.Servers {
+		if am.Timeout != expected[am.Name] {
+			t.Errorf("Expect Alertmanager '%s' timeout '%v' got '%v'", am.Name, expected[am.Name], am.Timeout)
+		}
+	}
+}
+
+func TestSilenceTemplates(t *testing.T) {
+	type testCaseT struct {
+		config  string
+		isFatal bool
+	}
+	for _, testCase := range []testCaseT{
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+        - name: alertname
+          value: "{{ .Labels.alertname }}.*"
+          isRegex: true
+      comment: "{{ .Comment }} ({{ .Labels.instance }})"
+`,
+		},
+		{
+			config: `silences:
+  templates:
+    - matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+`,
+			isFatal: true,
+		},
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+`,
+			isFatal: true,
+		},
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers: []
+`,
+			isFatal: true,
+		},
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+`,
+			isFatal: true,
+		},
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance"
+`,
+			isFatal: true,
+		},
+		{
+			config: `silences:
+  templates:
+    - name: host
+      matchers:
+        - name: instance
+          value: "{{ .Labels.instance }}"
+      comment: "{{ .Labels.instance | nosuchfunc }}"
+`,
+			isFatal: true,
+		},
+	} {
+		resetEnv()
+		log.SetLevel(log.PanicLevel)
+		var wasFatal bool
+		log.StandardLogger().ExitFunc = func(int) { wasFatal = true }
+
+		dir, err := ioutil.TempDir("", "karma")
+		if err != nil {
+			t.Fatal(err)
+		}
+		configFile := filepath.Join(dir, "karma.yaml")
+		err = ioutil.WriteFile(configFile, []byte(testCase.config), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("CONFIG_FILE", configFile)
+		Config.Read()
+		os.Unsetenv("CONFIG_FILE")
+		os.RemoveAll(dir)
+		log.StandardLogger().ExitFunc = nil
+
+		if wasFatal != testCase.isFatal {
+			t.Errorf("Config.Read() fatal=%v, expected %v for config:\n%s", wasFatal, testCase.isFatal, testCase.config)
+			continue
+		}
+		if testCase.isFatal {
+			continue
+		}
+		if len(Config.Silences.Templates) != 1 {
+			t.Fatalf("Got %d silence template(s), expected 1", len(Config.Silences.Templates))
+		}
+		tmpl := Config.Silences.Templates[0]
+		if tmpl.CompiledComment == nil || !tmpl.Matchers[1].IsRegex {
+			t.Errorf("Silence template wasn't parsed correctly: %+v", tmpl)
+		}
+		for _, m := range tmpl.Matchers {
+			if m.CompiledValue == nil {
+				t.Errorf("Silence template matcher '%s' wasn't compiled", m.Name)
+			}
+		}
+	}
+	resetEnv()
+}
+
 type urlSecretTest struct {
 	raw       string
 	sanitized string
@@ -244,6 +481,25 @@ func TestLogValues(t *testing.T) {
 	Config.LogValues()
 }
 
+func TestInvalidAlertMetricsLabel(t *testing.T) {
+	for _, name := range []string{"1foo", "foo-bar", "__name__", "alertname alertname"} {
+		resetEnv()
+		os.Setenv("ALERTS_METRICS_LABELS", name)
+
+		log.SetLevel(log.PanicLevel)
+		var wasFatal bool
+		log.StandardLogger().ExitFunc = func(int) { wasFatal = true }
+
+		Config.Read()
+		log.StandardLogger().ExitFunc = nil
+
+		if !wasFatal {
+			t.Errorf("Invalid alerts.metrics.labels value '%s' didn't cause log.Fatal()", name)
+		}
+	}
+	resetEnv()
+}
+
 func TestInvalidSilenceFormRegex(t *testing.T) {
 	resetEnv()
 	os.Setenv("SILENCEFORM_AUTHOR_POPULATE_FROM_HEADER_VALUE_RE", ".****")
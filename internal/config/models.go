@@ -2,6 +2,7 @@ package config
 
 import (
 	"regexp"
+	"text/template"
 	"time"
 )
 
@@ -17,7 +18,17 @@ type alertmanagerConfig struct {
 		Key                string
 		InsecureSkipVerify bool `yaml:"insecureSkipVerify"  mapstructure:"insecureSkipVerify"`
 	}
-	Headers map[string]string
+	Headers   map[string]string
+	BasicAuth struct {
+		Username     string
+		PasswordFile string `yaml:"passwordFile" mapstructure:"passwordFile"`
+	} `yaml:"basicAuth" mapstructure:"basicAuth"`
+	Priority  int
+	RateLimit struct {
+		Rate  float64
+		Burst int
+		Reads bool
+	} `yaml:"rateLimit" mapstructure:"rateLimit"`
 }
 
 type jiraRule struct {
@@ -34,10 +45,75 @@ type CustomLabelColor struct {
 
 type CustomLabelColors map[string][]CustomLabelColor
 
+// ViewConfig is a named set of grid defaults that can be selected using
+// view=name query argument
+type ViewConfig struct {
+	Name    string
+	Filters []string
+	Sorting struct {
+		Order   string
+		Reverse bool
+		Label   string
+	}
+}
+
+type CustomLabelValueRule struct {
+	ValueRegex    string         `yaml:"value_re" mapstructure:"value_re"`
+	CompiledRegex *regexp.Regexp `yaml:"-" mapstructure:"-"`
+	Replacement   string         `yaml:"replacement" mapstructure:"replacement"`
+}
+
+type CustomLabelValueRules map[string][]CustomLabelValueRule
+
+// AnnotationRule overrides visible and isLink attributes of all annotations
+// with name and value matching given regexes
+type AnnotationRule struct {
+	NameRegex          string         `yaml:"name_re" mapstructure:"name_re"`
+	ValueRegex         string         `yaml:"value_re" mapstructure:"value_re"`
+	CompiledNameRegex  *regexp.Regexp `yaml:"-" mapstructure:"-"`
+	CompiledValueRegex *regexp.Regexp `yaml:"-" mapstructure:"-"`
+	Visible            *bool          `yaml:"visible" mapstructure:"visible"`
+	IsLink             *bool          `yaml:"isLink" mapstructure:"isLink"`
+}
+
+// SilenceTemplateMatcher is a single matcher of a silence template, the value
+// is a template rendered using labels of matched alerts
+type SilenceTemplateMatcher struct {
+	Name          string             `yaml:"name" mapstructure:"name"`
+	Value         string             `yaml:"value" mapstructure:"value"`
+	IsRegex       bool               `yaml:"isRegex" mapstructure:"isRegex"`
+	CompiledValue *template.Template `yaml:"-" mapstructure:"-"`
+}
+
+// SilenceTemplate is a named set of matchers and a comment used by the bulk
+// silence endpoint to render silences for matched alerts
+type SilenceTemplate struct {
+	Name            string                   `yaml:"name" mapstructure:"name"`
+	Matchers        []SilenceTemplateMatcher `yaml:"matchers" mapstructure:"matchers"`
+	Comment         string                   `yaml:"comment" mapstructure:"comment"`
+	CompiledComment *template.Template       `yaml:"-" mapstructure:"-"`
+}
+
 type configSchema struct {
+	Acknowledgement struct {
+		TTL time.Duration
+	}
 	Alertmanager struct {
-		Interval time.Duration
-		Servers  []alertmanagerConfig
+		Interval   time.Duration
+		Jitter     int
+		MinHealthy int `yaml:"minHealthy" mapstructure:"minHealthy"`
+		Flapping   struct {
+			Window    time.Duration
+			Threshold int
+		}
+		Servers []alertmanagerConfig
+	}
+	Alerts struct {
+		StaleTimeout time.Duration `yaml:"staleTimeout" mapstructure:"staleTimeout"`
+		Metrics      struct {
+			Labels []string
+		}
+		MaxTotal int `yaml:"maxTotal" mapstructure:"maxTotal"`
 	}
 	Annotations struct {
 		Default struct {
@@ -47,23 +123,43 @@ type configSchema struct {
 		Visible []string
 		Keep    []string
 		Strip   []string
+		Rules   []AnnotationRule
 	}
 	Custom struct {
 		CSS string
 		JS  string
 	}
-	Debug   bool
+	Debug bool
+	Dedup struct {
+		IgnoreLabels []string `yaml:"ignoreLabels" mapstructure:"ignoreLabels"`
+	}
 	Filters struct {
-		Default []string
+		Default       []string
+		Timezone      string
+		MaxPerRequest int `yaml:"maxPerRequest" mapstructure:"maxPerRequest"`
+		Severity      struct {
+			Label string
+			Order []string
+		}
 	}
 	Grid struct {
-		Sorting struct {
-			Order        string
-			Reverse      bool
-			Label        string
-			CustomValues struct {
+		ShowResolved bool `yaml:"showResolved" mapstructure:"showResolved"`
+		Sorting      struct {
+			Order          string
+			Reverse        bool
+			Label          string
+			SecondaryLabel string `yaml:"secondaryLabel" mapstructure:"secondaryLabel"`
+			Collation      string
+			CustomValues   struct {
 				Labels map[string]map[string]string
+				Regex  CustomLabelValueRules
+				Order  map[string][]string
 			} `yaml:"customValues" mapstructure:"customValues"`
+			SeverityScore struct {
+				Label   string
+				Mode    string
+				Weights map[string]int
+			} `yaml:"severityScore" mapstructure:"severityScore"`
 		}
 	}
 	Labels struct {
@@ -74,18 +170,38 @@ type configSchema struct {
 			Static []string
 			Unique []string
 		}
+		Normalize struct {
+			Lowercase []string
+			Values    map[string]map[string]string
+		}
 	}
+	LabelStats struct {
+		MaxValuesPerName int `yaml:"maxValuesPerName" mapstructure:"maxValuesPerName"`
+	} `yaml:"labelStats" mapstructure:"labelStats"`
 	Listen struct {
-		Address string
-		Port    int
-		Prefix  string
+		Address        string
+		Port           int
+		Prefix         string
+		RequestTimeout time.Duration `yaml:"requestTimeout" mapstructure:"requestTimeout"`
 	}
 	Log struct {
 		Config bool
 		Level  string
 		Format string
 	}
-	JIRA      []jiraRule
+	JIRA    []jiraRule
+	Privacy struct {
+		Labels struct {
+			Strip  []string
+			Redact []string
+		}
+		Annotations struct {
+			Strip  []string
+			Redact []string
+		}
+		Placeholder   string
+		StripCounters bool `yaml:"stripCounters" mapstructure:"stripCounters"`
+	}
 	Receivers struct {
 		Keep  []string
 		Strip []string
@@ -105,4 +221,13 @@ type configSchema struct {
 			Labels []string
 		}
 	} `yaml:"silenceForm"  mapstructure:"silenceForm"`
+	Silences struct {
+		Templates []SilenceTemplate
+	}
+	Views   []ViewConfig
+	Webhook struct {
+		URI     string
+		Headers map[string]string
+		Timeout time.Duration
+	}
 }
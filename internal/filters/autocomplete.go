@@ -16,11 +16,22 @@ func makeAC(value string, tokens []string) models.Autocomplete {
 }
 
 // BuildAutocomplete takes an alert object and generates list of autocomplete
-// strings for it
+// strings for it, filters that need deduplicated alerts are skipped
 func BuildAutocomplete(alerts []models.Alert) []models.Autocomplete {
+	return buildAutocomplete(alerts, false)
+}
+
+// BuildDeduplicatedAutocomplete generates autocomplete strings only for
+// filters that match on data merged from all upstreams, alerts passed to it
+// must be already deduplicated
+func BuildDeduplicatedAutocomplete(alerts []models.Alert) []models.Autocomplete {
+	return buildAutocomplete(alerts, true)
+}
+
+func buildAutocomplete(alerts []models.Alert, deduplicated bool) []models.Autocomplete {
 	acHints := map[string]models.Autocomplete{}
 	for _, filterConfig := range AllFilters {
-		if filterConfig.Autocomplete != nil {
+		if filterConfig.Autocomplete != nil && filterConfig.Deduplicated == deduplicated {
 			for _, hint := range filterConfig.Autocomplete(filterConfig.Label, filterConfig.SupportedOperators, alerts) {
 				acHints[hint.Value] = hint
 			}
@@ -24,8 +24,18 @@ var acTests = []acTest{
 			"@age\u003c10m",
 			"@age\u003c1h",
 			"@age\u003e10m",
+			"@first_seen_age\u003c10m",
+			"@first_seen_age\u003c1h",
+			"@first_seen_age\u003e10m",
+			"@first_seen_age\u003e1h",
+			"@state_changed\u003c10m",
+			"@state_changed\u003c1h",
+			"@state_changed\u003e10m",
+			"@state_changed\u003e1h",
 			"@limit=10",
 			"@limit=50",
+			"@started_between!=09:00-17:00",
+			"@started_between=09:00-17:00",
 		},
 	},
 	{
@@ -69,12 +79,56 @@ var acTests = []acTest{
 			"@age\u003c1h",
 			"@age\u003e10m",
 			"@age\u003e1h",
+			"@first_seen_age\u003c10m",
+			"@first_seen_age\u003c1h",
+			"@first_seen_age\u003e10m",
+			"@first_seen_age\u003e1h",
+			"@state_changed\u003c10m",
+			"@state_changed\u003c1h",
+			"@state_changed\u003e10m",
+			"@state_changed\u003e1h",
 			"@alertmanager!=am1",
 			"@alertmanager!=am2",
 			"@alertmanager=am1",
 			"@alertmanager=am2",
+			"@flapping!=false",
+			"@flapping!=true",
+			"@flapping=false",
+			"@flapping=true",
+			"@acked!=false",
+			"@acked!=true",
+			"@acked=false",
+			"@acked=true",
+			"@cluster_disagreement!=false",
+			"@cluster_disagreement!=true",
+			"@cluster_disagreement=false",
+			"@cluster_disagreement=true",
+			"@inconsistent!=false",
+			"@inconsistent!=true",
+			"@inconsistent=false",
+			"@inconsistent=true",
+			"@inhibited!=false",
+			"@inhibited!=true",
+			"@inhibited=false",
+			"@inhibited=true",
+			"@label_count!=2",
+			"@label_count\u003c2",
+			"@label_count\u003c=2",
+			"@label_count=2",
+			"@label_count\u003e2",
+			"@label_count\u003e=2",
+			"@label_missing!=foo",
+			"@label_missing!=number",
+			"@label_missing=foo",
+			"@label_missing=number",
 			"@limit=10",
 			"@limit=50",
+			"@link_count!=0",
+			"@link_count\u003c0",
+			"@link_count\u003c=0",
+			"@link_count=0",
+			"@link_count\u003e0",
+			"@link_count\u003e=0",
 			"@receiver!=default",
 			"@receiver!=not default",
 			"@receiver!~default",
@@ -87,12 +141,18 @@ var acTests = []acTest{
 			"@silence_author!~me@example.com",
 			"@silence_author=me@example.com",
 			"@silence_author=~me@example.com",
+			"@silence_ends_in\u003c10m",
+			"@silence_ends_in\u003c1h",
+			"@silence_ends_in\u003e10m",
+			"@silence_ends_in\u003e1h",
 			"@silence_id!=1234567890",
 			"@silence_id=1234567890",
 			"@silence_jira!=JIRA-1",
 			"@silence_jira!~JIRA-1",
 			"@silence_jira=JIRA-1",
 			"@silence_jira=~JIRA-1",
+			"@started_between!=09:00-17:00",
+			"@started_between=09:00-17:00",
 			"@state!=active",
 			"@state!=suppressed",
 			"@state=active",
@@ -146,3 +206,69 @@ func TestBuildAutocomplete(t *testing.T) {
 		}
 	}
 }
+
+var deduplicatedACTests = []acTest{
+	{
+		Alerts:   []models.Alert{},
+		Expected: []string{},
+	},
+	{
+		Alerts: []models.Alert{
+			{
+				Labels: map[string]string{"foo": "bar"},
+				Alertmanager: []models.AlertmanagerInstance{
+					{Name: "am1", Cluster: "ha"},
+					{Name: "am2", Cluster: "ha"},
+					{Name: "am3", Cluster: "am3"},
+				},
+				Sources: 3,
+			},
+			{
+				Labels: map[string]string{"foo": "baz"},
+				Alertmanager: []models.AlertmanagerInstance{
+					{Name: "am1", Cluster: "ha"},
+				},
+				Sources: 1,
+			},
+		},
+		Expected: []string{
+			"@cluster_count!=1",
+			"@cluster_count!=2",
+			"@cluster_count<1",
+			"@cluster_count<2",
+			"@cluster_count<=1",
+			"@cluster_count<=2",
+			"@cluster_count=1",
+			"@cluster_count=2",
+			"@cluster_count>1",
+			"@cluster_count>2",
+			"@cluster_count>=1",
+			"@cluster_count>=2",
+			"@sources!=3",
+			"@sources<3",
+			"@sources<=3",
+			"@sources=3",
+			"@sources>3",
+			"@sources>=3",
+		},
+	},
+}
+
+func TestBuildDeduplicatedAutocomplete(t *testing.T) {
+	for _, acTest := range deduplicatedACTests {
+		result := []string{}
+		for _, hint := range filters.BuildDeduplicatedAutocomplete(acTest.Alerts) {
+			result = append(result, hint.Value)
+		}
+
+		sort.Strings(result)
+		sort.Strings(acTest.Expected)
+
+		resultJSON, _ := json.Marshal(result)
+		expectedJSON, _ := json.Marshal(acTest.Expected)
+
+		if string(resultJSON) != string(expectedJSON) {
+			t.Errorf("Autocomplete mismatch, expected %s, got %s", expectedJSON, resultJSON)
+		}
+	}
+}
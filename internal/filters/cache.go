@@ -0,0 +1,110 @@
+package filters
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+)
+
+// filterCacheSize is the maximum number of parsed filters we keep in the cache
+const filterCacheSize = 1024
+
+// parsedFilters stores filters parsed from raw expressions, so that polling
+// clients sending the same set of filters don't need to parse them again
+// Filters that read config values when parsed are not stored
+var parsedFilters = newFilterCache(filterCacheSize)
+
+type filterCacheEntry struct {
+	expression string
+	filter     FilterT
+}
+
+// filterCache is a LRU cache of parsed filters keyed by the raw expression
+// Stored filters are never matched against alerts, callers must use a copy
+// returned by cloneFilter since filters track hits per request
+type filterCache struct {
+	lock    sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newFilterCache(size int) *filterCache {
+	return &filterCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (fc *filterCache) get(expression string) (FilterT, bool) {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	if e, found := fc.entries[expression]; found {
+		fc.order.MoveToFront(e)
+		return e.Value.(*filterCacheEntry).filter, true
+	}
+	return nil, false
+}
+
+func (fc *filterCache) add(expression string, filter FilterT) {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	if e, found := fc.entries[expression]; found {
+		fc.order.MoveToFront(e)
+		e.Value.(*filterCacheEntry).filter = filter
+		return
+	}
+
+	fc.entries[expression] = fc.order.PushFront(&filterCacheEntry{expression: expression, filter: filter})
+	for fc.order.Len() > fc.size {
+		oldest := fc.order.Back()
+		fc.order.Remove(oldest)
+		delete(fc.entries, oldest.Value.(*filterCacheEntry).expression)
+	}
+}
+
+func (fc *filterCache) len() int {
+	fc.lock.Lock()
+	defer fc.lock.Unlock()
+
+	return fc.order.Len()
+}
+
+// isCacheable returns false for filters that depend on config values when
+// parsed, like @severity_at_least, those need to be parsed every time since
+// the config might have changed since they were cached
+func isCacheable(filter FilterT) bool {
+	switch f := filter.(type) {
+	case *orFilter:
+		for _, alternative := range f.Alternatives {
+			if !isCacheable(alternative) {
+				return false
+			}
+		}
+		return true
+	case *severityAtLeastFilter:
+		return false
+	default:
+		return true
+	}
+}
+
+// cloneFilter returns a copy of the filter that can be matched without
+// modifying the original one, alternatives of OR filters are copied too
+func cloneFilter(filter FilterT) FilterT {
+	if f, ok := filter.(*orFilter); ok {
+		c := *f
+		c.Alternatives = make([]FilterT, 0, len(f.Alternatives))
+		for _, alternative := range f.Alternatives {
+			c.Alternatives = append(c.Alternatives, cloneFilter(alternative))
+		}
+		return &c
+	}
+	v := reflect.ValueOf(filter).Elem()
+	c := reflect.New(v.Type())
+	c.Elem().Set(v)
+	return c.Interface().(FilterT)
+}
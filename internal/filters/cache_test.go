@@ -0,0 +1,165 @@
+package filters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+)
+
+func TestFilterCache(t *testing.T) {
+	fc := newFilterCache(2)
+	fc.add("a=1", parseFilter("a=1"))
+	fc.add("b=2", parseFilter("b=2"))
+	if _, found := fc.get("a=1"); !found {
+		t.Error("a=1 not found in the cache")
+	}
+	// b=2 is now the least recently used entry so it should be evicted
+	fc.add("c=3", parseFilter("c=3"))
+	if fc.len() != 2 {
+		t.Errorf("Cache has %d entries, expected 2", fc.len())
+	}
+	if _, found := fc.get("b=2"); found {
+		t.Error("b=2 wasn't evicted from the cache")
+	}
+	for _, expression := range []string{"a=1", "c=3"} {
+		f, found := fc.get(expression)
+		if !found {
+			t.Errorf("%s not found in the cache", expression)
+			continue
+		}
+		if f.GetRawText() != expression {
+			t.Errorf("Cache returned filter '%s' for '%s'", f.GetRawText(), expression)
+		}
+	}
+}
+
+func TestNewFilterCached(t *testing.T) {
+	alert := models.Alert{Labels: map[string]string{"foo": "bar"}}
+	for _, expression := range []string{
+		"foo=bar",
+		"foo!=bar",
+		"foo=~ba",
+		"foo=bar OR foo=baz",
+		"@limit=1",
+		"bar",
+		"foo=",
+		"foo=(",
+	} {
+		uncached := parseFilter(expression)
+		first := NewFilter(expression)
+		second := NewFilter(expression)
+
+		if first == second {
+			t.Errorf("[%s] NewFilter() returned the same instance twice", expression)
+		}
+		for _, f := range []FilterT{first, second} {
+			if f.GetRawText() != uncached.GetRawText() ||
+				f.GetIsValid() != uncached.GetIsValid() ||
+				f.GetInvalidReason() != uncached.GetInvalidReason() ||
+				f.GetName() != uncached.GetName() ||
+				f.GetMatcher() != uncached.GetMatcher() ||
+				f.GetValue() != uncached.GetValue() {
+				t.Errorf("[%s] Cached filter %#v doesn't match parsed one %#v", expression, f, uncached)
+			}
+		}
+
+		if !first.GetIsValid() {
+			continue
+		}
+		// hits must not be shared between copies
+		for i := 0; i < 3; i++ {
+			first.Match(&alert, 5)
+			uncached.Match(&alert, 5)
+		}
+		if first.GetHits() != uncached.GetHits() {
+			t.Errorf("[%s] Cached filter got %d hits, expected %d", expression, first.GetHits(), uncached.GetHits())
+		}
+		if second.GetHits() != 0 {
+			t.Errorf("[%s] Second copy got %d hits, expected 0", expression, second.GetHits())
+		}
+		if third := NewFilter(expression); third.GetHits() != 0 {
+			t.Errorf("[%s] New copy got %d hits, expected 0", expression, third.GetHits())
+		}
+	}
+}
+
+func TestNewFilterCachedOrAlternatives(t *testing.T) {
+	alert := models.Alert{Labels: map[string]string{"foo": "bar"}}
+	first := NewFilter("foo=bar OR foo=baz").(*orFilter)
+	first.Match(&alert, 0)
+	second := NewFilter("foo=bar OR foo=baz").(*orFilter)
+	if hits := first.Alternatives[0].GetHits(); hits != 1 {
+		t.Errorf("First copy alternative got %d hits, expected 1", hits)
+	}
+	if hits := second.Alternatives[0].GetHits(); hits != 0 {
+		t.Errorf("Second copy alternative got %d hits, expected 0", hits)
+	}
+}
+
+func TestNewFilterConfigDependent(t *testing.T) {
+	defaultOrder := config.Config.Filters.Severity.Order
+	defer func() { config.Config.Filters.Severity.Order = defaultOrder }()
+
+	for _, expression := range []string{
+		"@severity_at_least=critical",
+		"foo=bar OR @severity_at_least=critical",
+	} {
+		config.Config.Filters.Severity.Order = []string{"warning"}
+		if f := NewFilter(expression); f.GetIsValid() {
+			t.Errorf("[%s] Filter is valid with an unknown severity", expression)
+		}
+		if _, found := parsedFilters.get(expression); found {
+			t.Errorf("[%s] Config dependent filter was cached", expression)
+		}
+
+		// filter must be parsed again once config changes
+		config.Config.Filters.Severity.Order = []string{"warning", "critical"}
+		if f := NewFilter(expression); !f.GetIsValid() {
+			t.Errorf("[%s] Filter is invalid after config change: %s", expression, f.GetInvalidReason())
+		}
+	}
+}
+
+var benchmarkExpressions = []string{
+	"@state=active",
+	"alertname=~Disk",
+	"cluster=prod OR cluster=staging",
+	"@age>10m",
+	"@limit=50",
+	"fuzzy",
+}
+
+func BenchmarkParseFilter(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, expression := range benchmarkExpressions {
+			parseFilter(expression)
+		}
+	}
+}
+
+func BenchmarkNewFilterCached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, expression := range benchmarkExpressions {
+			NewFilter(expression)
+		}
+	}
+}
+
+func BenchmarkFilterCacheEviction(b *testing.B) {
+	b.ReportAllocs()
+	fc := newFilterCache(16)
+	expressions := make([]string, 64)
+	for i := range expressions {
+		expressions[i] = fmt.Sprintf("foo=%d", i)
+	}
+	for i := 0; i < b.N; i++ {
+		expression := expressions[i%len(expressions)]
+		if _, found := fc.get(expression); !found {
+			fc.add(expression, parseFilter(expression))
+		}
+	}
+}
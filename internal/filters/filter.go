@@ -3,6 +3,7 @@ package filters
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/prymitive/karma/internal/models"
 	"github.com/prymitive/karma/internal/slices"
@@ -15,6 +16,7 @@ type FilterT interface {
 	GetRawText() string
 	GetHits() int
 	GetIsValid() bool
+	GetInvalidReason() string
 	GetName() string
 	GetMatcher() string
 	GetValue() string
@@ -28,6 +30,8 @@ type alertFilter struct {
 	Value   interface{}
 	IsValid bool
 	Hits    int
+	// human readable reason why this filter is invalid
+	InvalidReason string
 }
 
 func (filter *alertFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
@@ -52,6 +56,16 @@ func (filter *alertFilter) GetIsValid() bool {
 	return filter.IsValid
 }
 
+func (filter *alertFilter) GetInvalidReason() string {
+	if filter.IsValid {
+		return ""
+	}
+	if filter.InvalidReason == "" {
+		return "invalid value"
+	}
+	return filter.InvalidReason
+}
+
 func (filter *alertFilter) GetName() string {
 	return filter.Matched
 }
@@ -64,17 +78,41 @@ func (filter *alertFilter) GetMatcher() string {
 }
 
 func (filter *alertFilter) GetValue() string {
-	return fmt.Sprintf("%s", filter.Value)
+	return fmt.Sprintf("%v", filter.Value)
 }
 
 type newFilterFactory func() FilterT
 
+func newInvalidFilter(expression string, reason string) FilterT {
+	invalid := alwaysInvalidFilter{}
+	invalid.init("", nil, expression, false, expression)
+	invalid.InvalidReason = reason
+	return &invalid
+}
+
 // NewFilter creates new filter object from filter expression like "key=value"
 // expression will be parsed and best filter implementation and value matcher
 // will be selected
+// Multiple expressions can be joined with " OR ", for example
+// "severity=critical OR severity=warning", and such filter will match alerts
+// that match any of them
+// Parsed filters are cached, every call returns a new copy
 func NewFilter(expression string) FilterT {
-	invalid := alwaysInvalidFilter{}
-	invalid.init("", nil, expression, false, expression)
+	if f, found := parsedFilters.get(expression); found {
+		return cloneFilter(f)
+	}
+	f := parseFilter(expression)
+	if !isCacheable(f) {
+		return f
+	}
+	parsedFilters.add(expression, f)
+	return cloneFilter(f)
+}
+
+func parseFilter(expression string) FilterT {
+	if strings.Contains(expression, orSeparator) {
+		return newOrFilter(expression)
+	}
 
 	reExp := fmt.Sprintf("^(?P<matched>(%s))(?P<operator>(%s))(?P<value>(.*))", filterRegex, matcherRegex)
 	re := regexp.MustCompile(reExp)
@@ -104,12 +142,16 @@ func NewFilter(expression string) FilterT {
 
 	if value == "" {
 		// there's no value, so it's always invalid
-		return &invalid
+		return newInvalidFilter(expression, "missing value")
 	}
 
 	if operator == "" {
 		// no operator, no valid filter here
-		return &invalid
+		return newInvalidFilter(expression, "missing operator")
+	}
+
+	if _, err := newMatcher(operator); err != nil {
+		return newInvalidFilter(expression, fmt.Sprintf("unknown operator '%s'", operator))
 	}
 
 	// we have "filter=" part, lookup filter that matches
@@ -120,19 +162,20 @@ func NewFilter(expression string) FilterT {
 			continue
 		}
 		if !slices.StringInSlice(fc.SupportedOperators, operator) {
-			return &invalid
+			return newInvalidFilter(expression, fmt.Sprintf("operator '%s' is not supported by '%s' filter", operator, matched))
 		}
-		matcher, err := newMatcher(operator)
-		if err != nil {
-			f.init(matched, nil, expression, false, "")
-		} else {
-			if value != "" {
-				f.init(matched, &matcher, expression, true, value)
-				return f
+		if _, isAnnotation := f.(*annotationFilter); !isAnnotation && (operator == regexpOperator || operator == negativeRegexOperator) {
+			// annotation filter values are in name:value format, only the value
+			// part is a regex and it's validated by annotationFilter.init()
+			if _, err := regexp.Compile(value); err != nil {
+				// value must be a valid regex when using regex operators
+				return newInvalidFilter(expression, fmt.Sprintf("invalid regex: %s", err))
 			}
-			f.init(matched, &matcher, expression, false, "")
 		}
+		matcher, _ := newMatcher(operator)
+		f.init(matched, &matcher, expression, true, value)
+		return f
 	}
 
-	return &invalid
+	return newInvalidFilter(expression, fmt.Sprintf("unknown filter '%s'", matched))
 }
@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// ackedFilter matches alerts that were acknowledged in karma
+type ackedFilter struct {
+	alertFilter
+}
+
+func (filter *ackedFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be true or false"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *ackedFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alert.Acknowledged, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAckedFilter() FilterT {
+	f := ackedFilter{}
+	return &f
+}
+
+func ackedAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	if len(alerts) == 0 {
+		return tokens
+	}
+	for _, operator := range operators {
+		for _, value := range []string{"true", "false"} {
+			tokens = append(tokens, makeAC(
+				name+operator+value,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
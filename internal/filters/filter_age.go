@@ -2,12 +2,47 @@ package filters
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/prymitive/karma/internal/models"
 )
 
+var durationDaysRegex = regexp.MustCompile("^(-)?([0-9]+)d(.*)$")
+
+// parseDuration works like time.ParseDuration but also accepts days (d) as
+// the leading unit, so 2d or 1d12h are valid durations
+func parseDuration(value string) (time.Duration, error) {
+	match := durationDaysRegex.FindStringSubmatch(value)
+	if match == nil {
+		return time.ParseDuration(value)
+	}
+
+	days, err := strconv.Atoi(match[2])
+	if err != nil {
+		return 0, err
+	}
+	dur := time.Duration(days) * time.Hour * 24
+
+	if match[3] != "" {
+		if strings.HasPrefix(match[3], "-") || strings.HasPrefix(match[3], "+") {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		rest, err := time.ParseDuration(match[3])
+		if err != nil {
+			return 0, err
+		}
+		dur += rest
+	}
+
+	if match[1] == "-" {
+		dur = -dur
+	}
+	return dur, nil
+}
+
 type ageFilter struct {
 	alertFilter
 }
@@ -20,9 +55,10 @@ func (filter *ageFilter) init(name string, matcher *matcherT, rawText string, is
 	filter.RawText = rawText
 	filter.IsValid = isValid
 
-	dur, err := time.ParseDuration(value)
+	dur, err := parseDuration(value)
 	if err != nil {
 		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("invalid duration '%s'", value)
 	}
 	if dur > 0 {
 		filter.Value = -dur
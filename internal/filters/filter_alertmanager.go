@@ -14,9 +14,21 @@ type alertmanagerInstanceFilter struct {
 func (filter *alertmanagerInstanceFilter) Match(alert *models.Alert, matches int) bool {
 	if filter.IsValid {
 		var isMatch bool
-		for _, am := range alert.Alertmanager {
-			if filter.Matcher.Compare(am.Name, filter.Value) {
-				isMatch = true
+		operator := filter.Matcher.GetOperator()
+		if operator == notEqualOperator || operator == negativeRegexOperator {
+			// alert collected from multiple instances shouldn't match if any
+			// of them is excluded by this filter
+			isMatch = len(alert.Alertmanager) > 0
+			for _, am := range alert.Alertmanager {
+				if !filter.Matcher.Compare(am.Name, filter.Value) {
+					isMatch = false
+				}
+			}
+		} else {
+			for _, am := range alert.Alertmanager {
+				if filter.Matcher.Compare(am.Name, filter.Value) {
+					isMatch = true
+				}
 			}
 		}
 		if isMatch {
@@ -0,0 +1,154 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+func annotationValue(alert *models.Alert, name string) (string, bool) {
+	for _, annotation := range alert.Annotations {
+		if annotation.Name == name {
+			return annotation.Value, true
+		}
+	}
+	return "", false
+}
+
+type annotationExistsFilter struct {
+	alertFilter
+}
+
+func (filter *annotationExistsFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		_, found := annotationValue(alert, filter.Value.(string))
+		isMatch := found
+		if filter.Matcher.GetOperator() == notEqualOperator {
+			isMatch = !found
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAnnotationExistsFilter() FilterT {
+	f := annotationExistsFilter{}
+	return &f
+}
+
+func annotationExistsAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		for _, annotation := range alert.Annotations {
+			for _, operator := range operators {
+				token := fmt.Sprintf("%s%s%s", name, operator, annotation.Name)
+				tokens[token] = makeAC(
+					token,
+					[]string{
+						name,
+						strings.TrimPrefix(name, "@"),
+						name + operator,
+					},
+				)
+			}
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
+
+// annotationFilter matches alerts using annotation values, filter value must
+// be passed as "name:value"
+// Alerts without given annotation are compared as if the annotation value was
+// empty, so @annotation=name: matches alerts with missing or empty annotation
+// An empty regex only matches empty values, so @annotation=~name: works the
+// same way
+type annotationFilter struct {
+	alertFilter
+	AnnotationName  string
+	AnnotationValue string
+}
+
+func (filter *annotationFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	if filter.IsValid {
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be in 'name:value' format"
+			return
+		}
+		filter.AnnotationName = parts[0]
+		filter.AnnotationValue = parts[1]
+		switch filter.Matcher.GetOperator() {
+		case regexpOperator, negativeRegexOperator:
+			if filter.AnnotationValue == "" {
+				filter.AnnotationValue = "^$"
+			}
+			if _, err := regexp.Compile(filter.AnnotationValue); err != nil {
+				filter.IsValid = false
+				filter.InvalidReason = fmt.Sprintf("invalid regex: %s", err)
+			}
+		}
+	}
+}
+
+func (filter *annotationFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		value, _ := annotationValue(alert, filter.AnnotationName)
+		isMatch := filter.Matcher.Compare(value, filter.AnnotationValue)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAnnotationFilter() FilterT {
+	f := annotationFilter{}
+	return &f
+}
+
+func annotationAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		for _, annotation := range alert.Annotations {
+			for _, operator := range operators {
+				switch operator {
+				case equalOperator, notEqualOperator:
+					token := fmt.Sprintf("%s%s%s:%s", name, operator, annotation.Name, annotation.Value)
+					tokens[token] = makeAC(
+						token,
+						[]string{
+							name,
+							strings.TrimPrefix(name, "@"),
+							name + operator,
+						},
+					)
+				}
+			}
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
@@ -0,0 +1,121 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// annotationNumRegex parses "name<op>number" values, longer operators must be
+// listed first so that ">=" isn't parsed as ">" followed by "=number"
+var annotationNumRegex = regexp.MustCompile("^([a-zA-Z_][a-zA-Z0-9_]*)(>=|<=|!=|>|<|=)(.+)$")
+
+// annotationNumFilter matches alerts using numeric annotation values, filter
+// value must be passed as "name<op>number", for example "value>90"
+// Alerts without given annotation or with annotation value that's not a
+// number never match
+type annotationNumFilter struct {
+	alertFilter
+	AnnotationName string
+	Operator       string
+	Number         float64
+}
+
+func (filter *annotationNumFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	if filter.IsValid {
+		parts := annotationNumRegex.FindStringSubmatch(value)
+		if parts == nil {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be in 'name<operator>number' format"
+			return
+		}
+		number, err := strconv.ParseFloat(parts[3], 64)
+		if err != nil {
+			filter.IsValid = false
+			filter.InvalidReason = fmt.Sprintf("invalid number '%s'", parts[3])
+			return
+		}
+		filter.AnnotationName = parts[1]
+		filter.Operator = parts[2]
+		filter.Number = number
+	}
+}
+
+func (filter *annotationNumFilter) compare(value float64) bool {
+	switch filter.Operator {
+	case moreThanOperator:
+		return value > filter.Number
+	case lessThanOperator:
+		return value < filter.Number
+	case moreThanOrEqOperator:
+		return value >= filter.Number
+	case lessThanOrEqOperator:
+		return value <= filter.Number
+	case notEqualOperator:
+		return value != filter.Number
+	default:
+		return value == filter.Number
+	}
+}
+
+func (filter *annotationNumFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		var isMatch bool
+		if value, found := annotationValue(alert, filter.AnnotationName); found {
+			if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				isMatch = filter.compare(number)
+			}
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAnnotationNumFilter() FilterT {
+	f := annotationNumFilter{}
+	return &f
+}
+
+func annotationNumAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		for _, annotation := range alert.Annotations {
+			if _, err := strconv.ParseFloat(strings.TrimSpace(annotation.Value), 64); err != nil {
+				continue
+			}
+			for _, operator := range operators {
+				for _, numOperator := range []string{moreThanOperator, lessThanOperator} {
+					token := fmt.Sprintf("%s%s%s%s%s", name, operator, annotation.Name, numOperator, strings.TrimSpace(annotation.Value))
+					tokens[token] = makeAC(
+						token,
+						[]string{
+							name,
+							strings.TrimPrefix(name, "@"),
+							name + operator,
+							name + operator + annotation.Name,
+						},
+					)
+				}
+			}
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
@@ -0,0 +1,62 @@
+package filters
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// anyLabelFilter matches alerts with at least one label value matching given
+// regex, no matter what the label name is, the negative operator will only
+// match alerts where none of the label values matches
+type anyLabelFilter struct {
+	alertFilter
+}
+
+func (filter *anyLabelFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	if _, err := regexp.Compile(value); err != nil {
+		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("invalid regex: %s", err)
+	}
+}
+
+func (filter *anyLabelFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		re := regexpMatcher{}
+		var found bool
+		for _, val := range alert.Labels {
+			if re.Compare(val, filter.Value) {
+				found = true
+				break
+			}
+		}
+		isMatch := found
+		if filter.Matcher.GetOperator() == negativeRegexOperator {
+			isMatch = !found
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newAnyLabelFilter() FilterT {
+	f := anyLabelFilter{}
+	return &f
+}
+
+func anyLabelAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	// every label value would be a hint, label filters already provide those
+	return []models.Autocomplete{}
+}
@@ -0,0 +1,92 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// clusterCountFilter matches alerts based on the number of distinct
+// Alertmanager clusters reporting them.
+// Instances with no cluster ID set are ignored, so an alert with no
+// Alertmanager data has a cluster count of 0. Comparison is done on the exact
+// number, so @cluster_count>1 and @cluster_count>=2 will both match alerts
+// reported by at least 2 clusters and @cluster_count=1 will only match alerts
+// with a single cluster.
+type clusterCountFilter struct {
+	alertFilter
+}
+
+func (filter *clusterCountFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a non-negative integer"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *clusterCountFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		clusters := map[string]bool{}
+		for _, am := range alert.Alertmanager {
+			if am.Cluster != "" {
+				clusters[am.Cluster] = true
+			}
+		}
+		isMatch := filter.Matcher.Compare(len(clusters), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newClusterCountFilter() FilterT {
+	f := clusterCountFilter{}
+	return &f
+}
+
+func clusterCountAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		clusters := map[string]bool{}
+		for _, am := range alert.Alertmanager {
+			if am.Cluster != "" {
+				clusters[am.Cluster] = true
+			}
+		}
+		if len(clusters) == 0 {
+			continue
+		}
+		for _, operator := range operators {
+			token := fmt.Sprintf("%s%s%d", name, operator, len(clusters))
+			tokens[token] = makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			)
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
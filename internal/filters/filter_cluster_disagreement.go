@@ -0,0 +1,71 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// clusterDisagreementFilter matches alerts that are missing from some healthy
+// members of the cluster they were collected from, members can disagree about
+// the presence of an alert because of gossip lag or split-brain
+type clusterDisagreementFilter struct {
+	alertFilter
+}
+
+func (filter *clusterDisagreementFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be true or false"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *clusterDisagreementFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alert.ClusterDisagreement, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newClusterDisagreementFilter() FilterT {
+	f := clusterDisagreementFilter{}
+	return &f
+}
+
+func clusterDisagreementAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	if len(alerts) == 0 {
+		return tokens
+	}
+	for _, operator := range operators {
+		for _, value := range []string{"true", "false"} {
+			tokens = append(tokens, makeAC(
+				name+operator+value,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
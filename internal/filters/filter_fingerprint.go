@@ -0,0 +1,37 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+type fingerprintFilter struct {
+	alertFilter
+}
+
+func (filter *fingerprintFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if alert.Fingerprint == "" {
+			// we don't know the fingerprint so we can't match anything
+			return false
+		}
+		isMatch := filter.Matcher.Compare(alert.Fingerprint, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newFingerprintFilter() FilterT {
+	f := fingerprintFilter{}
+	return &f
+}
+
+func fingerprintAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	// fingerprints are unique for every alert so they are not useful as hints
+	return []models.Autocomplete{}
+}
@@ -0,0 +1,55 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// fingerprintInFilter matches alerts with any of the fingerprints passed as
+// a comma separated list, it's used by external tools linking to a number of
+// alerts at once
+type fingerprintInFilter struct {
+	alertFilter
+	fingerprints map[string]bool
+}
+
+func (filter *fingerprintInFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	filter.Value = value
+	if filter.IsValid {
+		filter.fingerprints = map[string]bool{}
+		for _, fp := range strings.Split(value, ",") {
+			if fp = strings.TrimSpace(fp); fp != "" {
+				filter.fingerprints[fp] = true
+			}
+		}
+		if len(filter.fingerprints) == 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a comma separated list of fingerprints"
+		}
+	}
+}
+
+func (filter *fingerprintInFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := alert.Fingerprint != "" && filter.fingerprints[alert.Fingerprint]
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newFingerprintInFilter() FilterT {
+	f := fingerprintInFilter{}
+	return &f
+}
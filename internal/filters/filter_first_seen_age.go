@@ -0,0 +1,36 @@
+package filters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// firstSeenAgeFilter works like ageFilter but uses the timestamp of when karma
+// first collected the alert instead of StartsAt
+type firstSeenAgeFilter struct {
+	ageFilter
+}
+
+func (filter *firstSeenAgeFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if alert.KarmaFirstSeen.IsZero() {
+			// we don't know when this alert was first seen
+			return false
+		}
+		ts := time.Now().Add(filter.Value.(time.Duration))
+		isMatch := filter.Matcher.Compare(int(ts.Unix()), int(alert.KarmaFirstSeen.Unix()))
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newFirstSeenAgeFilter() FilterT {
+	f := firstSeenAgeFilter{}
+	return &f
+}
@@ -21,6 +21,7 @@ func (filter *fuzzyFilter) init(name string, matcher *matcherT, rawText string,
 	filter.Value = value
 	if _, err := regexp.Compile(value); err != nil {
 		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("invalid regex: %s", err)
 	}
 }
 
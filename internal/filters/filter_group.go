@@ -0,0 +1,38 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// groupFilter matches alerts by the ID of the alert group they belong to
+type groupFilter struct {
+	alertFilter
+}
+
+func (filter *groupFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if alert.GroupID == "" {
+			// we don't know the group so we can't match anything
+			return false
+		}
+		isMatch := filter.Matcher.Compare(alert.GroupID, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newGroupFilter() FilterT {
+	f := groupFilter{}
+	return &f
+}
+
+func groupAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	// group IDs are checksums so they are not useful as hints
+	return []models.Autocomplete{}
+}
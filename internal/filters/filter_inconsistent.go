@@ -0,0 +1,72 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// inconsistentFilter matches alerts that were reported with different labels
+// by Alertmanager upstreams they were collected from, which usually means that
+// upstreams have different configuration, label sets can only differ when
+// labels are normalized or ignored for deduplication
+type inconsistentFilter struct {
+	alertFilter
+}
+
+func (filter *inconsistentFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be true or false"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *inconsistentFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alert.Inconsistent, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newInconsistentFilter() FilterT {
+	f := inconsistentFilter{}
+	return &f
+}
+
+func inconsistentAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	if len(alerts) == 0 {
+		return tokens
+	}
+	for _, operator := range operators {
+		for _, value := range []string{"true", "false"} {
+			tokens = append(tokens, makeAC(
+				name+operator+value,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
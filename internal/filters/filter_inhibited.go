@@ -0,0 +1,69 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+type inhibitedFilter struct {
+	alertFilter
+}
+
+func (filter *inhibitedFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be true or false"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *inhibitedFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		// alerts with unknown state are never inhibited
+		isMatch := filter.Matcher.Compare(alert.IsInhibited(), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newInhibitedFilter() FilterT {
+	f := inhibitedFilter{}
+	return &f
+}
+
+func inhibitedAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	if len(alerts) == 0 {
+		return tokens
+	}
+	for _, operator := range operators {
+		for _, value := range []string{"true", "false"} {
+			tokens = append(tokens, makeAC(
+				name+operator+value,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
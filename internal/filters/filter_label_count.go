@@ -0,0 +1,74 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// labelCountFilter matches alerts based on the number of labels they have.
+// Filters are applied before labels shared by all alerts in a group are moved
+// to the group, so every label of the alert is counted, including labels used
+// for grouping and labels shared with other alerts.
+type labelCountFilter struct {
+	alertFilter
+}
+
+func (filter *labelCountFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a non-negative integer"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *labelCountFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(len(alert.Labels), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newLabelCountFilter() FilterT {
+	f := labelCountFilter{}
+	return &f
+}
+
+func labelCountAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		for _, operator := range operators {
+			token := fmt.Sprintf("%s%s%d", name, operator, len(alert.Labels))
+			tokens[token] = makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			)
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
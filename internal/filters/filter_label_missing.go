@@ -0,0 +1,64 @@
+package filters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// labelMissingFilter matches alerts that don't have a label with given name.
+// Filters are applied before labels shared by all alerts in a group are moved
+// to the group, so alert labels already include group and shared labels.
+type labelMissingFilter struct {
+	alertFilter
+}
+
+func (filter *labelMissingFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		name, _ := filter.Value.(string)
+		_, found := models.LookupLabel(name, alert.Labels)
+		isMatch := filter.Matcher.Compare(found, false)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newLabelMissingFilter() FilterT {
+	f := labelMissingFilter{}
+	return &f
+}
+
+func labelMissingAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	labelNames := map[string]bool{}
+	for _, alert := range alerts {
+		for label := range alert.Labels {
+			labelNames[label] = true
+		}
+	}
+	names := make([]string, 0, len(labelNames))
+	for label := range labelNames {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		for _, label := range names {
+			tokens = append(tokens, makeAC(
+				name+operator+label,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
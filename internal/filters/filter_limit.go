@@ -23,6 +23,7 @@ func (filter *limitFilter) init(name string, matcher *matcherT, rawText string,
 		val, err := strconv.Atoi(value)
 		if err != nil || val < 1 {
 			filter.IsValid = false
+			filter.InvalidReason = "value must be a positive integer"
 		} else {
 			filter.Value = val
 		}
@@ -0,0 +1,83 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// alertLinkCount returns the number of distinct URLs in alert annotations,
+// only annotations marked as links are counted
+func alertLinkCount(alert *models.Alert) int {
+	links := map[string]bool{}
+	for _, annotation := range alert.Annotations {
+		if annotation.IsLink {
+			links[annotation.Value] = true
+		}
+	}
+	return len(links)
+}
+
+type linkCountFilter struct {
+	alertFilter
+}
+
+func (filter *linkCountFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a non-negative integer"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *linkCountFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alertLinkCount(alert), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newLinkCountFilter() FilterT {
+	f := linkCountFilter{}
+	return &f
+}
+
+func linkCountAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		alert := alert // scopelint pin
+		for _, operator := range operators {
+			token := fmt.Sprintf("%s%s%d", name, operator, alertLinkCount(&alert))
+			tokens[token] = makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			)
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
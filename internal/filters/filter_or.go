@@ -0,0 +1,75 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// orSeparator is used to split a single filter expression into a list of
+// alternatives, alert will match if any of them matches
+const orSeparator = " OR "
+
+type orFilter struct {
+	alertFilter
+	Alternatives []FilterT
+}
+
+func (filter *orFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		for _, alternative := range filter.Alternatives {
+			if alternative.Match(alert, matches) {
+				filter.Hits++
+				return true
+			}
+		}
+		return false
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+// ExpressionCount returns the number of expressions in a parsed filter, every
+// alternative of a filter joined with " OR " is counted separately and empty
+// filters are not counted at all
+func ExpressionCount(filter FilterT) int {
+	if f, ok := filter.(*orFilter); ok {
+		var count int
+		for _, alternative := range f.Alternatives {
+			count += ExpressionCount(alternative)
+		}
+		return count
+	}
+	if strings.TrimSpace(filter.GetRawText()) == "" {
+		return 0
+	}
+	return 1
+}
+
+// newOrFilter creates a filter from an expression like "a=b OR c=d", it's
+// only valid if every alternative is valid
+func newOrFilter(expression string) FilterT {
+	f := orFilter{}
+	var reason string
+	for _, alternativeExpression := range strings.Split(expression, orSeparator) {
+		alternativeExpression = strings.TrimSpace(alternativeExpression)
+		alternative := NewFilter(alternativeExpression)
+		switch {
+		case reason != "":
+		case alternativeExpression == "":
+			reason = "empty alternative"
+		case !alternative.GetIsValid():
+			reason = fmt.Sprintf("invalid alternative '%s': %s", alternativeExpression, alternative.GetInvalidReason())
+		default:
+			if _, isLimit := alternative.(*limitFilter); isLimit {
+				// @limit doesn't match alerts, it only caps the number of them
+				reason = "@limit can't be used as an alternative"
+			}
+		}
+		f.Alternatives = append(f.Alternatives, alternative)
+	}
+	f.init("", nil, expression, reason == "", expression)
+	f.InvalidReason = reason
+	return &f
+}
@@ -14,6 +14,11 @@ type receiverFilter struct {
 func (filter *receiverFilter) Match(alert *models.Alert, matches int) bool {
 	if filter.IsValid {
 		isMatch := filter.Matcher.Compare(alert.Receiver, filter.Value)
+		if alert.Receiver == "" {
+			// alert without receiver can only match negative receiver filters
+			operator := filter.Matcher.GetOperator()
+			isMatch = operator == notEqualOperator || operator == negativeRegexOperator
+		}
 		if isMatch {
 			filter.Hits++
 		}
@@ -0,0 +1,83 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+)
+
+// severityOrdinal returns the position of given severity in the configured
+// ranking, severities are compared case insensitive and unknown ones sort
+// lowest
+func severityOrdinal(severity string) int {
+	for i, s := range config.Config.Filters.Severity.Order {
+		if strings.EqualFold(s, severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// severityAtLeastFilter matches alerts with a severity label equal to or
+// ranked higher than given severity
+type severityAtLeastFilter struct {
+	alertFilter
+}
+
+func (filter *severityAtLeastFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		ordinal := severityOrdinal(value)
+		if ordinal < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = fmt.Sprintf("unknown severity, must be one of: %s", strings.Join(config.Config.Filters.Severity.Order, ", "))
+		} else {
+			filter.Value = ordinal
+		}
+	}
+}
+
+func (filter *severityAtLeastFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		ordinal := -1
+		if severity, found := models.LookupLabel(config.Config.Filters.Severity.Label, alert.Labels); found {
+			ordinal = severityOrdinal(severity)
+		}
+		isMatch := ordinal >= filter.Value.(int)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSeverityAtLeastFilter() FilterT {
+	f := severityAtLeastFilter{}
+	return &f
+}
+
+func severityAtLeastAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		for _, severity := range config.Config.Filters.Severity.Order {
+			tokens = append(tokens, makeAC(
+				name+operator+severity,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					name + operator,
+				},
+			))
+		}
+	}
+	return tokens
+}
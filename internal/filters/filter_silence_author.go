@@ -14,20 +14,26 @@ type silenceAuthorFilter struct {
 func (filter *silenceAuthorFilter) Match(alert *models.Alert, matches int) bool {
 	if filter.IsValid {
 		var isMatch bool
+		authors := []string{}
 		if alert.IsSilenced() {
 			for _, silenceID := range alert.SilencedBy {
 				for _, am := range alert.Alertmanager {
 					silence, found := am.Silences[silenceID]
 					if found {
-						m := filter.Matcher.Compare(silence.CreatedBy, filter.Value)
-						if m {
-							isMatch = m
-						}
+						authors = append(authors, silence.CreatedBy)
 					}
 				}
 			}
-		} else {
-			isMatch = filter.Matcher.Compare("", filter.Value)
+		}
+		if len(authors) == 0 {
+			// alert isn't silenced or we don't know anything about silences, so
+			// there's no author to compare
+			authors = append(authors, "")
+		}
+		for _, author := range authors {
+			if filter.Matcher.Compare(author, filter.Value) {
+				isMatch = true
+			}
 		}
 		if isMatch {
 			filter.Hits++
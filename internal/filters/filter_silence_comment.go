@@ -0,0 +1,67 @@
+package filters
+
+import (
+	"fmt"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+type silenceCommentFilter struct {
+	alertFilter
+}
+
+func (filter *silenceCommentFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		comments := []string{}
+		if alert.IsSilenced() {
+			for _, silenceID := range alert.SilencedBy {
+				for _, am := range alert.Alertmanager {
+					silence, found := am.Silences[silenceID]
+					if found {
+						comments = append(comments, silence.Comment)
+					}
+				}
+			}
+		}
+		if len(comments) == 0 {
+			// alert isn't silenced or we don't know anything about silences,
+			// there's no comment to check so never match
+			return false
+		}
+
+		var isMatch bool
+		switch filter.Matcher.GetOperator() {
+		case negativeRegexOperator:
+			// all comments must not match the regex
+			isMatch = true
+			for _, comment := range comments {
+				if !filter.Matcher.Compare(comment, filter.Value) {
+					isMatch = false
+				}
+			}
+		default:
+			// any comment must match the regex
+			for _, comment := range comments {
+				if filter.Matcher.Compare(comment, filter.Value) {
+					isMatch = true
+				}
+			}
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSilenceCommentFilter() FilterT {
+	f := silenceCommentFilter{}
+	return &f
+}
+
+func silenceCommentAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	// comments are free form text, there's nothing useful to suggest
+	return []models.Autocomplete{}
+}
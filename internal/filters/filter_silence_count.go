@@ -0,0 +1,97 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// activeSilenceCount returns the number of distinct silences that are
+// currently active for given alert, silences from all Alertmanager upstreams
+// are counted, but silences replicated between cluster members share the same
+// ID so those are only counted once
+func activeSilenceCount(alert *models.Alert, now time.Time) int {
+	silences := map[string]bool{}
+	for _, am := range alert.Alertmanager {
+		for _, silenceID := range am.SilencedBy {
+			silence, found := am.Silences[silenceID]
+			if !found || silence.StartsAt.After(now) || !silence.EndsAt.After(now) {
+				continue
+			}
+			silences[silenceID] = true
+		}
+	}
+	return len(silences)
+}
+
+// silenceCountFilter matches alerts based on the number of active silences
+// matching them
+type silenceCountFilter struct {
+	alertFilter
+}
+
+func (filter *silenceCountFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a non-negative integer"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *silenceCountFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(activeSilenceCount(alert, time.Now()), filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSilenceCountFilter() FilterT {
+	f := silenceCountFilter{}
+	return &f
+}
+
+func silenceCountAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	now := time.Now()
+	for _, alert := range alerts {
+		count := activeSilenceCount(&alert, now)
+		if count < 2 {
+			// only suggest filters for alerts with overlapping silences
+			continue
+		}
+		for _, operator := range operators {
+			token := fmt.Sprintf("%s%s%d", name, operator, count)
+			tokens[token] = makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			)
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
@@ -0,0 +1,100 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+type silenceEndsInFilter struct {
+	alertFilter
+}
+
+func (filter *silenceEndsInFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+
+	dur, err := parseDuration(value)
+	if err != nil {
+		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("invalid duration '%s'", value)
+	} else if dur < 0 {
+		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("duration '%s' must not be negative", value)
+	}
+	filter.Value = dur
+}
+
+func (filter *silenceEndsInFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if !alert.IsSilenced() {
+			return false
+		}
+
+		now := time.Now()
+		// if there are multiple silences then use the one that ends first
+		var endsAt time.Time
+		for _, silenceID := range alert.SilencedBy {
+			for _, am := range alert.Alertmanager {
+				silence, found := am.Silences[silenceID]
+				if !found || !silence.EndsAt.After(now) {
+					continue
+				}
+				if endsAt.IsZero() || silence.EndsAt.Before(endsAt) {
+					endsAt = silence.EndsAt
+				}
+			}
+		}
+		if endsAt.IsZero() {
+			// we don't know anything about active silences for this alert
+			return false
+		}
+
+		ts := now.Add(filter.Value.(time.Duration))
+		isMatch := filter.Matcher.Compare(int(endsAt.Unix()), int(ts.Unix()))
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSilenceEndsInFilter() FilterT {
+	f := silenceEndsInFilter{}
+	return &f
+}
+
+func silenceEndsInAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	var silenced bool
+	for _, alert := range alerts {
+		if alert.IsSilenced() {
+			silenced = true
+			break
+		}
+	}
+	if !silenced {
+		return tokens
+	}
+	for _, operator := range operators {
+		for _, value := range []string{"10m", "1h"} {
+			tokens = append(tokens, makeAC(
+				fmt.Sprintf("%s%s%s", name, operator, value),
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			))
+		}
+	}
+	return tokens
+}
@@ -0,0 +1,107 @@
+package filters
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// generatorURLHost returns the host part of the generatorURL, without the
+// port, empty string is returned for malformed URLs
+func generatorURLHost(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// sourceHosts returns the list of unique hosts from the generatorURL of each
+// Alertmanager instance alert was collected from
+func sourceHosts(alert *models.Alert) []string {
+	hosts := []string{}
+	seen := map[string]bool{}
+	for _, am := range alert.Alertmanager {
+		if host := generatorURLHost(am.Source); host != "" && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		if host := generatorURLHost(alert.GeneratorURL); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+type sourceHostFilter struct {
+	alertFilter
+}
+
+func (filter *sourceHostFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		var isMatch bool
+		hosts := sourceHosts(alert)
+		operator := filter.Matcher.GetOperator()
+		if operator == notEqualOperator || operator == negativeRegexOperator {
+			// alerts without any source never match, those with multiple
+			// sources only match if none of them is excluded
+			isMatch = len(hosts) > 0
+			for _, host := range hosts {
+				if !filter.Matcher.Compare(host, filter.Value) {
+					isMatch = false
+				}
+			}
+		} else {
+			for _, host := range hosts {
+				if filter.Matcher.Compare(host, filter.Value) {
+					isMatch = true
+				}
+			}
+		}
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSourceHostFilter() FilterT {
+	f := sourceHostFilter{}
+	return &f
+}
+
+func sourceHostAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for i := range alerts {
+		for _, host := range sourceHosts(&alerts[i]) {
+			for _, operator := range operators {
+				switch operator {
+				case equalOperator, notEqualOperator:
+					token := fmt.Sprintf("%s%s%s", name, operator, host)
+					tokens[token] = makeAC(
+						token,
+						[]string{
+							name,
+							strings.TrimPrefix(name, "@"),
+							name + operator,
+						},
+					)
+				}
+			}
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
@@ -0,0 +1,77 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// sourcesFilter matches alerts based on the number of distinct Alertmanager
+// upstream URIs reporting them, unlike @cluster_count every upstream is
+// counted, even if it's a member of a cluster
+type sourcesFilter struct {
+	alertFilter
+}
+
+func (filter *sourcesFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+	if filter.IsValid {
+		val, err := strconv.Atoi(value)
+		if err != nil || val < 0 {
+			filter.IsValid = false
+			filter.InvalidReason = "value must be a non-negative integer"
+		} else {
+			filter.Value = val
+		}
+	}
+}
+
+func (filter *sourcesFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		isMatch := filter.Matcher.Compare(alert.Sources, filter.Value)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newSourcesFilter() FilterT {
+	f := sourcesFilter{}
+	return &f
+}
+
+func sourcesAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := map[string]models.Autocomplete{}
+	for _, alert := range alerts {
+		if alert.Sources < 2 {
+			// only suggest filters for alerts with duplicates
+			continue
+		}
+		for _, operator := range operators {
+			token := fmt.Sprintf("%s%s%d", name, operator, alert.Sources)
+			tokens[token] = makeAC(
+				token,
+				[]string{
+					name,
+					strings.TrimPrefix(name, "@"),
+					fmt.Sprintf("%s%s", name, operator),
+				},
+			)
+		}
+	}
+	acData := []models.Autocomplete{}
+	for _, token := range tokens {
+		acData = append(acData, token)
+	}
+	return acData
+}
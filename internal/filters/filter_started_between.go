@@ -0,0 +1,139 @@
+package filters
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+)
+
+// timeOfDayWindow is a daily window, both start and end are the number of
+// minutes since midnight, if end is lower than start then the window crosses
+// midnight
+type timeOfDayWindow struct {
+	start int
+	end   int
+}
+
+func (w timeOfDayWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/60, w.start%60, w.end/60, w.end%60)
+}
+
+func (w timeOfDayWindow) contains(minutes int) bool {
+	if w.start < w.end {
+		return minutes >= w.start && minutes < w.end
+	}
+	return minutes >= w.start || minutes < w.end
+}
+
+// parseTimeOfDayWindow parses windows in the HH:MM-HH:MM format
+func parseTimeOfDayWindow(value string) (timeOfDayWindow, error) {
+	w := timeOfDayWindow{}
+
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return w, fmt.Errorf("invalid window '%s', expected HH:MM-HH:MM", value)
+	}
+
+	for i, part := range parts {
+		ts, err := time.Parse("15:04", part)
+		if err != nil {
+			return w, fmt.Errorf("invalid time '%s' in window '%s'", part, value)
+		}
+		if i == 0 {
+			w.start = ts.Hour()*60 + ts.Minute()
+		} else {
+			w.end = ts.Hour()*60 + ts.Minute()
+		}
+	}
+
+	if w.start == w.end {
+		return w, fmt.Errorf("invalid window '%s', start and end must be different", value)
+	}
+
+	return w, nil
+}
+
+var (
+	timezoneLock     sync.Mutex
+	timezoneName     string
+	timezoneLocation = time.UTC
+)
+
+// filterLocation returns the timezone configured for time based filters, it's
+// loaded once and reused until the config value changes
+func filterLocation() *time.Location {
+	timezoneLock.Lock()
+	defer timezoneLock.Unlock()
+
+	if config.Config.Filters.Timezone != timezoneName {
+		loc, err := time.LoadLocation(config.Config.Filters.Timezone)
+		if err != nil {
+			loc = time.UTC
+		}
+		timezoneName = config.Config.Filters.Timezone
+		timezoneLocation = loc
+	}
+	return timezoneLocation
+}
+
+type startedBetweenFilter struct {
+	alertFilter
+}
+
+func (filter *startedBetweenFilter) init(name string, matcher *matcherT, rawText string, isValid bool, value string) {
+	filter.Matched = name
+	if matcher != nil {
+		filter.Matcher = *matcher
+	}
+	filter.RawText = rawText
+	filter.IsValid = isValid
+
+	w, err := parseTimeOfDayWindow(value)
+	if err != nil {
+		filter.IsValid = false
+		filter.InvalidReason = err.Error()
+	}
+	filter.Value = w
+}
+
+func (filter *startedBetweenFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if alert.StartsAt.IsZero() {
+			// we don't know when the alert started so we can't match anything
+			return false
+		}
+		ts := alert.StartsAt.In(filterLocation())
+		inWindow := filter.Value.(timeOfDayWindow).contains(ts.Hour()*60 + ts.Minute())
+		isMatch := filter.Matcher.Compare(inWindow, true)
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newStartedBetweenFilter() FilterT {
+	f := startedBetweenFilter{}
+	return &f
+}
+
+func startedBetweenAutocomplete(name string, operators []string, alerts []models.Alert) []models.Autocomplete {
+	tokens := []models.Autocomplete{}
+	for _, operator := range operators {
+		tokens = append(tokens, makeAC(
+			fmt.Sprintf("%s%s09:00-17:00", name, operator),
+			[]string{
+				name,
+				strings.TrimPrefix(name, "@"),
+				fmt.Sprintf("%s%s", name, operator),
+			},
+		))
+	}
+	return tokens
+}
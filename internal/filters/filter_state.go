@@ -22,6 +22,7 @@ func (filter *stateFilter) init(name string, matcher *matcherT, rawText string,
 	filter.Value = value
 	if !slices.StringInSlice(models.AlertStateList, value) {
 		filter.IsValid = false
+		filter.InvalidReason = fmt.Sprintf("unknown state '%s', valid states: %s", value, strings.Join(models.AlertStateList, ", "))
 	}
 }
 
@@ -0,0 +1,36 @@
+package filters
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+)
+
+// stateChangedFilter works like ageFilter but uses the timestamp of the most
+// recent state change karma recorded for the alert instead of StartsAt
+type stateChangedFilter struct {
+	ageFilter
+}
+
+func (filter *stateChangedFilter) Match(alert *models.Alert, matches int) bool {
+	if filter.IsValid {
+		if alert.StateChangedAt.IsZero() {
+			// there was no state change since karma started
+			return false
+		}
+		ts := time.Now().Add(filter.Value.(time.Duration))
+		isMatch := filter.Matcher.Compare(int(ts.Unix()), int(alert.StateChangedAt.Unix()))
+		if isMatch {
+			filter.Hits++
+		}
+		return isMatch
+	}
+	e := fmt.Sprintf("Match() called on invalid filter %#v", filter)
+	panic(e)
+}
+
+func newStateChangedFilter() FilterT {
+	f := stateChangedFilter{}
+	return &f
+}
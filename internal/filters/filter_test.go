@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/prymitive/karma/internal/alertmanager"
+	"github.com/prymitive/karma/internal/config"
 	"github.com/prymitive/karma/internal/filters"
 	"github.com/prymitive/karma/internal/models"
 
@@ -46,654 +47,3117 @@ var tests = []filterTest{
 		IsMatch:    false,
 	},
 	{
-		Expression: "@state=xx",
-		IsValid:    false,
-	},
-	{
-		Expression: "@state=:xx",
-		IsValid:    false,
-	},
-	{
-		Expression: "@state==xx",
-		IsValid:    false,
+		Expression: "@inhibited=true",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"1"}},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@state=~true",
-		IsValid:    false,
+		Expression: "@inhibited=true",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@state=~false",
-		IsValid:    false,
+		Expression: "@inhibited=true",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@state=suppressed",
+		Expression: "@inhibited=true",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"999"}},
-		IsMatch:    true,
+		Alert:      models.Alert{State: "unprocessed"},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@state=suppressed",
+		Expression: "@inhibited=true",
 		IsValid:    true,
-		Alert:      models.Alert{State: "active"},
+		Alert:      models.Alert{},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@state!=suppressed",
+		Expression: "@inhibited=false",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"999"}},
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"1"}},
 		IsMatch:    false,
 	},
-
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited=false",
 		IsValid:    true,
 		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		IsMatch:    false,
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited=false",
 		IsValid:    true,
 		Alert:      models.Alert{State: "active"},
-		IsMatch:    false,
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited=false",
 		IsValid:    true,
-		Alert:      models.Alert{State: "active", SilencedBy: []string{"abcdef"}},
-		IsMatch:    false,
+		Alert:      models.Alert{},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited!=true",
 		IsValid:    true,
-		Alert:      models.Alert{State: "unprocessed"},
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"1"}},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited!=true",
 		IsValid:    true,
-		Alert:      models.Alert{State: "unprocessed", SilencedBy: []string{"abcdef"}},
-		IsMatch:    false,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_id=abcdef",
+		Expression: "@inhibited!=false",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"1"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_id!=abcdef",
+		Expression: "@inhibited!=false",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
+		Alert:      models.Alert{State: "unprocessed"},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_id!=abcdef",
-		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		IsMatch:    true,
-	},
-	{
-		Expression: "@silence_id=~cde",
+		Expression: "@inhibited=xx",
 		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
-		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_id!~abc",
+		Expression: "@inhibited=~true",
 		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"zwd"}},
-		IsMatch:    false,
 	},
-
 	{
-		Expression: "@silence_jira=1",
+		Expression: "@label_count>5",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "1"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev", "instance": "server1", "job": "node", "severity": "critical", "team": "ops"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_jira=2",
+		Expression: "@label_count>5",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev"}},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_jira!=3",
+		Expression: "@label_count>=6",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "x"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev", "instance": "server1", "job": "node", "severity": "critical", "team": "ops"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_jira!=4",
+		Expression: "@label_count<3",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "4"},
-		IsMatch:    false,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev"}},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_jira!=5",
+		Expression: "@label_count<=2",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_jira=~abc",
+		Expression: "@link_count=0",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "xxabcxx"},
+		Alert:      models.Alert{},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_jira=~abc",
+		Expression: "@link_count=0",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
-		IsMatch:    false,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "summary", Value: "disk is full"},
+		}},
+		IsMatch: true,
 	},
 	{
-		Expression: "@silence_jira=~",
-		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
-		IsMatch:    false,
+		Expression: "@link_count=0",
+		IsValid:    true,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+		}},
+		IsMatch: false,
 	},
 	{
-		Expression: "@silence_jira~=",
-		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
-		IsMatch:    false,
+		Expression: "@link_count=2",
+		IsValid:    true,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "dashboard", Value: "http://grafana.example.com", IsLink: true},
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+			{Name: "summary", Value: "disk is full"},
+		}},
+		IsMatch: true,
 	},
 	{
-		Expression: "@silence_jira~=1",
-		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
-		IsMatch:    false,
+		Expression: "@link_count=1",
+		IsValid:    true,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "help", Value: "http://runbook.example.com", IsLink: true},
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+		}},
+		IsMatch: true,
 	},
-
 	{
-		Expression: "@silence_author=john",
+		Expression: "@link_count>1",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
-		IsMatch:    true,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "dashboard", Value: "http://grafana.example.com", IsLink: true},
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+		}},
+		IsMatch: true,
 	},
 	{
-		Expression: "@silence_author=john",
+		Expression: "@link_count<1",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
-		IsMatch:    false,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+		}},
+		IsMatch: false,
 	},
 	{
-		Expression: "@silence_author!=john",
+		Expression: "@link_count>=1",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
-		IsMatch:    true,
+		Alert: models.Alert{Annotations: models.Annotations{
+			{Name: "runbook", Value: "http://runbook.example.com", IsLink: true},
+		}},
+		IsMatch: true,
 	},
 	{
-		Expression: "@silence_author!=john",
+		Expression: "@link_count<=1",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
-		IsMatch:    false,
+		Alert:      models.Alert{},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silence_author!=john",
+		Expression: "@link_count!=0",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
-		IsMatch:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_author=~",
+		Expression: "@link_count=-1",
 		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
-		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_author===x",
+		Expression: "@link_count=foo",
 		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
-		IsMatch:    false,
 	},
 	{
-		Expression: "@silence_author=!!xxx",
+		Expression: "@link_count=~1",
 		IsValid:    false,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1"},
+	},
+	{
+		Expression: "@label_count<2",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev"}},
 		IsMatch:    false,
 	},
-
 	{
-		Expression: "@age<1h",
+		Expression: "@label_count=2",
 		IsValid:    true,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@age>1h",
+		Expression: "@label_count=2",
 		IsValid:    true,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
-		IsMatch:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev", "instance": "server1", "job": "node", "severity": "critical", "team": "ops"}},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@age<-1h",
+		Expression: "@label_count!=2",
 		IsValid:    true,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Foo", "cluster": "dev", "instance": "server1", "job": "node", "severity": "critical", "team": "ops"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@age>-1h",
+		Expression: "@label_count=0",
 		IsValid:    true,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
+		Alert:      models.Alert{Labels: map[string]string{}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@age=1h",
-		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		Expression: "@label_count>0",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{}},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@age=~1h",
+		Expression: "@label_count=-1",
 		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
-		IsMatch:    false,
 	},
 	{
-		Expression: "@age>",
+		Expression: "@label_count=abc",
 		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
-		IsMatch:    false,
 	},
 	{
-		Expression: "@age<",
+		Expression: "@label_count=~1",
 		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+	},
+	{
+		Expression: "@fingerprint=aae7a1432b5d2f1b",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@fingerprint=aae7a1432b5d2f1b",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@age>a",
-		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		Expression: "@fingerprint=aae7a1432b5d2f1b",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: ""},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@age<10v",
-		IsValid:    false,
-		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		Expression: "@fingerprint=aae7",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    false,
 	},
-
 	{
-		Expression: "node=vps1",
+		Expression: "@fingerprint!=aae7a1432b5d2f1b",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "node=vps1",
+		Expression: "@fingerprint!=aae7a1432b5d2f1b",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    false,
 	},
 	{
-		Expression: "node!=vps1",
+		Expression: "@fingerprint!=aae7a1432b5d2f1b",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		Alert:      models.Alert{Fingerprint: ""},
 		IsMatch:    false,
 	},
 	{
-		Expression: "node!=vps1",
+		Expression: "@fingerprint=~^aae7",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps2"}},
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "node=~vps",
+		Expression: "@fingerprint=~^aae7",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
-		IsMatch:    true,
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
+		IsMatch:    false,
 	},
 	{
-		Expression: "node!~vps",
+		Expression: "@fingerprint=~^aae7",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		Alert:      models.Alert{Fingerprint: ""},
 		IsMatch:    false,
 	},
 	{
-		Expression: "node!~abc",
+		Expression: "@fingerprint!~^aae7",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		Alert:      models.Alert{Fingerprint: "54c2f185e49cfccb"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "node!~",
-		IsValid:    false,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		Expression: "@fingerprint!~^aae7",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    false,
 	},
 	{
-		Expression: "node=",
+		Expression: "@fingerprint>aae7",
 		IsValid:    false,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
-		IsMatch:    false,
 	},
 	{
-		Expression: "node===",
-		IsValid:    false,
-		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
-		IsMatch:    false,
+		Expression: "@fingerprint_in=aae7a1432b5d2f1b",
+		IsValid:    true,
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
+		IsMatch:    true,
 	},
-
 	{
-		Expression: "abc",
+		Expression: "@fingerprint_in=54c2f185e49cfccb,aae7a1432b5d2f1b",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"key": "abc"}},
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@fingerprint_in=54c2f185e49cfccb, aae7a1432b5d2f1b,",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"key": "XXXabcx"}},
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@fingerprint_in=54c2f185e49cfccb,aae7",
 		IsValid:    true,
-		Alert:      models.Alert{Labels: map[string]string{"abc": "xxxab"}},
+		Alert:      models.Alert{Fingerprint: "aae7a1432b5d2f1b"},
 		IsMatch:    false,
 	},
 	{
-		Expression: "abc",
+		Expression: "@fingerprint_in=54c2f185e49cfccb",
 		IsValid:    true,
-		Alert: models.Alert{
-			Annotations: models.Annotations{
-				models.Annotation{Name: "key", Value: "abc"},
-			},
-		},
-		IsMatch: true,
+		Alert:      models.Alert{Fingerprint: ""},
+		IsMatch:    false,
 	},
 	{
-		Expression: "abc",
-		IsValid:    true,
-		Alert: models.Alert{
-			Annotations: models.Annotations{
-				models.Annotation{Name: "key", Value: "ccc abc"},
-			},
-		},
-		IsMatch: true,
+		Expression: "@fingerprint_in=",
+		IsValid:    false,
 	},
 	{
-		Expression: "abc",
+		Expression: "@fingerprint_in=,,",
+		IsValid:    false,
+	},
+	{
+		Expression: "@fingerprint_in!=aae7a1432b5d2f1b",
+		IsValid:    false,
+	},
+	{
+		Expression: "@group=4a5c5b8e4e51e0eb",
 		IsValid:    true,
-		Alert: models.Alert{
-			Annotations: models.Annotations{
-				models.Annotation{Name: "abc", Value: "zzz"},
-			},
-		},
-		IsMatch: false,
+		Alert:      models.Alert{GroupID: "4a5c5b8e4e51e0eb"},
+		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@group=4a5c5b8e4e51e0eb",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", Comment: "abc"},
+		Alert:      models.Alert{GroupID: "0f3b1952d4c0a3a7"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@group=4a5c5b8e4e51e0eb",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@group!=4a5c5b8e4e51e0eb",
+		IsValid:    true,
+		Alert:      models.Alert{GroupID: "0f3b1952d4c0a3a7"},
 		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@group=~4a5c",
+		IsValid:    false,
+	},
+	{
+		Expression: "@any_label=~web.*",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", Comment: "abcxxx"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "instance": "web1"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@any_label=~web.*",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", Comment: "ABCD"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "job": "webserver"}},
 		IsMatch:    true,
 	},
 	{
-		Expression: "abc",
+		Expression: "@any_label=~^Fake$",
 		IsValid:    true,
-		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
-		Silence:    models.Silence{ID: "1", Comment: "xzc"},
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "instance": "web1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@any_label=~web.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "instance": "db1"}},
 		IsMatch:    false,
 	},
 	{
-		Expression: "abc",
+		Expression: "@any_label=~web.*",
 		IsValid:    true,
 		Alert:      models.Alert{},
 		IsMatch:    false,
 	},
 	{
-		Expression: "^abb[****].*****",
-		IsValid:    false,
+		Expression: "@any_label!~web.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "instance": "db1"}},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@silenced=true",
-		IsValid:    false,
+		Expression: "@any_label!~web.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "instance": "web1"}},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@silenced!=false",
+		Expression: "@any_label=~web(",
 		IsValid:    false,
 	},
 	{
-		Expression: "@silenced=~false",
+		Expression: "@any_label=web1",
 		IsValid:    false,
 	},
 	{
-		Expression: "@inhibited=true",
-		IsValid:    false,
+		Expression: "@label_missing=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake"}},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@inhibited!=false",
+		Expression: "@label_missing=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "team": "ops"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@label_missing=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "team": ""}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@label_missing=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@label_missing!=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake", "team": "ops"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@label_missing!=team",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"alertname": "Fake"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@label_missing=",
 		IsValid:    false,
 	},
 	{
-		Expression: "@inhibited=~false",
+		Expression: "@label_missing=~team",
 		IsValid:    false,
 	},
 	{
-		Expression: "@alertmanager=test",
+		Expression: "@flapping=true",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: true},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@alertmanager=abc",
+		Expression: "@flapping=true",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: false},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@alertmanager=~tes",
+		Expression: "@flapping=false",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: false},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@alertmanager=~000",
+		Expression: "@flapping=false",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: true},
 		IsMatch:    false,
 	},
 	{
-		Expression: "@alertmanager!=tes",
+		Expression: "@flapping!=true",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: false},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@alertmanager!~abc",
+		Expression: "@flapping!=false",
 		IsValid:    true,
-		Alert:      models.Alert{},
+		Alert:      models.Alert{Flapping: true},
 		IsMatch:    true,
 	},
 	{
-		Expression: "@receiver=by-name",
-		IsValid:    true,
-		Alert: models.Alert{
-			Receiver: "by-name",
-		},
-		IsMatch: true,
+		Expression: "@flapping=yes",
+		IsValid:    false,
 	},
 	{
-		Expression: "@receiver=by-name",
-		IsValid:    true,
-		Alert: models.Alert{
-			Receiver: "by-not-name",
-		},
-		IsMatch: false,
+		Expression: "@flapping>true",
+		IsValid:    false,
 	},
 	{
-		Expression: "@receiver=~name",
+		Expression: "@inconsistent=true",
 		IsValid:    true,
-		Alert: models.Alert{
-			Receiver: "by-not-name",
-		},
-		IsMatch: true,
+		Alert:      models.Alert{Inconsistent: true},
+		IsMatch:    true,
 	},
-}
-
-func TestFilters(t *testing.T) {
-	log.SetLevel(log.ErrorLevel)
-
-	am, err := alertmanager.NewAlertmanager("test", "http://localhost", alertmanager.WithRequestTimeout(time.Second))
-	if err != nil {
-		t.Error(err)
-	}
-	for _, ft := range tests {
-		alert := models.Alert(ft.Alert)
-		alert.Alertmanager = []models.AlertmanagerInstance{
-			{
-				Name:       am.Name,
-				Silences:   map[string]*models.Silence{},
-				SilencedBy: []string{},
-			},
-		}
-		if ft.Silence.ID != "" {
-			alert.Alertmanager[0].Silences[ft.Silence.ID] = &ft.Silence
-			alert.Alertmanager[0].SilencedBy = append(alert.Alertmanager[0].SilencedBy, ft.Silence.ID)
-		}
-
-		f := filters.NewFilter(ft.Expression)
-		if f == nil {
-			t.Errorf("[%s] No filter found", ft.Expression)
-		}
-		if f.GetHits() != 0 {
-			t.Errorf("[%s] Hits = %#v after init(), expected 0", ft.Expression, f.GetHits())
-		}
-		if f.GetIsValid() != ft.IsValid {
-			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
-		}
-		if f.GetIsValid() {
-			m := f.Match(&alert, 0)
-			if m != ft.IsMatch {
-				j, _ := json.Marshal(ft.Alert)
-				s, _ := json.Marshal(ft.Silence)
-				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s\nsilence used: %s", ft.Expression, m, ft.IsMatch, j, s)
-			}
-			if ft.IsMatch && f.GetHits() != 1 {
-				t.Errorf("[%s] GetHits() returned %#v after match, expected 1", ft.Expression, f.GetHits())
-			}
-			if !ft.IsMatch && f.GetHits() != 0 {
-				t.Errorf("[%s] GetHits() returned %#v after non-match, expected 0", ft.Expression, f.GetHits())
-			}
-			if f.GetRawText() != ft.Expression {
-				t.Errorf("[%s] GetRawText() returned %#v != %s passed as the expression", ft.Expression, f.GetRawText(), ft.Expression)
-			}
-		}
-	}
-}
-
-type limitFilterTest struct {
-	Expression string
-	IsValid    bool
-	IsMatch    []bool
-	Hits       int
-}
-
-var limitTests = []limitFilterTest{
 	{
-		Expression: "@limit=3",
+		Expression: "@inconsistent=true",
 		IsValid:    true,
-		IsMatch:    []bool{true, true, true},
-		Hits:       0,
+		Alert:      models.Alert{Inconsistent: false},
+		IsMatch:    false,
 	},
 	{
-		Expression: "@limit=1",
+		Expression: "@inconsistent=false",
 		IsValid:    true,
-		IsMatch:    []bool{true, false, false},
-		Hits:       2,
+		Alert:      models.Alert{Inconsistent: false},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@limit=5",
+		Expression: "@inconsistent!=false",
 		IsValid:    true,
-		IsMatch:    []bool{true, true, true, true, true, false, false, false},
-		Hits:       3,
+		Alert:      models.Alert{Inconsistent: true},
+		IsMatch:    true,
 	},
 	{
-		Expression: "@limit=0",
+		Expression: "@inconsistent=maybe",
 		IsValid:    false,
 	},
 	{
-		Expression: "@limit=abc",
+		Expression: "@inconsistent>true",
 		IsValid:    false,
 	},
 	{
-		Expression: "@limit==0",
+		Expression: "@cluster_disagreement=true",
+		IsValid:    true,
+		Alert:      models.Alert{ClusterDisagreement: true},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@cluster_disagreement=true",
+		IsValid:    true,
+		Alert:      models.Alert{ClusterDisagreement: false},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@cluster_disagreement=true",
+		IsValid:    true,
+		Alert:      models.Alert{Inconsistent: true},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@cluster_disagreement=false",
+		IsValid:    true,
+		Alert:      models.Alert{ClusterDisagreement: false},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@cluster_disagreement!=true",
+		IsValid:    true,
+		Alert:      models.Alert{ClusterDisagreement: false},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@cluster_disagreement!=false",
+		IsValid:    true,
+		Alert:      models.Alert{ClusterDisagreement: true},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@cluster_disagreement=yes",
 		IsValid:    false,
 	},
 	{
-		Expression: "@limit>0",
+		Expression: "@acked=true",
+		IsValid:    true,
+		Alert:      models.Alert{Acknowledged: true},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@acked=true",
+		IsValid:    true,
+		Alert:      models.Alert{Acknowledged: false},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@acked=false",
+		IsValid:    true,
+		Alert:      models.Alert{Acknowledged: false},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@acked!=true",
+		IsValid:    true,
+		Alert:      models.Alert{Acknowledged: true},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@acked=yes",
 		IsValid:    false,
 	},
 	{
-		Expression: "@limit<0",
+		Expression: "@acked>true",
 		IsValid:    false,
 	},
-}
-
-func TestLimitFilter(t *testing.T) {
-	for _, ft := range limitTests {
-		f := filters.NewFilter(ft.Expression)
-		if f == nil {
-			t.Errorf("[%s] No filter found", ft.Expression)
-		}
-		if f.GetHits() != 0 {
-			t.Errorf("[%s] Hits = %#v after init(), expected 0", ft.Expression, f.GetHits())
-		}
-		if f.GetIsValid() != ft.IsValid {
-			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
-		}
-		if f.GetIsValid() {
-			alert := models.Alert{}
-			var index int
-			for _, isMatch := range ft.IsMatch {
-				m := f.Match(&alert, index)
-				if m != isMatch {
-					t.Errorf("[%s] Match() returned %#v while %#v was expected, index %d", ft.Expression, m, isMatch, index)
-				}
-				if f.GetRawText() != ft.Expression {
-					t.Errorf("[%s] GetRawText() returned %#v != %s passed as the expression", ft.Expression, f.GetRawText(), ft.Expression)
-				}
-				index++
+	{
+		Expression: "@silence_ends_in<1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Minute * 30)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_ends_in<1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Hour * 2)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in>1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Hour * 2)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_ends_in>1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Minute * 30)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in<1d",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Hour * 12)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_ends_in<1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", EndsAt: time.Now().Add(time.Minute * -5)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in<1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in>1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in<1h",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_ends_in=1h",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silence_ends_in<-1h",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silence_ends_in<foo",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 12, 30, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 9, 0, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 17, 0, 0, 0, time.UTC)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 8, 59, 0, 0, time.UTC)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 12, 30, 0, 0, time.FixedZone("UTC+10", 10*60*60))},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between!=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 12, 30, 0, 0, time.UTC)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between!=09:00-17:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 20, 0, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 23, 15, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 5, 59, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 6, 0, 0, 0, time.UTC)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@started_between!=22:00-06:00",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@started_between=09:00-09:00",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between=09:00",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between=9-17",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between=09:00-25:00",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between=09:00-17:00-18:00",
+		IsValid:    false,
+	},
+	{
+		Expression: "@started_between>09:00-17:00",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=xx",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=:xx",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state==xx",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=~true",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=~false",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=suppressed",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"999"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@state=suppressed",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@state!=suppressed",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", InhibitedBy: []string{"999"}},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active", SilencedBy: []string{"abcdef"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "unprocessed"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "unprocessed", SilencedBy: []string{"abcdef"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_id!=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id!=abcdef",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_id=~cde",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"abcdef"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_id!~abc",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"zwd"}},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "@silence_jira=1",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "1"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_jira=2",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_jira!=3",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "x"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_jira!=4",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "4"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_jira!=5",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_jira=~abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "xxabcxx"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_jira=~abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_jira=~",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_jira~=",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_jira~=1",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", JiraID: "xxx"},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "@silence_comment=~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "Maintenance, see JIRA-1234"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_comment=~jira-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "Maintenance, see JIRA-1234"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_comment=~JIRA-[0-9]+",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "JIRA-99"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_comment=~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "JIRA-99"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_comment=~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_comment!~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "JIRA-99"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_comment!~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "JIRA-1234"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_comment!~JIRA-1234",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_comment=~(",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silence_comment=JIRA-1234",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silence_comment!=JIRA-1234",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silence_author=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author!=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "bob"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author!=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author!=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author!=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author!=john",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author=~jo",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", CreatedBy: "john"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@silence_author=~",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author===x",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@silence_author=!!xxx",
+		IsValid:    false,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1"},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "@age<1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age>1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age<-1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age>-1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age=1h",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age=~1h",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age>",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age<",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age>a",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@first_seen_age>1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now(), KarmaFirstSeen: time.Now().Add(time.Hour * -2)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@first_seen_age<1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now(), KarmaFirstSeen: time.Now().Add(time.Hour * -2)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@first_seen_age<1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2), KarmaFirstSeen: time.Now().Add(time.Minute * -5)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@first_seen_age>2d",
+		IsValid:    true,
+		Alert:      models.Alert{KarmaFirstSeen: time.Now().Add(time.Hour * -72)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@first_seen_age<1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now()},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@first_seen_age>1h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@first_seen_age=1h",
+		IsValid:    false,
+	},
+	{
+		Expression: "@first_seen_age>a",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state_changed<5m",
+		IsValid:    true,
+		Alert:      models.Alert{StateChangedAt: time.Now().Add(time.Minute * -2)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@state_changed<5m",
+		IsValid:    true,
+		Alert:      models.Alert{StateChangedAt: time.Now().Add(time.Minute * -10)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@state_changed>5m",
+		IsValid:    true,
+		Alert:      models.Alert{StateChangedAt: time.Now().Add(time.Minute * -10)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@state_changed>5m",
+		IsValid:    true,
+		Alert:      models.Alert{StateChangedAt: time.Now().Add(time.Minute * -2)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@state_changed<5m",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now()},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@state_changed>5m",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -2)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@state_changed=5m",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state_changed<a",
+		IsValid:    false,
+	},
+	{
+		Expression: "@age>2d",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -72)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age<2d",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -72)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age<1d12h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -30)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age>1d12h",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -30)},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@age>-1d",
+		IsValid:    true,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Hour * -30)},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@age>d",
+		IsValid:    false,
+	},
+	{
+		Expression: "@age>1d-1h",
+		IsValid:    false,
+	},
+	{
+		Expression: "@age>1dxx",
+		IsValid:    false,
+	},
+	{
+		Expression: "@age<10v",
+		IsValid:    false,
+		Alert:      models.Alert{StartsAt: time.Now().Add(time.Minute * -55)},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "node=vps1",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "node=vps1",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node!=vps1",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node!=vps1",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps2"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "Critical"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=i=CRITICAL",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "critical"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "warning"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity=i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity=critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "Critical"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity!i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "CriTicaL"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity!i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "warning"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity!i=critical",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=i=",
+		IsValid:    false,
+	},
+	{
+		Expression: "@state=i=active",
+		IsValid:    false,
+	},
+	{
+		Expression: "severity=inactive",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "inactive"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "node=~vps",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "node!~vps",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node!~abc",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "node!~[",
+		IsValid:    false,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node=~[",
+		IsValid:    false,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity!~warn.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "warning"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity!~warn.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "critical"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity!~warn.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=~warn.*",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node!~",
+		IsValid:    false,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node=",
+		IsValid:    false,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "node===",
+		IsValid:    false,
+		Alert:      models.Alert{Labels: map[string]string{"node": "vps1"}},
+		IsMatch:    false,
+	},
+
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"key": "abc"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"key": "XXXabcx"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"abc": "xxxab"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "key", Value: "abc"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "key", Value: "ccc abc"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{
+				models.Annotation{Name: "abc", Value: "zzz"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "abc"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "abcxxx"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "ABCD"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{State: "suppressed", SilencedBy: []string{"1"}},
+		Silence:    models.Silence{ID: "1", Comment: "xzc"},
+		IsMatch:    false,
+	},
+	{
+		Expression: "abc",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "^abb[****].*****",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silenced=true",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silenced!=false",
+		IsValid:    false,
+	},
+	{
+		Expression: "@silenced=~false",
+		IsValid:    false,
+	},
+	{
+		Expression: "@inhibited=true",
+		IsValid:    false,
+	},
+	{
+		Expression: "@inhibited!=false",
+		IsValid:    false,
+	},
+	{
+		Expression: "@inhibited=~false",
+		IsValid:    false,
+	},
+	{
+		Expression: "@alertmanager=test",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@alertmanager=abc",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@alertmanager=~tes",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@alertmanager=~000",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@alertmanager!=tes",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@alertmanager!~abc",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@receiver=by-name",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "by-name",
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@receiver=by-name",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "by-not-name",
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@receiver=~name",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "by-not-name",
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@receiver!=by-name",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "by-not-name",
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@receiver!~name",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "by-not-name",
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@receiver=~.*",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@receiver!=by-name",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@receiver!~name",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@receiver=pagerduty OR @receiver=slack",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "slack",
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@receiver=pagerduty OR @receiver=slack",
+		IsValid:    true,
+		Alert: models.Alert{
+			Receiver: "email",
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_exists=runbook_url",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "runbook_url", Value: "http://localhost"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_exists=runbook_url",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "runbook_url", Value: ""}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_exists=runbook_url",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "runbook_url"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_exists!=runbook_url",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_exists!=runbook_url",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "runbook_url", Value: "http://localhost"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_exists=~runbook",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_exists=",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation=summary:foo",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation=summary:foo",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo bar"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation=summary:foo:bar",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo:bar"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation!=summary:foo",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation!=summary:foo",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@annotation=summary:",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: ""}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation=summary:",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@annotation=~summary:fo+",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "a foo b"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation!~summary:fo+",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "a foo b"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation=~summary:",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: ""}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation=~summary:",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@annotation=~summary:",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation!~summary:",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "foo"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation=~summary:a:b",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "summary", Value: "a:b"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation=~:foo",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation=summary",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation=:foo",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation=",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation>summary:1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num=value>90",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "95.5"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value>90",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "90"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_num=value>=90",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "90"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value<0.5",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "0.25"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value<=-1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "-1"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value=1e3",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "1000"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value!=10",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "11"}},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@annotation_num=value!=10",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "high"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_num=value>90",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "value", Value: "very high"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_num=value<90",
+		IsValid:    true,
+		Alert: models.Alert{
+			Annotations: models.Annotations{{Name: "threshold", Value: "80"}},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@annotation_num=value<90",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@annotation_num=value",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num=value>",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num=value>abc",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num=value=>90",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num=>90",
+		IsValid:    false,
+	},
+	{
+		Expression: "@annotation_num>value>90",
+		IsValid:    false,
+	},
+	{
+		Expression: "@cluster_count=0",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    true,
+	},
+	{
+		Expression: "@cluster_count=~1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@cluster_count>-1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@cluster_count>a",
+		IsValid:    false,
+	},
+	{
+		Expression: "severity=critical OR severity=warning",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "warning"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=critical OR severity=warning",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"severity": "info"}},
+		IsMatch:    false,
+	},
+	{
+		Expression: "severity=critical OR @state=active OR job=node",
+		IsValid:    true,
+		Alert:      models.Alert{State: "active"},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=critical OR critical",
+		IsValid:    true,
+		Alert:      models.Alert{Labels: map[string]string{"level": "critical"}},
+		IsMatch:    true,
+	},
+	{
+		Expression: "severity=critical OR severity=",
+		IsValid:    false,
+	},
+	{
+		Expression: "severity=critical OR foo===bar",
+		IsValid:    false,
+	},
+	{
+		Expression: "severity=critical OR ",
+		IsValid:    false,
+	},
+	{
+		Expression: "severity=critical OR @limit=5",
+		IsValid:    false,
+	},
+}
+
+func TestFilters(t *testing.T) {
+	log.SetLevel(log.ErrorLevel)
+
+	am, err := alertmanager.NewAlertmanager("test", "http://localhost", alertmanager.WithRequestTimeout(time.Second))
+	if err != nil {
+		t.Error(err)
+	}
+	for _, ft := range tests {
+		alert := models.Alert(ft.Alert)
+		alert.Alertmanager = []models.AlertmanagerInstance{
+			{
+				Name:       am.Name,
+				Silences:   map[string]*models.Silence{},
+				SilencedBy: []string{},
+			},
+		}
+		if ft.Silence.ID != "" {
+			alert.Alertmanager[0].Silences[ft.Silence.ID] = &ft.Silence
+			alert.Alertmanager[0].SilencedBy = append(alert.Alertmanager[0].SilencedBy, ft.Silence.ID)
+		}
+
+		f := filters.NewFilter(ft.Expression)
+		if f == nil {
+			t.Errorf("[%s] No filter found", ft.Expression)
+		}
+		if f.GetHits() != 0 {
+			t.Errorf("[%s] Hits = %#v after init(), expected 0", ft.Expression, f.GetHits())
+		}
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			m := f.Match(&alert, 0)
+			if m != ft.IsMatch {
+				j, _ := json.Marshal(ft.Alert)
+				s, _ := json.Marshal(ft.Silence)
+				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s\nsilence used: %s", ft.Expression, m, ft.IsMatch, j, s)
+			}
+			if ft.IsMatch && f.GetHits() != 1 {
+				t.Errorf("[%s] GetHits() returned %#v after match, expected 1", ft.Expression, f.GetHits())
+			}
+			if !ft.IsMatch && f.GetHits() != 0 {
+				t.Errorf("[%s] GetHits() returned %#v after non-match, expected 0", ft.Expression, f.GetHits())
+			}
+			if f.GetRawText() != ft.Expression {
+				t.Errorf("[%s] GetRawText() returned %#v != %s passed as the expression", ft.Expression, f.GetRawText(), ft.Expression)
+			}
+		}
+	}
+}
+
+type limitFilterTest struct {
+	Expression string
+	IsValid    bool
+	IsMatch    []bool
+	Hits       int
+}
+
+var limitTests = []limitFilterTest{
+	{
+		Expression: "@limit=3",
+		IsValid:    true,
+		IsMatch:    []bool{true, true, true},
+		Hits:       0,
+	},
+	{
+		Expression: "@limit=1",
+		IsValid:    true,
+		IsMatch:    []bool{true, false, false},
+		Hits:       2,
+	},
+	{
+		Expression: "@limit=5",
+		IsValid:    true,
+		IsMatch:    []bool{true, true, true, true, true, false, false, false},
+		Hits:       3,
+	},
+	{
+		Expression: "@limit=0",
+		IsValid:    false,
+	},
+	{
+		Expression: "@limit=abc",
+		IsValid:    false,
+	},
+	{
+		Expression: "@limit==0",
+		IsValid:    false,
+	},
+	{
+		Expression: "@limit>0",
+		IsValid:    false,
+	},
+	{
+		Expression: "@limit<0",
+		IsValid:    false,
+	},
+}
+
+func TestLimitFilter(t *testing.T) {
+	for _, ft := range limitTests {
+		f := filters.NewFilter(ft.Expression)
+		if f == nil {
+			t.Errorf("[%s] No filter found", ft.Expression)
+		}
+		if f.GetHits() != 0 {
+			t.Errorf("[%s] Hits = %#v after init(), expected 0", ft.Expression, f.GetHits())
+		}
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			alert := models.Alert{}
+			var index int
+			for _, isMatch := range ft.IsMatch {
+				m := f.Match(&alert, index)
+				if m != isMatch {
+					t.Errorf("[%s] Match() returned %#v while %#v was expected, index %d", ft.Expression, m, isMatch, index)
+				}
+				if f.GetRawText() != ft.Expression {
+					t.Errorf("[%s] GetRawText() returned %#v != %s passed as the expression", ft.Expression, f.GetRawText(), ft.Expression)
+				}
+				index++
+			}
+			if f.GetHits() != ft.Hits {
+				t.Errorf("[%s] GetHits() returned %#v hits, expected %d", ft.Expression, f.GetHits(), ft.Hits)
+			}
+		}
+	}
+}
+
+var clusterCountTests = []filterTest{
+	{
+		Expression: "@cluster_count>1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@cluster_count>1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+				{Name: "am3", Cluster: "am3"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@cluster_count=1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@cluster_count!=1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@cluster_count<2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@cluster_count>=2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "am2"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@cluster_count>=2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@cluster_count<=1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@cluster_count<=1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "am2"},
+			},
+		},
+		IsMatch: false,
+	},
+}
+
+var alertmanagerTests = []filterTest{
+	{
+		Expression: "@alertmanager=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@alertmanager=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am2", Cluster: "ha"},
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@alertmanager=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am2", Cluster: "ha"},
+				{Name: "am3", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@alertmanager!=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@alertmanager!=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@alertmanager!=am1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am2", Cluster: "ha"},
+				{Name: "am3", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@alertmanager=~am",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "prod", Cluster: "prod"},
+				{Name: "am2", Cluster: "ha"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@alertmanager!~am",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "prod", Cluster: "prod"},
+				{Name: "am2", Cluster: "ha"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@alertmanager!~am",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "prod", Cluster: "prod"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@alertmanager!=am1",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+}
+
+var sourceHostTests = []filterTest{
+	{
+		Expression: "@source_host=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom1:9090/graph?g0.expr=up"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom2:9090/graph?g0.expr=up"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "https://prom1/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=prom1.example.com",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom1.example.com:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=~prom-.*",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom-eu-1:9090/graph"},
+				{Name: "am2", Source: "http://other:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=~^prom-",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://other:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom1:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom2:9090/graph"},
+				{Name: "am2", Source: "http://prom1:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://prom2:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host!~prom",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://other:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=10.0.0.1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://10.0.0.1:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=::1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://[::1]:9090/graph"},
+			},
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			GeneratorURL: "http://prom1:9090/graph",
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@source_host=prom1",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert:      models.Alert{},
+		IsMatch:    false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: ""},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host=~.*",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://%zz:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host!=prom1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "http://%zz:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host=~.*",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "prom1:9090/graph"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host=~.*",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Source: "/graph?g0.expr=up"},
+			},
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@source_host>prom1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@source_host=",
+		IsValid:    false,
+	},
+}
+
+var sourcesTests = []filterTest{
+	{
+		Expression: "@sources>1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "am1"},
+			},
+			Sources: 1,
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@sources>1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "am1"},
+				{Name: "am2", Cluster: "am2"},
+			},
+			Sources: 2,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources>=2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+			Sources: 2,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources>=3",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+			Sources: 2,
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@sources<=2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+			Sources: 2,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources<=1",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+			},
+			Sources: 2,
+		},
+		IsMatch: false,
+	},
+	{
+		Expression: "@sources<2",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "am1"},
+			},
+			Sources: 1,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources=3",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am2", Cluster: "ha"},
+				{Name: "am3", Cluster: "am3"},
+			},
+			Sources: 3,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources!=3",
+		IsValid:    true,
+		Alert: models.Alert{
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am1", Cluster: "ha"},
+				{Name: "am1", Cluster: "ha"},
+			},
+			Sources: 1,
+		},
+		IsMatch: true,
+	},
+	{
+		Expression: "@sources=~1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@sources>a",
+		IsValid:    false,
+	},
+	{
+		Expression: "@sources>=-1",
+		IsValid:    false,
+	},
+	{
+		Expression: "@sources=>1",
+		IsValid:    false,
+	},
+}
+
+func TestAlertmanagerFilter(t *testing.T) {
+	for _, ft := range alertmanagerTests {
+		alert := models.Alert(ft.Alert)
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			m := f.Match(&alert, 0)
+			if m != ft.IsMatch {
+				j, _ := json.Marshal(ft.Alert)
+				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s", ft.Expression, m, ft.IsMatch, j)
 			}
-			if f.GetHits() != ft.Hits {
-				t.Errorf("[%s] GetHits() returned %#v hits, expected %d", ft.Expression, f.GetHits(), ft.Hits)
+		}
+	}
+}
+
+func TestSourceHostFilter(t *testing.T) {
+	for _, ft := range sourceHostTests {
+		alert := models.Alert(ft.Alert)
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			m := f.Match(&alert, 0)
+			if m != ft.IsMatch {
+				j, _ := json.Marshal(ft.Alert)
+				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s", ft.Expression, m, ft.IsMatch, j)
+			}
+		}
+	}
+}
+
+func TestSourcesFilter(t *testing.T) {
+	for _, ft := range sourcesTests {
+		alert := models.Alert(ft.Alert)
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			m := f.Match(&alert, 0)
+			if m != ft.IsMatch {
+				j, _ := json.Marshal(ft.Alert)
+				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s", ft.Expression, m, ft.IsMatch, j)
+			}
+		}
+	}
+}
+
+func TestClusterCountFilter(t *testing.T) {
+	for _, ft := range clusterCountTests {
+		alert := models.Alert(ft.Alert)
+		f := filters.NewFilter(ft.Expression)
+		if f.GetIsValid() != ft.IsValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", ft.Expression, f.GetIsValid(), ft.IsValid)
+		}
+		if f.GetIsValid() {
+			m := f.Match(&alert, 0)
+			if m != ft.IsMatch {
+				j, _ := json.Marshal(ft.Alert)
+				t.Errorf("[%s] Match() returned %#v while %#v was expected\nalert used: %s", ft.Expression, m, ft.IsMatch, j)
 			}
 		}
 	}
 }
+
+var invalidReasonTests = []struct {
+	Expression string
+	Reason     string
+}{
+	{Expression: "@state=active", Reason: ""},
+	{Expression: "foo=bar", Reason: ""},
+	{Expression: "@state=", Reason: "missing value"},
+	{Expression: "@state=foo", Reason: "unknown state 'foo', valid states: unprocessed, active, suppressed"},
+	{Expression: "@age<1x", Reason: "invalid duration '1x'"},
+	{Expression: "@limit=0", Reason: "value must be a positive integer"},
+	{Expression: "@limit=abc", Reason: "value must be a positive integer"},
+	{Expression: "@cluster_count=-1", Reason: "value must be a non-negative integer"},
+	{Expression: "@sources=abc", Reason: "value must be a non-negative integer"},
+	{Expression: "@annotation=foo", Reason: "value must be in 'name:value' format"},
+	{Expression: "@annotation_num=foo", Reason: "value must be in 'name<operator>number' format"},
+	{Expression: "@annotation_num=foo>bar", Reason: "invalid number 'bar'"},
+	{Expression: "@annotation=~summary:(", Reason: "invalid regex: error parsing regexp: missing closing ): `(`"},
+	{Expression: "(", Reason: "invalid regex: error parsing regexp: missing closing ): `(`"},
+	{Expression: "foo=", Reason: "missing value"},
+	{Expression: "@state=>active", Reason: "unknown operator '=>'"},
+	{Expression: "@state>active", Reason: "operator '>' is not supported by '@state' filter"},
+	{Expression: "foo=~(", Reason: "invalid regex: error parsing regexp: missing closing ): `(`"},
+	{Expression: "@foo=bar", Reason: "unknown filter '@foo'"},
+	{Expression: "@silence_ends_in<-1h", Reason: "duration '-1h' must not be negative"},
+	{Expression: "@started_between=09:00", Reason: "invalid window '09:00', expected HH:MM-HH:MM"},
+	{Expression: "@started_between=09:00-5pm", Reason: "invalid time '5pm' in window '09:00-5pm'"},
+	{Expression: "@started_between=09:00-09:00", Reason: "invalid window '09:00-09:00', start and end must be different"},
+	{Expression: "@state=active OR @foo=bar", Reason: "invalid alternative '@foo=bar': unknown filter '@foo'"},
+	{Expression: "@state=active OR @limit=5", Reason: "@limit can't be used as an alternative"},
+}
+
+func TestInvalidReason(t *testing.T) {
+	for _, rt := range invalidReasonTests {
+		f := filters.NewFilter(rt.Expression)
+		if f.GetIsValid() != (rt.Reason == "") {
+			t.Errorf("[%s] GetIsValid() returned %v", rt.Expression, f.GetIsValid())
+		}
+		if f.GetInvalidReason() != rt.Reason {
+			t.Errorf("[%s] GetInvalidReason() returned %q while %q was expected", rt.Expression, f.GetInvalidReason(), rt.Reason)
+		}
+	}
+}
+
+func TestFilterGetValue(t *testing.T) {
+	for expression, value := range map[string]string{
+		"foo=bar":                     "bar",
+		"@limit=5":                    "5",
+		"@sources>=2":                 "2",
+		"@inhibited=true":             "true",
+		"@inhibited!=0":               "false",
+		"@started_between=9:05-17:00": "09:05-17:00",
+	} {
+		f := filters.NewFilter(expression)
+		if f.GetValue() != value {
+			t.Errorf("[%s] GetValue() returned %q while %q was expected", expression, f.GetValue(), value)
+		}
+	}
+}
+
+func TestExpressionCount(t *testing.T) {
+	for expression, count := range map[string]int{
+		"":                                    0,
+		" ":                                   0,
+		"foo=bar":                             1,
+		"@state=foo":                          1,
+		"foo=bar OR bar=foo":                  2,
+		"foo=bar OR bar=foo OR @state=active": 3,
+		"foo=bar OR ":                         1,
+	} {
+		f := filters.NewFilter(expression)
+		if filters.ExpressionCount(f) != count {
+			t.Errorf("[%s] ExpressionCount() returned %d while %d was expected", expression, filters.ExpressionCount(f), count)
+		}
+	}
+}
+
+func TestStartedBetweenTimezone(t *testing.T) {
+	defer func() {
+		config.Config.Filters.Timezone = ""
+	}()
+
+	// 01:00 UTC is 10:00 in Tokyo
+	alert := models.Alert{StartsAt: time.Date(2019, 1, 1, 1, 0, 0, 0, time.UTC)}
+	for _, testCase := range []struct {
+		timezone string
+		isMatch  bool
+	}{
+		{timezone: "", isMatch: false},
+		{timezone: "UTC", isMatch: false},
+		{timezone: "Asia/Tokyo", isMatch: true},
+	} {
+		config.Config.Filters.Timezone = testCase.timezone
+		f := filters.NewFilter("@started_between=09:00-17:00")
+		if m := f.Match(&alert, 0); m != testCase.isMatch {
+			t.Errorf("[%s] Match() returned %#v while %#v was expected", testCase.timezone, m, testCase.isMatch)
+		}
+	}
+}
+
+func TestSilenceEndsInFilterMultipleSilences(t *testing.T) {
+	now := time.Now()
+	newSilence := func(id string, endsIn time.Duration) *models.Silence {
+		return &models.Silence{ID: id, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(endsIn)}
+	}
+
+	for _, testCase := range []struct {
+		expression string
+		instances  []models.AlertmanagerInstance
+		silencedBy []string
+		isMatch    bool
+	}{
+		{
+			// soonest ending silence ends in 30m
+			expression: "@silence_ends_in<1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{
+					"1": newSilence("1", time.Hour*4),
+					"2": newSilence("2", time.Minute*30),
+				}},
+			},
+			silencedBy: []string{"1", "2"},
+			isMatch:    true,
+		},
+		{
+			expression: "@silence_ends_in>1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{
+					"1": newSilence("1", time.Hour*4),
+					"2": newSilence("2", time.Minute*30),
+				}},
+			},
+			silencedBy: []string{"1", "2"},
+			isMatch:    false,
+		},
+		{
+			// silences from different Alertmanager instances
+			expression: "@silence_ends_in<1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{"1": newSilence("1", time.Hour*4)}},
+				{Name: "am2", Silences: map[string]*models.Silence{"2": newSilence("2", time.Minute*10)}},
+			},
+			silencedBy: []string{"1", "2"},
+			isMatch:    true,
+		},
+		{
+			// expired silences are ignored
+			expression: "@silence_ends_in<1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{
+					"1": newSilence("1", time.Hour*4),
+					"2": newSilence("2", -time.Minute),
+				}},
+			},
+			silencedBy: []string{"1", "2"},
+			isMatch:    false,
+		},
+		{
+			expression: "@silence_ends_in>1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{
+					"1": newSilence("1", time.Hour*4),
+					"2": newSilence("2", -time.Minute),
+				}},
+			},
+			silencedBy: []string{"1", "2"},
+			isMatch:    true,
+		},
+		{
+			// silences that don't silence this alert are ignored
+			expression: "@silence_ends_in<1h",
+			instances: []models.AlertmanagerInstance{
+				{Name: "am1", Silences: map[string]*models.Silence{
+					"1": newSilence("1", time.Hour*4),
+					"2": newSilence("2", time.Minute*30),
+				}},
+			},
+			silencedBy: []string{"1"},
+			isMatch:    false,
+		},
+	} {
+		alert := models.Alert{
+			State:        models.AlertStateSuppressed,
+			SilencedBy:   testCase.silencedBy,
+			Alertmanager: testCase.instances,
+		}
+		f := filters.NewFilter(testCase.expression)
+		if !f.GetIsValid() {
+			t.Errorf("[%s] GetIsValid() returned false", testCase.expression)
+			continue
+		}
+		if m := f.Match(&alert, 0); m != testCase.isMatch {
+			t.Errorf("[%s] Match() returned %#v while %#v was expected for silences %v", testCase.expression, m, testCase.isMatch, testCase.silencedBy)
+		}
+	}
+}
+
+func TestSilenceCountFilter(t *testing.T) {
+	now := time.Now()
+	newSilence := func(id string, startsIn, endsIn time.Duration) *models.Silence {
+		return &models.Silence{ID: id, StartsAt: now.Add(startsIn), EndsAt: now.Add(endsIn)}
+	}
+	noSilences := []models.AlertmanagerInstance{
+		{Name: "am1", Silences: map[string]*models.Silence{}, SilencedBy: []string{}},
+	}
+	oneSilence := []models.AlertmanagerInstance{
+		{Name: "am1", Silences: map[string]*models.Silence{
+			"1": newSilence("1", -time.Hour, time.Hour),
+		}, SilencedBy: []string{"1"}},
+	}
+	threeSilences := []models.AlertmanagerInstance{
+		{Name: "am1", Silences: map[string]*models.Silence{
+			"1": newSilence("1", -time.Hour, time.Hour),
+			"2": newSilence("2", -time.Hour, time.Minute),
+		}, SilencedBy: []string{"1", "2"}},
+		{Name: "am2", Silences: map[string]*models.Silence{
+			"3": newSilence("3", -time.Minute, time.Hour),
+		}, SilencedBy: []string{"3"}},
+	}
+	// silence replicated between cluster members
+	replicatedSilence := []models.AlertmanagerInstance{
+		{Name: "am1", Cluster: "ha", Silences: map[string]*models.Silence{
+			"1": newSilence("1", -time.Hour, time.Hour),
+		}, SilencedBy: []string{"1"}},
+		{Name: "am2", Cluster: "ha", Silences: map[string]*models.Silence{
+			"1": newSilence("1", -time.Hour, time.Hour),
+		}, SilencedBy: []string{"1"}},
+	}
+	inactiveSilences := []models.AlertmanagerInstance{
+		{Name: "am1", Silences: map[string]*models.Silence{
+			"1": newSilence("1", -time.Hour, time.Hour),
+			// expired
+			"2": newSilence("2", -time.Hour, -time.Minute),
+			// not started yet
+			"3": newSilence("3", time.Minute, time.Hour),
+			// doesn't silence this alert
+			"4": newSilence("4", -time.Hour, time.Hour),
+		}, SilencedBy: []string{"1", "2", "3", "5"}},
+	}
+
+	for _, testCase := range []struct {
+		expression string
+		instances  []models.AlertmanagerInstance
+		isMatch    bool
+	}{
+		{expression: "@silence_count=0", instances: noSilences, isMatch: true},
+		{expression: "@silence_count=0", instances: oneSilence, isMatch: false},
+		{expression: "@silence_count>0", instances: noSilences, isMatch: false},
+		{expression: "@silence_count=1", instances: oneSilence, isMatch: true},
+		{expression: "@silence_count!=1", instances: oneSilence, isMatch: false},
+		{expression: "@silence_count>1", instances: oneSilence, isMatch: false},
+		{expression: "@silence_count>1", instances: threeSilences, isMatch: true},
+		{expression: "@silence_count=3", instances: threeSilences, isMatch: true},
+		{expression: "@silence_count>=3", instances: threeSilences, isMatch: true},
+		{expression: "@silence_count<3", instances: threeSilences, isMatch: false},
+		{expression: "@silence_count<=3", instances: threeSilences, isMatch: true},
+		{expression: "@silence_count!=3", instances: threeSilences, isMatch: false},
+		{expression: "@silence_count=1", instances: replicatedSilence, isMatch: true},
+		{expression: "@silence_count>1", instances: replicatedSilence, isMatch: false},
+		{expression: "@silence_count=1", instances: inactiveSilences, isMatch: true},
+		{expression: "@silence_count>1", instances: inactiveSilences, isMatch: false},
+	} {
+		alert := models.Alert{Alertmanager: testCase.instances}
+		f := filters.NewFilter(testCase.expression)
+		if !f.GetIsValid() {
+			t.Errorf("[%s] GetIsValid() returned false", testCase.expression)
+			continue
+		}
+		if m := f.Match(&alert, 0); m != testCase.isMatch {
+			t.Errorf("[%s] Match() returned %#v while %#v was expected", testCase.expression, m, testCase.isMatch)
+		}
+	}
+
+	for _, expression := range []string{"@silence_count=a", "@silence_count>-1", "@silence_count=~1", "@silence_count="} {
+		if f := filters.NewFilter(expression); f.GetIsValid() {
+			t.Errorf("[%s] GetIsValid() returned true", expression)
+		}
+	}
+}
+
+func TestSeverityAtLeastFilter(t *testing.T) {
+	defer func() {
+		config.Config.Filters.Severity.Label = ""
+		config.Config.Filters.Severity.Order = nil
+	}()
+	config.Config.Filters.Severity.Label = "severity"
+	config.Config.Filters.Severity.Order = []string{"info", "warning", "critical"}
+
+	for _, testCase := range []struct {
+		expression string
+		labels     map[string]string
+		isValid    bool
+		isMatch    bool
+	}{
+		{expression: "@severity_at_least=info", labels: map[string]string{"severity": "info"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=info", labels: map[string]string{"severity": "critical"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=warning", labels: map[string]string{"severity": "info"}, isValid: true, isMatch: false},
+		{expression: "@severity_at_least=warning", labels: map[string]string{"severity": "warning"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=warning", labels: map[string]string{"severity": "critical"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=critical", labels: map[string]string{"severity": "warning"}, isValid: true, isMatch: false},
+		{expression: "@severity_at_least=critical", labels: map[string]string{"severity": "critical"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=Warning", labels: map[string]string{"severity": "CRITICAL"}, isValid: true, isMatch: true},
+		{expression: "@severity_at_least=info", labels: map[string]string{"severity": "page"}, isValid: true, isMatch: false},
+		{expression: "@severity_at_least=info", labels: map[string]string{"alertname": "Fake"}, isValid: true, isMatch: false},
+		{expression: "@severity_at_least=page", isValid: false},
+		{expression: "@severity_at_least=", isValid: false},
+		{expression: "@severity_at_least!=warning", isValid: false},
+		{expression: "@severity_at_least>warning", isValid: false},
+	} {
+		f := filters.NewFilter(testCase.expression)
+		if f.GetIsValid() != testCase.isValid {
+			t.Errorf("[%s] GetIsValid() returned %#v while %#v was expected", testCase.expression, f.GetIsValid(), testCase.isValid)
+			continue
+		}
+		if !testCase.isValid {
+			continue
+		}
+		alert := models.Alert{Labels: testCase.labels}
+		if m := f.Match(&alert, 0); m != testCase.isMatch {
+			t.Errorf("[%s] Match() on %v returned %#v while %#v was expected", testCase.expression, testCase.labels, m, testCase.isMatch)
+		}
+	}
+}
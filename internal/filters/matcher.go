@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	cache "github.com/patrickmn/go-cache"
@@ -43,6 +44,22 @@ func (matcher *notEqualMatcher) Compare(valA, valB interface{}) bool {
 	return valA != valB
 }
 
+type caseInsensitiveEqualMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *caseInsensitiveEqualMatcher) Compare(valA, valB interface{}) bool {
+	return strings.EqualFold(fmt.Sprint(valA), fmt.Sprint(valB))
+}
+
+type caseInsensitiveNotEqualMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *caseInsensitiveNotEqualMatcher) Compare(valA, valB interface{}) bool {
+	return !strings.EqualFold(fmt.Sprint(valA), fmt.Sprint(valB))
+}
+
 type moreThanMatcher struct {
 	abstractMatcher
 }
@@ -91,6 +108,54 @@ func (matcher *lessThanMatcher) Compare(valA, valB interface{}) bool {
 	return string(valA.(string)) < string(valB.(string))
 }
 
+type moreThanOrEqualMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *moreThanOrEqualMatcher) Compare(valA, valB interface{}) bool {
+	if valA == nil || valA == "" || valB == nil || valB == "" {
+		return false
+	}
+
+	if intA, ok := valA.(int); ok {
+		if intB, ok := valB.(int); ok {
+			return intA >= intB
+		}
+	}
+
+	if atoiA, err := strconv.Atoi(valA.(string)); err == nil {
+		if atoiB, err := strconv.Atoi(valB.(string)); err == nil {
+			return atoiA >= atoiB
+		}
+	}
+
+	return string(valA.(string)) >= string(valB.(string))
+}
+
+type lessThanOrEqualMatcher struct {
+	abstractMatcher
+}
+
+func (matcher *lessThanOrEqualMatcher) Compare(valA, valB interface{}) bool {
+	if valA == nil || valA == "" || valB == nil || valB == "" {
+		return false
+	}
+
+	if intA, ok := valA.(int); ok {
+		if intB, ok := valB.(int); ok {
+			return intA <= intB
+		}
+	}
+
+	if atoiA, err := strconv.Atoi(valA.(string)); err == nil {
+		if atoiB, err := strconv.Atoi(valB.(string)); err == nil {
+			return atoiA <= atoiB
+		}
+	}
+
+	return string(valA.(string)) <= string(valB.(string))
+}
+
 type regexpMatcher struct {
 	abstractMatcher
 }
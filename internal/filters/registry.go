@@ -3,30 +3,40 @@ package filters
 import "regexp"
 
 const (
-	equalOperator         string = "="
-	notEqualOperator      string = "!="
-	moreThanOperator      string = ">"
-	lessThanOperator      string = "<"
-	regexpOperator        string = "=~"
-	negativeRegexOperator string = "!~"
+	equalOperator                   string = "="
+	notEqualOperator                string = "!="
+	caseInsensitiveEqualOperator    string = "=i="
+	caseInsensitiveNotEqualOperator string = "!i="
+	moreThanOperator                string = ">"
+	lessThanOperator                string = "<"
+	moreThanOrEqOperator            string = ">="
+	lessThanOrEqOperator            string = "<="
+	regexpOperator                  string = "=~"
+	negativeRegexOperator           string = "!~"
 )
 
 // this needs to be hand crafted because any of the supported operator chars
 // should be considered part of the operator expression
 // this is needed to catch errors in operators, for example:
 // a===b should yield an error
-var matcherRegex = "[=!<>~]+"
+// case insensitive operators contain a letter so they need to be listed
+// explicitly before the generic pattern
+var matcherRegex = "=i=|!i=|[=!<>~]+"
 
 // same as matcherRegex but for the filter name part
 var filterRegex = "^(@)?[a-zA-Z_][a-zA-Z0-9_]*"
 
 var matcherConfig = map[string]matcherT{
-	equalOperator:         &equalMatcher{abstractMatcher{Operator: equalOperator}},
-	notEqualOperator:      &notEqualMatcher{abstractMatcher{Operator: notEqualOperator}},
-	moreThanOperator:      &moreThanMatcher{abstractMatcher{Operator: moreThanOperator}},
-	lessThanOperator:      &lessThanMatcher{abstractMatcher{Operator: lessThanOperator}},
-	regexpOperator:        &regexpMatcher{abstractMatcher{Operator: regexpOperator}},
-	negativeRegexOperator: &negativeRegexMatcher{abstractMatcher{Operator: negativeRegexOperator}},
+	equalOperator:                   &equalMatcher{abstractMatcher{Operator: equalOperator}},
+	notEqualOperator:                &notEqualMatcher{abstractMatcher{Operator: notEqualOperator}},
+	caseInsensitiveEqualOperator:    &caseInsensitiveEqualMatcher{abstractMatcher{Operator: caseInsensitiveEqualOperator}},
+	caseInsensitiveNotEqualOperator: &caseInsensitiveNotEqualMatcher{abstractMatcher{Operator: caseInsensitiveNotEqualOperator}},
+	moreThanOperator:                &moreThanMatcher{abstractMatcher{Operator: moreThanOperator}},
+	lessThanOperator:                &lessThanMatcher{abstractMatcher{Operator: lessThanOperator}},
+	moreThanOrEqOperator:            &moreThanOrEqualMatcher{abstractMatcher{Operator: moreThanOrEqOperator}},
+	lessThanOrEqOperator:            &lessThanOrEqualMatcher{abstractMatcher{Operator: lessThanOrEqOperator}},
+	regexpOperator:                  &regexpMatcher{abstractMatcher{Operator: regexpOperator}},
+	negativeRegexOperator:           &negativeRegexMatcher{abstractMatcher{Operator: negativeRegexOperator}},
 }
 
 type filterConfig struct {
@@ -35,6 +45,9 @@ type filterConfig struct {
 	SupportedOperators []string
 	Factory            newFilterFactory
 	Autocomplete       autocompleteFactory
+	// set for filters matching on data merged from all upstreams, autocomplete
+	// hints for those can only be generated from deduplicated alerts
+	Deduplicated bool
 }
 
 // AllFilters contains the mapping of all filters along with operators they
@@ -47,6 +60,13 @@ var AllFilters = []filterConfig{
 		Factory:            newAlertmanagerInstanceFilter,
 		Autocomplete:       alertmanagerInstanceAutocomplete,
 	},
+	{
+		Label:              "@source_host",
+		LabelRe:            regexp.MustCompile("^@source_host$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		Factory:            newSourceHostFilter,
+		Autocomplete:       sourceHostAutocomplete,
+	},
 	{
 		Label:              "@state",
 		LabelRe:            regexp.MustCompile("^@state$"),
@@ -54,6 +74,13 @@ var AllFilters = []filterConfig{
 		Factory:            newStateFilter,
 		Autocomplete:       stateAutocomplete,
 	},
+	{
+		Label:              "@inhibited",
+		LabelRe:            regexp.MustCompile("^@inhibited$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newInhibitedFilter,
+		Autocomplete:       inhibitedAutocomplete,
+	},
 	{
 		Label:              "@receiver",
 		LabelRe:            regexp.MustCompile("^@receiver$"),
@@ -68,6 +95,27 @@ var AllFilters = []filterConfig{
 		Factory:            newAgeFilter,
 		Autocomplete:       ageAutocomplete,
 	},
+	{
+		Label:              "@first_seen_age",
+		LabelRe:            regexp.MustCompile("^@first_seen_age$"),
+		SupportedOperators: []string{lessThanOperator, moreThanOperator},
+		Factory:            newFirstSeenAgeFilter,
+		Autocomplete:       ageAutocomplete,
+	},
+	{
+		Label:              "@state_changed",
+		LabelRe:            regexp.MustCompile("^@state_changed$"),
+		SupportedOperators: []string{lessThanOperator, moreThanOperator},
+		Factory:            newStateChangedFilter,
+		Autocomplete:       ageAutocomplete,
+	},
+	{
+		Label:              "@started_between",
+		LabelRe:            regexp.MustCompile("^@started_between$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newStartedBetweenFilter,
+		Autocomplete:       startedBetweenAutocomplete,
+	},
 	{
 		Label:              "@silence_id",
 		LabelRe:            regexp.MustCompile("^@silence_id$"),
@@ -82,6 +130,20 @@ var AllFilters = []filterConfig{
 		Factory:            newSilenceJiraFilter,
 		Autocomplete:       silenceJiraIDAutocomplete,
 	},
+	{
+		Label:              "@silence_ends_in",
+		LabelRe:            regexp.MustCompile("^@silence_ends_in$"),
+		SupportedOperators: []string{lessThanOperator, moreThanOperator},
+		Factory:            newSilenceEndsInFilter,
+		Autocomplete:       silenceEndsInAutocomplete,
+	},
+	{
+		Label:              "@silence_count",
+		LabelRe:            regexp.MustCompile("^@silence_count$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, moreThanOperator, lessThanOperator, moreThanOrEqOperator, lessThanOrEqOperator},
+		Factory:            newSilenceCountFilter,
+		Autocomplete:       silenceCountAutocomplete,
+	},
 	{
 		Label:              "@silence_author",
 		LabelRe:            regexp.MustCompile("^@silence_author$"),
@@ -89,6 +151,134 @@ var AllFilters = []filterConfig{
 		Factory:            newSilenceAuthorFilter,
 		Autocomplete:       silenceAuthorAutocomplete,
 	},
+	{
+		Label:              "@silence_comment",
+		LabelRe:            regexp.MustCompile("^@silence_comment$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator},
+		Factory:            newSilenceCommentFilter,
+		Autocomplete:       silenceCommentAutocomplete,
+	},
+	{
+		Label:              "@annotation_exists",
+		LabelRe:            regexp.MustCompile("^@annotation_exists$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newAnnotationExistsFilter,
+		Autocomplete:       annotationExistsAutocomplete,
+	},
+	{
+		Label:              "@annotation",
+		LabelRe:            regexp.MustCompile("^@annotation$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator},
+		Factory:            newAnnotationFilter,
+		Autocomplete:       annotationAutocomplete,
+	},
+	{
+		Label:              "@annotation_num",
+		LabelRe:            regexp.MustCompile("^@annotation_num$"),
+		SupportedOperators: []string{equalOperator},
+		Factory:            newAnnotationNumFilter,
+		Autocomplete:       annotationNumAutocomplete,
+	},
+	{
+		Label:              "@link_count",
+		LabelRe:            regexp.MustCompile("^@link_count$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, moreThanOperator, lessThanOperator, moreThanOrEqOperator, lessThanOrEqOperator},
+		Factory:            newLinkCountFilter,
+		Autocomplete:       linkCountAutocomplete,
+	},
+	{
+		Label:              "@cluster_count",
+		LabelRe:            regexp.MustCompile("^@cluster_count$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, moreThanOperator, lessThanOperator, moreThanOrEqOperator, lessThanOrEqOperator},
+		Factory:            newClusterCountFilter,
+		Autocomplete:       clusterCountAutocomplete,
+		Deduplicated:       true,
+	},
+	{
+		Label:              "@sources",
+		LabelRe:            regexp.MustCompile("^@sources$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, moreThanOperator, lessThanOperator, moreThanOrEqOperator, lessThanOrEqOperator},
+		Factory:            newSourcesFilter,
+		Autocomplete:       sourcesAutocomplete,
+		Deduplicated:       true,
+	},
+	{
+		Label:              "@flapping",
+		LabelRe:            regexp.MustCompile("^@flapping$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newFlappingFilter,
+		Autocomplete:       flappingAutocomplete,
+	},
+	{
+		Label:              "@acked",
+		LabelRe:            regexp.MustCompile("^@acked$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newAckedFilter,
+		Autocomplete:       ackedAutocomplete,
+	},
+	{
+		Label:              "@inconsistent",
+		LabelRe:            regexp.MustCompile("^@inconsistent$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newInconsistentFilter,
+		Autocomplete:       inconsistentAutocomplete,
+	},
+	{
+		Label:              "@cluster_disagreement",
+		LabelRe:            regexp.MustCompile("^@cluster_disagreement$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newClusterDisagreementFilter,
+		Autocomplete:       clusterDisagreementAutocomplete,
+	},
+	{
+		Label:              "@label_count",
+		LabelRe:            regexp.MustCompile("^@label_count$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, moreThanOperator, lessThanOperator, moreThanOrEqOperator, lessThanOrEqOperator},
+		Factory:            newLabelCountFilter,
+		Autocomplete:       labelCountAutocomplete,
+	},
+	{
+		Label:              "@label_missing",
+		LabelRe:            regexp.MustCompile("^@label_missing$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newLabelMissingFilter,
+		Autocomplete:       labelMissingAutocomplete,
+	},
+	{
+		Label:              "@any_label",
+		LabelRe:            regexp.MustCompile("^@any_label$"),
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator},
+		Factory:            newAnyLabelFilter,
+		Autocomplete:       anyLabelAutocomplete,
+	},
+	{
+		Label:              "@severity_at_least",
+		LabelRe:            regexp.MustCompile("^@severity_at_least$"),
+		SupportedOperators: []string{equalOperator},
+		Factory:            newSeverityAtLeastFilter,
+		Autocomplete:       severityAtLeastAutocomplete,
+	},
+	{
+		Label:              "@fingerprint",
+		LabelRe:            regexp.MustCompile("^@fingerprint$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator, regexpOperator, negativeRegexOperator},
+		Factory:            newFingerprintFilter,
+		Autocomplete:       fingerprintAutocomplete,
+	},
+	{
+		Label:              "@fingerprint_in",
+		LabelRe:            regexp.MustCompile("^@fingerprint_in$"),
+		SupportedOperators: []string{equalOperator},
+		Factory:            newFingerprintInFilter,
+		Autocomplete:       fingerprintAutocomplete,
+	},
+	{
+		Label:              "@group",
+		LabelRe:            regexp.MustCompile("^@group$"),
+		SupportedOperators: []string{equalOperator, notEqualOperator},
+		Factory:            newGroupFilter,
+		Autocomplete:       groupAutocomplete,
+	},
 	{
 		Label:              "@limit",
 		LabelRe:            regexp.MustCompile("^@limit$"),
@@ -99,7 +289,7 @@ var AllFilters = []filterConfig{
 	{
 		Label:              "[a-zA-Z_][a-zA-Z0-9_]*",
 		LabelRe:            regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$"),
-		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, lessThanOperator, moreThanOperator},
+		SupportedOperators: []string{regexpOperator, negativeRegexOperator, equalOperator, notEqualOperator, caseInsensitiveEqualOperator, caseInsensitiveNotEqualOperator, lessThanOperator, moreThanOperator},
 		Factory:            newLabelFilter,
 		Autocomplete:       labelAutocomplete,
 	},
@@ -0,0 +1,16 @@
+package mapper
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// AlertFingerprint returns the same fingerprint that Alertmanager uses to
+// identify an alert with given labels, it's used for API versions that
+// don't return fingerprints
+func AlertFingerprint(labels map[string]string) string {
+	ls := model.LabelSet{}
+	for name, value := range labels {
+		ls[model.LabelName(name)] = model.LabelValue(value)
+	}
+	return ls.Fingerprint().String()
+}
@@ -0,0 +1,31 @@
+package mapper_test
+
+import (
+	"testing"
+
+	"github.com/prymitive/karma/internal/mapper"
+)
+
+func TestAlertFingerprint(t *testing.T) {
+	for _, testCase := range []struct {
+		labels      map[string]string
+		fingerprint string
+	}{
+		{labels: map[string]string{}, fingerprint: "cbf29ce484222325"},
+		{labels: map[string]string{"alertname": "foo"}, fingerprint: "c5a7cb176f0f418a"},
+		{
+			labels: map[string]string{
+				"alertname": "Free_Disk_Space_Too_Low",
+				"cluster":   "staging",
+				"disk":      "sda",
+				"instance":  "server5",
+				"job":       "node_exporter",
+			},
+			fingerprint: "aae7a1432b5d2f1b",
+		},
+	} {
+		if fp := mapper.AlertFingerprint(testCase.labels); fp != testCase.fingerprint {
+			t.Errorf("AlertFingerprint(%v) returned %s, expected %s", testCase.labels, fp, testCase.fingerprint)
+		}
+	}
+}
@@ -84,6 +84,7 @@ func (s StatusMapper) Decode(source io.ReadCloser) (models.AlertmanagerStatus, e
 	status.Version = resp.Data.VersionInfo.Version
 
 	status.ID = resp.Data.ClusterStatus.Name
+	status.ClusterStatus = resp.Data.ClusterStatus.Status
 	for _, peer := range resp.Data.ClusterStatus.Peers {
 		status.PeerIDs = append(status.PeerIDs, peer.Name)
 	}
@@ -14,6 +14,7 @@ import (
 	"github.com/prymitive/karma/internal/mapper/v017/client/alertgroup"
 	"github.com/prymitive/karma/internal/mapper/v017/client/general"
 	"github.com/prymitive/karma/internal/mapper/v017/client/silence"
+	v2models "github.com/prymitive/karma/internal/mapper/v017/models"
 	"github.com/prymitive/karma/internal/models"
 )
 
@@ -40,13 +41,17 @@ func newClient(uri string, headers map[string]string, httpTransport http.RoundTr
 
 // Alerts will fetch all alert groups from the API
 func groups(c *client.Alertmanager, timeout time.Duration) ([]models.AlertGroup, error) {
-	ret := []models.AlertGroup{}
-
 	groups, err := c.Alertgroup.GetAlertGroups(alertgroup.NewGetAlertGroupsParamsWithTimeout(timeout))
 	if err != nil {
 		return []models.AlertGroup{}, err
 	}
-	for _, group := range groups.Payload {
+	return mapGroups(groups.Payload), nil
+}
+
+// mapGroups converts v2 API alert groups into karma models
+func mapGroups(payload v2models.AlertGroups) []models.AlertGroup {
+	ret := []models.AlertGroup{}
+	for _, group := range payload {
 		g := models.AlertGroup{
 			Receiver: *group.Receiver.Name,
 			Labels:   group.Labels,
@@ -57,10 +62,12 @@ func groups(c *client.Alertmanager, timeout time.Duration) ([]models.AlertGroup,
 				Annotations:  models.AnnotationsFromMap(alert.Annotations),
 				Labels:       alert.Labels,
 				StartsAt:     time.Time(*alert.StartsAt),
+				EndsAt:       time.Time(*alert.EndsAt),
 				GeneratorURL: alert.GeneratorURL.String(),
 				State:        *alert.Status.State,
 				InhibitedBy:  alert.Status.InhibitedBy,
 				SilencedBy:   alert.Status.SilencedBy,
+				Fingerprint:  *alert.Fingerprint,
 			}
 			sort.Strings(a.InhibitedBy)
 			sort.Strings(a.SilencedBy)
@@ -69,19 +76,21 @@ func groups(c *client.Alertmanager, timeout time.Duration) ([]models.AlertGroup,
 		}
 		ret = append(ret, g)
 	}
-
-	return ret, nil
+	return ret
 }
 
 func silences(c *client.Alertmanager, timeout time.Duration) ([]models.Silence, error) {
-	ret := []models.Silence{}
-
 	silences, err := c.Silence.GetSilences(silence.NewGetSilencesParamsWithTimeout(timeout))
 	if err != nil {
-		return ret, err
+		return []models.Silence{}, err
 	}
+	return mapSilences(silences.Payload), nil
+}
 
-	for _, s := range silences.Payload {
+// mapSilences converts v2 API silences into karma models
+func mapSilences(payload v2models.GettableSilences) []models.Silence {
+	ret := []models.Silence{}
+	for _, s := range payload {
 		us := models.Silence{
 			ID:        *s.ID,
 			StartsAt:  time.Time(*s.StartsAt),
@@ -99,8 +108,7 @@ func silences(c *client.Alertmanager, timeout time.Duration) ([]models.Silence,
 		}
 		ret = append(ret, us)
 	}
-
-	return ret, nil
+	return ret
 }
 
 func status(c *client.Alertmanager, timeout time.Duration) (models.AlertmanagerStatus, error) {
@@ -113,6 +121,9 @@ func status(c *client.Alertmanager, timeout time.Duration) (models.AlertmanagerS
 
 	ret.Version = *status.Payload.VersionInfo.Version
 	ret.ID = status.Payload.Cluster.Name
+	if status.Payload.Cluster.Status != nil {
+		ret.ClusterStatus = *status.Payload.Cluster.Status
+	}
 	for _, p := range status.Payload.Cluster.Peers {
 		ret.PeerIDs = append(ret.PeerIDs, *p.Name)
 	}
@@ -0,0 +1,43 @@
+package v017
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/strfmt"
+
+	v2models "github.com/prymitive/karma/internal/mapper/v017/models"
+	"github.com/prymitive/karma/internal/models"
+)
+
+// StaticVersion is the version reported for upstreams reading from a static
+// file instead of an Alertmanager instance
+const StaticVersion = "static"
+
+// staticData is the format of static files, alert groups and silences use the
+// same schema as responses from the v2 API
+type staticData struct {
+	Alerts   v2models.AlertGroups      `json:"alerts"`
+	Silences v2models.GettableSilences `json:"silences"`
+}
+
+// DecodeStatic reads alert groups and silences from a static file
+func DecodeStatic(source io.Reader) ([]models.AlertGroup, []models.Silence, error) {
+	data := staticData{}
+	err := json.NewDecoder(source).Decode(&data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// all required fields must be set before mapping, v2 models use pointers
+	err = data.Alerts.Validate(strfmt.Default)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = data.Silences.Validate(strfmt.Default)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mapGroups(data.Alerts), mapSilences(data.Silences), nil
+}
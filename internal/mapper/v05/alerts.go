@@ -21,6 +21,7 @@ type alert struct {
 	Annotations  map[string]string `json:"annotations"`
 	Labels       map[string]string `json:"labels"`
 	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
 	GeneratorURL string            `json:"generatorURL"`
 	Inhibited    bool              `json:"inhibited"`
 	Silenced     string            `json:"silenced"`
@@ -119,10 +120,12 @@ func (m AlertMapper) Decode(source io.ReadCloser) ([]models.AlertGroup, error) {
 					Annotations:  models.AnnotationsFromMap(a.Annotations),
 					Labels:       a.Labels,
 					StartsAt:     a.StartsAt,
+					EndsAt:       a.EndsAt,
 					GeneratorURL: a.GeneratorURL,
 					State:        status,
 					InhibitedBy:  inhibitedBy,
 					SilencedBy:   silencedBy,
+					Fingerprint:  mapper.AlertFingerprint(a.Labels),
 				}
 				sort.Strings(a.InhibitedBy)
 				sort.Strings(a.SilencedBy)
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// AcknowledgementRequest is the body of a request acknowledging an alert with
+// given fingerprint
+type AcknowledgementRequest struct {
+	Fingerprint string `json:"fingerprint"`
+	CreatedBy   string `json:"createdBy"`
+}
+
+// Acknowledgement marks an alert as being handled by someone without creating
+// a silence for it, it's only kept in karma memory until it expires
+type Acknowledgement struct {
+	Fingerprint string    `json:"fingerprint"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
@@ -34,15 +34,48 @@ type Alert struct {
 	Labels      map[string]string `json:"labels"`
 	StartsAt    time.Time         `json:"startsAt"`
 	State       string            `json:"state"`
+	// fingerprint generated by Alertmanager from original alert labels, it's
+	// exposed so that UI can reference alerts when acknowledging them or
+	// filtering with @fingerprint_in
+	Fingerprint string `json:"fingerprint" hash:"-"`
 	// those are not exposed in JSON, Alertmanager specific value will be in kept
 	// in the Alertmanager slice
 	// skip those when generating alert fingerprint too
-	GeneratorURL string   `json:"-" hash:"-"`
-	SilencedBy   []string `json:"-" hash:"-"`
-	InhibitedBy  []string `json:"-" hash:"-"`
+	GeneratorURL string `json:"-" hash:"-"`
+	// Alertmanager keeps updating endsAt for firing alerts, alert is resolved
+	// once endsAt is in the past
+	EndsAt      time.Time `json:"-" hash:"-"`
+	SilencedBy  []string  `json:"-" hash:"-"`
+	InhibitedBy []string  `json:"-" hash:"-"`
+	// ID of the alert group this alert belongs to
+	GroupID string `json:"-" hash:"-"`
+	// labels as collected from Alertmanager, only set if labels were modified
+	// so that alerts with different labels can be merged
+	SourceLabels map[string]string `json:"-" hash:"-"`
+	// number of distinct Alertmanager upstream URIs this alert was collected
+	// from, it's only set on deduplicated alerts
+	Sources int `json:"-" hash:"-"`
 	// karma fields
 	Alertmanager []AlertmanagerInstance `json:"alertmanager"`
 	Receiver     string                 `json:"receiver"`
+	// true if alert changed state too many times recently
+	Flapping bool `json:"flapping"`
+	// when karma first collected this alert, it's not reset when Alertmanager
+	// resets StartsAt
+	KarmaFirstSeen time.Time `json:"karmaFirstSeen" hash:"-"`
+	// when karma last recorded a state change for this alert, it's zero if
+	// there was no change since karma started collecting it
+	StateChangedAt time.Time `json:"-" hash:"-"`
+	// true if someone acknowledged this alert in karma, acknowledgements are
+	// only stored by karma and are not sent to Alertmanager
+	Acknowledged bool `json:"acknowledged"`
+	// true if Alertmanager instances this alert was collected from reported it
+	// with different label sets, which is only possible when some labels are
+	// normalized or ignored for deduplication
+	Inconsistent bool `json:"inconsistent"`
+	// true if this alert is missing from some healthy members of the cluster
+	// it was collected from
+	ClusterDisagreement bool `json:"clusterDisagreement"`
 	// fingerprints are precomputed for speed
 	labelsFP  string `hash:"-"`
 	contentFP string `hash:"-"`
@@ -72,6 +105,11 @@ func (a *Alert) IsSilenced() bool {
 	return (a.State == AlertStateSuppressed && len(a.SilencedBy) > 0)
 }
 
+// IsResolved will return true if alert endsAt is set and it's before now
+func (a *Alert) IsResolved(now time.Time) bool {
+	return !a.EndsAt.IsZero() && a.EndsAt.Before(now)
+}
+
 // IsInhibited will return true if alert should be considered silenced
 func (a *Alert) IsInhibited() bool {
 	return (a.State == AlertStateSuppressed && len(a.InhibitedBy) > 0)
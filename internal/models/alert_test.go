@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -37,6 +38,25 @@ var alertStateTests = []alertStateTest{
 	},
 }
 
+func TestAlertIsResolved(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, testCase := range []struct {
+		endsAt   time.Time
+		resolved bool
+	}{
+		{endsAt: time.Time{}, resolved: false},
+		{endsAt: now.Add(time.Minute), resolved: false},
+		{endsAt: now, resolved: false},
+		{endsAt: now.Add(-time.Minute), resolved: true},
+	} {
+		alert := models.Alert{EndsAt: testCase.endsAt}
+		if alert.IsResolved(now) != testCase.resolved {
+			t.Errorf("alert.IsResolved() returned %t while %t was expected for endsAt=%s",
+				alert.IsResolved(now), testCase.resolved, testCase.endsAt)
+		}
+	}
+}
+
 func TestAlertState(t *testing.T) {
 	for _, testCase := range alertStateTests {
 		if testCase.alert.IsActive() != testCase.isActive {
@@ -54,6 +74,26 @@ func TestAlertState(t *testing.T) {
 	}
 }
 
+func TestAlertFingerprintJSON(t *testing.T) {
+	alert := models.Alert{
+		Labels:      map[string]string{"alertname": "Foo"},
+		State:       models.AlertStateActive,
+		Fingerprint: "aae7a1432b5d2f1b",
+	}
+	data, err := json.Marshal(alert)
+	if err != nil {
+		t.Fatalf("Failed to marshal alert: %s", err)
+	}
+
+	decoded := models.Alert{}
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal alert: %s", err)
+	}
+	if decoded.Fingerprint != alert.Fingerprint {
+		t.Errorf("Got fingerprint '%s' after JSON round-trip, expected '%s' in %s", decoded.Fingerprint, alert.Fingerprint, data)
+	}
+}
+
 func BenchmarkLabelsFingerprint(b *testing.B) {
 	alert := models.Alert{
 		Labels: map[string]string{
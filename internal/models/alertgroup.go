@@ -45,6 +45,8 @@ type AlertGroup struct {
 	AlertmanagerCount map[string]int    `json:"alertmanagerCount"`
 	StateCount        map[string]int    `json:"stateCount"`
 	LatestStartsAt    time.Time         `json:"-"`
+	LatestFirstSeen   time.Time         `json:"-"`
+	EarliestStartsAt  time.Time         `json:"-"`
 }
 
 // LabelsFingerprint is a checksum of this AlertGroup labels and the receiver
@@ -77,6 +79,28 @@ func (ag AlertGroup) ContentFingerprint() string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+// IsFlapping returns true if any alert in this group is flapping
+func (ag AlertGroup) IsFlapping() bool {
+	for _, alert := range ag.Alerts {
+		if alert.Flapping {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLatestFirstSeen returns the most recent first seen timestamp of all
+// alerts in this group
+func (ag AlertGroup) FindLatestFirstSeen() time.Time {
+	var ts time.Time
+	for i, alert := range ag.Alerts {
+		if i == 0 || alert.KarmaFirstSeen.After(ts) {
+			ts = alert.KarmaFirstSeen
+		}
+	}
+	return ts
+}
+
 func (ag AlertGroup) FindLatestStartsAt() time.Time {
 	var ts time.Time
 	for i, alert := range ag.Alerts {
@@ -86,3 +110,15 @@ func (ag AlertGroup) FindLatestStartsAt() time.Time {
 	}
 	return ts
 }
+
+// FindEarliestStartsAt returns the oldest start timestamp of all alerts in
+// this group
+func (ag AlertGroup) FindEarliestStartsAt() time.Time {
+	var ts time.Time
+	for i, alert := range ag.Alerts {
+		if i == 0 || alert.StartsAt.Before(ts) {
+			ts = alert.StartsAt
+		}
+	}
+	return ts
+}
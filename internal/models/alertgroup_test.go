@@ -239,3 +239,72 @@ func TestFindLatestStartsAt(t *testing.T) {
 		}
 	}
 }
+
+func TestFindEarliestStartsAt(t *testing.T) {
+	for _, testCase := range []struct {
+		alerts   models.AlertList
+		startsAt time.Time
+	}{
+		{alerts: models.AlertList{}, startsAt: time.Time{}},
+		{
+			alerts: models.AlertList{
+				{StartsAt: time.Date(2017, time.January, 10, 0, 0, 0, 5, time.UTC)},
+				{StartsAt: time.Date(2017, time.January, 10, 0, 0, 0, 1, time.UTC)},
+				{StartsAt: time.Date(2017, time.January, 10, 0, 0, 0, 8, time.UTC)},
+			},
+			startsAt: time.Date(2017, time.January, 10, 0, 0, 0, 1, time.UTC),
+		},
+		{
+			alerts: models.AlertList{
+				{StartsAt: time.Date(2017, time.January, 10, 0, 0, 0, 3, time.UTC)},
+			},
+			startsAt: time.Date(2017, time.January, 10, 0, 0, 0, 3, time.UTC),
+		},
+	} {
+		ag := models.AlertGroup{Alerts: testCase.alerts}
+		got := ag.FindEarliestStartsAt()
+		if !got.Equal(testCase.startsAt) {
+			t.Errorf("FindEarliestStartsAt returned %s when %s was expected", got, testCase.startsAt)
+		}
+	}
+}
+
+func TestFindLatestFirstSeen(t *testing.T) {
+	for _, testCase := range []struct {
+		alerts    models.AlertList
+		firstSeen time.Time
+	}{
+		{alerts: models.AlertList{}, firstSeen: time.Time{}},
+		{
+			alerts: models.AlertList{
+				{KarmaFirstSeen: time.Date(2017, time.January, 10, 0, 0, 0, 5, time.UTC), StartsAt: time.Date(2017, time.January, 10, 0, 0, 0, 9, time.UTC)},
+				{KarmaFirstSeen: time.Date(2017, time.January, 10, 0, 0, 0, 8, time.UTC)},
+				{KarmaFirstSeen: time.Date(2017, time.January, 10, 0, 0, 0, 1, time.UTC)},
+			},
+			firstSeen: time.Date(2017, time.January, 10, 0, 0, 0, 8, time.UTC),
+		},
+	} {
+		ag := models.AlertGroup{Alerts: testCase.alerts}
+		got := ag.FindLatestFirstSeen()
+		if !got.Equal(testCase.firstSeen) {
+			t.Errorf("FindLatestFirstSeen returned %s when %s was expected", got, testCase.firstSeen)
+		}
+	}
+}
+
+func TestAlertGroupIsFlapping(t *testing.T) {
+	for _, testCase := range []struct {
+		alerts   models.AlertList
+		flapping bool
+	}{
+		{alerts: models.AlertList{}, flapping: false},
+		{alerts: models.AlertList{{}, {}}, flapping: false},
+		{alerts: models.AlertList{{}, {Flapping: true}}, flapping: true},
+		{alerts: models.AlertList{{Flapping: true}, {Flapping: true}}, flapping: true},
+	} {
+		ag := models.AlertGroup{Alerts: testCase.alerts}
+		if ag.IsFlapping() != testCase.flapping {
+			t.Errorf("IsFlapping() returned %v for %v, expected %v", ag.IsFlapping(), testCase.alerts, testCase.flapping)
+		}
+	}
+}
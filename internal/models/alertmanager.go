@@ -14,6 +14,9 @@ type AlertmanagerInstance struct {
 	StartsAt time.Time `json:"startsAt"`
 	// Source links to alert source for given alertmanager instance
 	Source string `json:"source"`
+	// labels of the alert as reported by this instance, before any label was
+	// normalized or ignored for deduplication
+	Labels map[string]string `json:"-"`
 	// all silences matching current alert in this upstream, we don't export this
 	// in api responses, this is used internally
 	Silences map[string]*Silence `json:"-"`
@@ -22,6 +25,14 @@ type AlertmanagerInstance struct {
 	InhibitedBy []string `json:"inhibitedBy"`
 }
 
+// ClusterPeerStatusUnknown is used as the cluster peer status of Alertmanager
+// instances that don't report it, or when it can't be collected
+const ClusterPeerStatusUnknown = "unknown"
+
+// ClusterPeerStatusReady is the cluster peer status of Alertmanager instances
+// that finished joining the gossip cluster
+const ClusterPeerStatusReady = "ready"
+
 // AlertmanagerAPIStatus describes the Alertmanager instance overall health
 type AlertmanagerAPIStatus struct {
 	Name string `json:"name"`
@@ -35,19 +46,45 @@ type AlertmanagerAPIStatus struct {
 	Version        string            `json:"version"`
 	Cluster        string            `json:"cluster"`
 	ClusterMembers []string          `json:"clusterMembers"`
+	// gossip status of this instance, one of ready, settling, disabled or
+	// unknown
+	ClusterPeerStatus string `json:"clusterPeerStatus"`
+	// timeout for requests sent to this instance, in milliseconds
+	Timeout int64 `json:"timeout"`
+	// number of alerts collected from this instance
+	AlertCount int `json:"alertCount"`
+	// how long the most recent collection cycle took, in milliseconds
+	LastCollectionDuration int64 `json:"lastCollectionDuration"`
+	// when the most recent collection cycle finished
+	LastCollectionTimestamp time.Time `json:"lastCollectionTimestamp"`
 }
 
 // AlertmanagerAPICounters returns number of Alertmanager instances in each
-// state
+// state, along with the number of alerts collected from all instances and the
+// number of unique alerts left after deduplication and dropped because of the
+// alerts.maxTotal limit
 type AlertmanagerAPICounters struct {
+	Total        int `json:"total"`
+	Healthy      int `json:"healthy"`
+	Failed       int `json:"failed"`
+	RawTotal     int `json:"rawTotal"`
+	DedupedTotal int `json:"dedupedTotal"`
+	Dropped      int `json:"dropped"`
+}
+
+// AlertmanagerClusterSummary describes the health of all Alertmanager
+// instances that are members of the same cluster
+type AlertmanagerClusterSummary struct {
 	Total   int `json:"total"`
 	Healthy int `json:"healthy"`
-	Failed  int `json:"failed"`
+	// true if at least one cluster member has an error
+	Degraded bool `json:"degraded"`
 }
 
 // AlertmanagerAPISummary describes the Alertmanager instance overall health
 type AlertmanagerAPISummary struct {
-	Counters  AlertmanagerAPICounters `json:"counters"`
-	Instances []AlertmanagerAPIStatus `json:"instances"`
-	Clusters  map[string][]string     `json:"clusters"`
+	Counters      AlertmanagerAPICounters               `json:"counters"`
+	Instances     []AlertmanagerAPIStatus               `json:"instances"`
+	Clusters      map[string][]string                   `json:"clusters"`
+	ClusterHealth map[string]AlertmanagerClusterSummary `json:"clusterHealth"`
 }
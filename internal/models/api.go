@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 
@@ -19,6 +20,94 @@ type Filter struct {
 	IsValid bool   `json:"isValid"`
 }
 
+// LookupLabel returns the value of a label with given name from the first of
+// passed label maps that has it, alert group labels can be stored on the group,
+// in shared labels or on alerts, so all of them need to be checked
+func LookupLabel(name string, labelMaps ...map[string]string) (string, bool) {
+	for _, labels := range labelMaps {
+		if v, found := labels[name]; found {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// FilterCheckResponse is returned when validating a single filter expression
+type FilterCheckResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason"`
+	Parsed struct {
+		Name    string `json:"name"`
+		Matcher string `json:"matcher"`
+		Value   string `json:"value"`
+	} `json:"parsed"`
+}
+
+// BulkSilenceRequest is the body of a request creating silences for all alerts
+// matching given filters
+type BulkSilenceRequest struct {
+	Filters   []string `json:"filters"`
+	Duration  string   `json:"duration"`
+	CreatedBy string   `json:"createdBy"`
+	Comment   string   `json:"comment"`
+	// name of the silence template used to render silences, optional
+	Template string `json:"template"`
+}
+
+// BulkSilenceResponse is returned after creating silences for alerts matching
+// filters, both silence IDs and errors are keyed by the Alertmanager upstream
+// name, errors will be present if some silences failed to be created
+// Rendered is the list of all distinct silences that were sent
+// Alerts is the number of alerts silences were created for, Skipped is the
+// number of matched alerts that were already silenced
+type BulkSilenceResponse struct {
+	Alerts   int                 `json:"alerts"`
+	Skipped  int                 `json:"skipped"`
+	Silences map[string][]string `json:"silences"`
+	Errors   map[string][]string `json:"errors"`
+	Rendered []Silence           `json:"rendered"`
+}
+
+// FilterDiffRequest is the body of a request comparing alerts matched by two
+// sets of filters
+type FilterDiffRequest struct {
+	A []string `json:"a"`
+	B []string `json:"b"`
+}
+
+// FilterDiffBucket holds fingerprints of all alerts in one bucket of the
+// filter comparison, fingerprints are sorted
+type FilterDiffBucket struct {
+	Count        int      `json:"count"`
+	Fingerprints []string `json:"fingerprints"`
+}
+
+// FilterDiffResponse is returned after comparing two sets of filters, alerts
+// are split into those matched only by the first set, only by the second set
+// and by both sets
+type FilterDiffResponse struct {
+	OnlyA FilterDiffBucket `json:"onlyA"`
+	OnlyB FilterDiffBucket `json:"onlyB"`
+	Both  FilterDiffBucket `json:"both"`
+}
+
+// SilenceSource identifies the Alertmanager upstream a silence was collected
+// from and the ID it uses for that silence
+type SilenceSource struct {
+	Alertmanager string `json:"alertmanager"`
+	ID           string `json:"id"`
+}
+
+// ManagedSilence is an active silence collected from Alertmanager upstreams,
+// silences with the same ID collected from members of the same cluster are
+// merged into a single entry with all sources listed
+type ManagedSilence struct {
+	Silence    Silence         `json:"silence"`
+	Cluster    string          `json:"cluster"`
+	Sources    []SilenceSource `json:"sources"`
+	AlertCount int             `json:"alertCount"`
+}
+
 // Color is used by karmaLabelColor to reprenset colors as RGBA
 type Color struct {
 	Red   uint8 `json:"red"`
@@ -68,6 +157,9 @@ type LabelNameStats struct {
 	Name   string              `json:"name"`
 	Values LabelValueStatsList `json:"values"`
 	Hits   int                 `json:"hits"`
+	// number of all values, values might be paginated so it can be higher
+	// than the length of Values
+	TotalValues int `json:"totalValues"`
 }
 
 type LabelNameStatsList []LabelNameStats
@@ -85,6 +177,38 @@ func (lnsl LabelNameStatsList) Less(i, j int) bool {
 	return lnsl[i].Hits > lnsl[j].Hits
 }
 
+// LabelCrossTabRow holds the number of alerts with given value of the row label
+// for every value of the column label
+type LabelCrossTabRow struct {
+	Value   string         `json:"value"`
+	Hits    int            `json:"hits"`
+	Columns map[string]int `json:"columns"`
+}
+
+type LabelCrossTabRowList []LabelCrossTabRow
+
+func (lctrl LabelCrossTabRowList) Len() int {
+	return len(lctrl)
+}
+func (lctrl LabelCrossTabRowList) Swap(i, j int) {
+	lctrl[i], lctrl[j] = lctrl[j], lctrl[i]
+}
+func (lctrl LabelCrossTabRowList) Less(i, j int) bool {
+	if lctrl[i].Hits == lctrl[j].Hits {
+		return sortorder.NaturalLess(lctrl[i].Value, lctrl[j].Value)
+	}
+	return lctrl[i].Hits > lctrl[j].Hits
+}
+
+// LabelCrossTab is a matrix of alert counts for every combination of values of
+// two labels, like severity and team
+type LabelCrossTab struct {
+	Row    string               `json:"row"`
+	Column string               `json:"column"`
+	Rows   LabelCrossTabRowList `json:"rows"`
+	Hits   int                  `json:"hits"`
+}
+
 // APIAlertGroupSharedMaps defines shared part of APIAlertGroup
 type APIAlertGroupSharedMaps struct {
 	Annotations Annotations         `json:"annotations"`
@@ -98,7 +222,13 @@ type APIAlertGroupSharedMaps struct {
 // annotations that are unique to that instance
 type APIAlertGroup struct {
 	AlertGroup
-	Shared APIAlertGroupSharedMaps `json:"shared"`
+	Shared   APIAlertGroupSharedMaps `json:"shared"`
+	Flapping bool                    `json:"flapping"`
+	// number of alerts that were removed from this group because of the
+	// maxAlertsPerGroup limit
+	TruncatedAlerts int `json:"truncatedAlerts"`
+	// true if this group is in scope of an active maintenance window
+	MaintenanceActive bool `json:"maintenanceActive"`
 }
 
 func (ag *APIAlertGroup) dedupLabels() {
@@ -258,9 +388,11 @@ func (ag *APIAlertGroup) DedupSharedMaps() {
 
 // GridSettings exposes all grid settings from the config file
 type GridSettings struct {
-	Order   string `json:"order"`
-	Reverse bool   `json:"reverse"`
-	Label   string `json:"label"`
+	Order          string `json:"order"`
+	Reverse        bool   `json:"reverse"`
+	Label          string `json:"label"`
+	SecondaryLabel string `json:"secondaryLabel"`
+	Annotation     string `json:"annotation"`
 }
 
 // SortSettings nests all settings specific to sorting
@@ -290,17 +422,92 @@ type Settings struct {
 
 // AlertsResponse is the structure of JSON response UI will use to get alert data
 type AlertsResponse struct {
-	Status      string                        `json:"status"`
-	Timestamp   string                        `json:"timestamp"`
-	Version     string                        `json:"version"`
-	Upstreams   AlertmanagerAPISummary        `json:"upstreams"`
-	Silences    map[string]map[string]Silence `json:"silences"`
-	AlertGroups []APIAlertGroup               `json:"groups"`
-	TotalAlerts int                           `json:"totalAlerts"`
-	Colors      LabelsColorMap                `json:"colors"`
-	Filters     []Filter                      `json:"filters"`
-	Counters    LabelNameStatsList            `json:"counters"`
-	Settings    Settings                      `json:"settings"`
+	Status       string                        `json:"status"`
+	Timestamp    string                        `json:"timestamp"`
+	Version      string                        `json:"version"`
+	Upstreams    AlertmanagerAPISummary        `json:"upstreams"`
+	Silences     map[string]map[string]Silence `json:"silences"`
+	AlertGroups  []APIAlertGroup               `json:"groups"`
+	TotalAlerts  int                           `json:"totalAlerts"`
+	TotalGroups  int                           `json:"totalGroups"`
+	Colors       LabelsColorMap                `json:"colors"`
+	Filters      []Filter                      `json:"filters"`
+	Counters     LabelNameStatsList            `json:"counters"`
+	Settings     Settings                      `json:"settings"`
+	SortSettings GridSettings                  `json:"sortSettings"`
+}
+
+// AlertsAPIVersion is the current version of the alerts response schema,
+// clients can request older versions to keep working after fields are renamed
+const AlertsAPIVersion = 2
+
+// AlertsAPIVersionLegacy is the oldest version of the alerts response schema
+// that is still supported
+const AlertsAPIVersionLegacy = 1
+
+// AlertsResponseV1 is the legacy schema of AlertsResponse, it's the same data
+// but using field names from the first version of the API
+type AlertsResponseV1 struct {
+	Status       string                        `json:"status"`
+	Timestamp    string                        `json:"timestamp"`
+	Version      string                        `json:"version"`
+	Upstreams    AlertmanagerAPISummary        `json:"upstreams"`
+	Silences     map[string]map[string]Silence `json:"silences"`
+	AlertGroups  []APIAlertGroup               `json:"alertGroups"`
+	TotalAlerts  int                           `json:"alertCount"`
+	TotalGroups  int                           `json:"groupCount"`
+	Colors       LabelsColorMap                `json:"colors"`
+	Filters      []Filter                      `json:"filters"`
+	Counters     LabelNameStatsList            `json:"counters"`
+	Settings     Settings                      `json:"settings"`
+	SortSettings GridSettings                  `json:"sortSettings"`
+}
+
+// NewAlertsResponseV1 converts alerts response to the legacy schema
+func NewAlertsResponseV1(resp AlertsResponse) AlertsResponseV1 {
+	return AlertsResponseV1{
+		Status:       resp.Status,
+		Timestamp:    resp.Timestamp,
+		Version:      resp.Version,
+		Upstreams:    resp.Upstreams,
+		Silences:     resp.Silences,
+		AlertGroups:  resp.AlertGroups,
+		TotalAlerts:  resp.TotalAlerts,
+		TotalGroups:  resp.TotalGroups,
+		Colors:       resp.Colors,
+		Filters:      resp.Filters,
+		Counters:     resp.Counters,
+		Settings:     resp.Settings,
+		SortSettings: resp.SortSettings,
+	}
+}
+
+// MarshalAlertsResponse encodes alerts response as JSON using the schema for
+// given API version
+func MarshalAlertsResponse(resp AlertsResponse, apiVersion int) ([]byte, error) {
+	switch apiVersion {
+	case AlertsAPIVersion:
+		return json.Marshal(resp)
+	case AlertsAPIVersionLegacy:
+		return json.Marshal(NewAlertsResponseV1(resp))
+	default:
+		return nil, fmt.Errorf("unsupported API version %d", apiVersion)
+	}
+}
+
+// AlertsExportSchemaVersion is the current version of AlertsExportResponse
+// structure, it should be bumped every time a backward incompatible change is
+// made to it
+const AlertsExportSchemaVersion = 1
+
+// AlertsExportResponse is the structure of JSON response returned when
+// exporting alert groups for use by external tools
+type AlertsExportResponse struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Timestamp     string          `json:"timestamp"`
+	Version       string          `json:"version"`
+	AlertGroups   []APIAlertGroup `json:"groups"`
+	TotalAlerts   int             `json:"totalAlerts"`
 }
 
 // Autocomplete is the structure of autocomplete object for filter hints
@@ -2,6 +2,7 @@ package models_test
 
 import (
 	"encoding/json"
+	"reflect"
 	"sort"
 	"testing"
 
@@ -470,3 +471,137 @@ func TestNameStatsSort(t *testing.T) {
 		t.Errorf("Sorting LabelNameStatsList produces the same output as unsorted instance")
 	}
 }
+
+func TestValueStatsNaturalSort(t *testing.T) {
+	values := models.LabelValueStatsList{
+		models.LabelValueStats{Value: "node10", Hits: 2},
+		models.LabelValueStats{Value: "node2", Hits: 2},
+		models.LabelValueStats{Value: "node1", Hits: 2},
+		models.LabelValueStats{Value: "node3", Hits: 5},
+		models.LabelValueStats{Value: "node20", Hits: 2},
+	}
+	sort.Sort(values)
+
+	got := []string{}
+	for _, v := range values {
+		got = append(got, v.Value)
+	}
+	expected := []string{"node3", "node1", "node2", "node10", "node20"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Incorrectly sorted values, expected %v, got %v", expected, got)
+	}
+}
+
+func TestLookupLabel(t *testing.T) {
+	group := map[string]string{"alertname": "Fake"}
+	shared := map[string]string{"cluster": "prod"}
+	alert := map[string]string{"alertname": "Fake", "cluster": "prod", "instance": "server1"}
+	for _, testCase := range []struct {
+		name  string
+		value string
+		found bool
+	}{
+		{name: "alertname", value: "Fake", found: true},
+		{name: "cluster", value: "prod", found: true},
+		{name: "instance", value: "server1", found: true},
+		{name: "team", value: "", found: false},
+	} {
+		value, found := models.LookupLabel(testCase.name, group, shared, alert)
+		if value != testCase.value || found != testCase.found {
+			t.Errorf("LookupLabel(%s) returned (%s, %v), expected (%s, %v)", testCase.name, value, found, testCase.value, testCase.found)
+		}
+	}
+
+	if _, found := models.LookupLabel("cluster", group, map[string]string{}, map[string]string{"instance": "server1"}); found {
+		t.Error("LookupLabel(cluster) found a label that's not present in any map")
+	}
+}
+
+func TestMarshalAlertsResponse(t *testing.T) {
+	resp := models.AlertsResponse{
+		Status:      "success",
+		Version:     "dev",
+		AlertGroups: []models.APIAlertGroup{{AlertGroup: models.AlertGroup{ID: "1"}}},
+		TotalAlerts: 5,
+		TotalGroups: 1,
+	}
+
+	for _, testCase := range []struct {
+		apiVersion int
+		present    []string
+		missing    []string
+	}{
+		{
+			apiVersion: models.AlertsAPIVersion,
+			present:    []string{"status", "version", "groups", "totalAlerts", "totalGroups"},
+			missing:    []string{"alertGroups", "alertCount", "groupCount"},
+		},
+		{
+			apiVersion: models.AlertsAPIVersionLegacy,
+			present:    []string{"status", "version", "alertGroups", "alertCount", "groupCount"},
+			missing:    []string{"groups", "totalAlerts", "totalGroups"},
+		},
+	} {
+		data, err := models.MarshalAlertsResponse(resp, testCase.apiVersion)
+		if err != nil {
+			t.Fatalf("[v%d] MarshalAlertsResponse() failed: %s", testCase.apiVersion, err)
+		}
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatalf("[v%d] Failed to unmarshal response: %s", testCase.apiVersion, err)
+		}
+		for _, name := range testCase.present {
+			if _, found := fields[name]; !found {
+				t.Errorf("[v%d] Field '%s' is missing from the response: %s", testCase.apiVersion, name, data)
+			}
+		}
+		for _, name := range testCase.missing {
+			if _, found := fields[name]; found {
+				t.Errorf("[v%d] Field '%s' is present in the response: %s", testCase.apiVersion, name, data)
+			}
+		}
+	}
+
+	legacy := models.AlertsResponseV1{}
+	data, _ := models.MarshalAlertsResponse(resp, models.AlertsAPIVersionLegacy)
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		t.Fatalf("Failed to unmarshal legacy response: %s", err)
+	}
+	if legacy.TotalAlerts != resp.TotalAlerts || legacy.TotalGroups != resp.TotalGroups || len(legacy.AlertGroups) != 1 {
+		t.Errorf("Legacy response has different values: %+v", legacy)
+	}
+
+	if _, err := models.MarshalAlertsResponse(resp, 0); err == nil {
+		t.Error("MarshalAlertsResponse() didn't fail for an unsupported version")
+	}
+}
+
+func TestAlertsResponseV1Fields(t *testing.T) {
+	// fields renamed in the current version, every other field must use the
+	// same name in both versions, so adding a field to AlertsResponse fails
+	// this test until it's also added to AlertsResponseV1
+	renamed := map[string]string{
+		"groups":      "alertGroups",
+		"totalAlerts": "alertCount",
+		"totalGroups": "groupCount",
+	}
+
+	current := reflect.TypeOf(models.AlertsResponse{})
+	legacy := reflect.TypeOf(models.AlertsResponseV1{})
+	if current.NumField() != legacy.NumField() {
+		t.Fatalf("AlertsResponse has %d fields while AlertsResponseV1 has %d", current.NumField(), legacy.NumField())
+	}
+	for i := 0; i < current.NumField(); i++ {
+		cf, lf := current.Field(i), legacy.Field(i)
+		if cf.Name != lf.Name || cf.Type != lf.Type {
+			t.Errorf("Field %d is %s %s in AlertsResponse and %s %s in AlertsResponseV1", i, cf.Name, cf.Type, lf.Name, lf.Type)
+		}
+		name := cf.Tag.Get("json")
+		if legacyName, found := renamed[name]; found {
+			name = legacyName
+		}
+		if lf.Tag.Get("json") != name {
+			t.Errorf("Field %s is encoded as '%s' in AlertsResponseV1, expected '%s'", lf.Name, lf.Tag.Get("json"), name)
+		}
+	}
+}
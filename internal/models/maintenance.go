@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// MaintenanceWindow suppresses alert groups in the grid for the duration of a
+// planned maintenance, if there are no filters then all alert groups are in
+// scope, otherwise only groups with at least one alert matching all filters
+// are, it's only kept in karma memory until it ends
+type MaintenanceWindow struct {
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Filters   []string  `json:"filters"`
+	Hide      bool      `json:"hide"`
+	CreatedBy string    `json:"createdBy"`
+}
+
+// IsActive returns true if given time is within this maintenance window
+func (mw MaintenanceWindow) IsActive(now time.Time) bool {
+	return !now.Before(mw.StartsAt) && now.Before(mw.EndsAt)
+}
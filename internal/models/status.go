@@ -4,4 +4,7 @@ type AlertmanagerStatus struct {
 	Version string
 	ID      string
 	PeerIDs []string
+	// gossip status of this instance (ready, settling, disabled), empty if
+	// Alertmanager doesn't expose it
+	ClusterStatus string
 }
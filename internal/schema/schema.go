@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generator builds JSON Schema definitions from Go types using reflection,
+// properties are named using json struct tags, so the schema follows the
+// types used to generate API responses
+type Generator struct {
+	definitions map[string]map[string]interface{}
+}
+
+// NewGenerator creates an empty Generator instance
+func NewGenerator() *Generator {
+	return &Generator{definitions: map[string]map[string]interface{}{}}
+}
+
+// Add generates schema for the type of v and stores it in definitions
+// under given name
+func (g *Generator) Add(name string, v interface{}) {
+	g.definitions[name] = g.schemaFor(reflect.TypeOf(v), false)
+}
+
+// Schema returns a JSON Schema document with all definitions
+func (g *Generator) Schema() map[string]interface{} {
+	definitions := map[string]interface{}{}
+	for name, definition := range g.definitions {
+		definitions[name] = definition
+	}
+	return map[string]interface{}{
+		"$schema":     draft,
+		"definitions": definitions,
+	}
+}
+
+// schemaFor returns schema for given type, if ref is set then named structs
+// are stored in definitions and a reference to them is returned
+func (g *Generator) schemaFor(t reflect.Type, ref bool) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaFor(t.Elem(), ref)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem(), true)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem(), true)}
+	case reflect.Struct:
+		if !ref || t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, found := g.definitions[t.Name()]; !found {
+			// reserve the name first so recursive types don't loop forever
+			g.definitions[t.Name()] = map[string]interface{}{}
+			g.definitions[t.Name()] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/definitions/" + t.Name()}
+	}
+	// interfaces and anything else can hold any value
+	return map[string]interface{}{}
+}
+
+func (g *Generator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	g.addProperties(t, properties)
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func (g *Generator) addProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			// fields of embedded structs are encoded as if they were part of
+			// the outer struct
+			g.addProperties(fieldType, properties)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			// unexported fields are never encoded
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaFor(field.Type, true)
+	}
+}
@@ -0,0 +1,112 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/prymitive/karma/internal/schema"
+)
+
+type embedded struct {
+	ID string `json:"id"`
+}
+
+type child struct {
+	Name     string   `json:"name"`
+	Children []*child `json:"children"`
+}
+
+type root struct {
+	embedded
+	Count     int               `json:"count"`
+	Ratio     float64           `json:"ratio"`
+	Enabled   bool              `json:"enabled,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Labels    map[string]string `json:"labels"`
+	Child     child             `json:"child"`
+	Raw       []byte            `json:"raw"`
+	Any       interface{}       `json:"any"`
+	NoTag     string
+	Skipped   string `json:"-"`
+	hidden    string
+}
+
+func TestGenerator(t *testing.T) {
+	g := schema.NewGenerator()
+	g.Add("root", root{hidden: ""})
+	g.Add("children", []child{})
+
+	expected := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "definitions": {
+    "child": {
+      "properties": {
+        "children": {
+          "items": {
+            "$ref": "#/definitions/child"
+          },
+          "type": "array"
+        },
+        "name": {
+          "type": "string"
+        }
+      },
+      "type": "object"
+    },
+    "children": {
+      "items": {
+        "$ref": "#/definitions/child"
+      },
+      "type": "array"
+    },
+    "root": {
+      "properties": {
+        "NoTag": {
+          "type": "string"
+        },
+        "any": {},
+        "child": {
+          "$ref": "#/definitions/child"
+        },
+        "count": {
+          "type": "integer"
+        },
+        "enabled": {
+          "type": "boolean"
+        },
+        "id": {
+          "type": "string"
+        },
+        "labels": {
+          "additionalProperties": {
+            "type": "string"
+          },
+          "type": "object"
+        },
+        "ratio": {
+          "type": "number"
+        },
+        "raw": {
+          "type": "string"
+        },
+        "timestamp": {
+          "format": "date-time",
+          "type": "string"
+        }
+      },
+      "type": "object"
+    }
+  }
+}`
+
+	result, err := json.MarshalIndent(g.Schema(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, string(result)); diff != "" {
+		t.Errorf("Wrong schema generated (-want +got):\n%s", diff)
+	}
+}
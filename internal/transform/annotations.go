@@ -0,0 +1,34 @@
+package transform
+
+import (
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+)
+
+// ApplyAnnotationRules returns a copy of annotations with visible and isLink
+// attributes updated using all matching rules, rules are applied in order so
+// the last matching rule wins
+func ApplyAnnotationRules(rules []config.AnnotationRule, sourceAnnotations models.Annotations) models.Annotations {
+	if len(rules) == 0 {
+		return sourceAnnotations
+	}
+	annotations := make(models.Annotations, 0, len(sourceAnnotations))
+	for _, annotation := range sourceAnnotations {
+		for _, rule := range rules {
+			if rule.CompiledNameRegex != nil && !rule.CompiledNameRegex.MatchString(annotation.Name) {
+				continue
+			}
+			if rule.CompiledValueRegex != nil && !rule.CompiledValueRegex.MatchString(annotation.Value) {
+				continue
+			}
+			if rule.Visible != nil {
+				annotation.Visible = *rule.Visible
+			}
+			if rule.IsLink != nil {
+				annotation.IsLink = *rule.IsLink
+			}
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
@@ -0,0 +1,98 @@
+package transform_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/prymitive/karma/internal/config"
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/transform"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestApplyAnnotationRules(t *testing.T) {
+	source := models.Annotations{
+		{Name: "dashboard", Value: "https://grafana.example.com/d/abc", Visible: true, IsLink: true},
+		{Name: "help", Value: "http://wiki", Visible: true, IsLink: true},
+		{Name: "runbook", Value: "wiki/runbooks/disk", Visible: true, IsLink: false},
+		{Name: "summary", Value: "disk is full", Visible: true, IsLink: false},
+	}
+
+	for _, testCase := range []struct {
+		name     string
+		rules    []config.AnnotationRule
+		expected models.Annotations
+	}{
+		{
+			name:     "no rules",
+			rules:    []config.AnnotationRule{},
+			expected: source,
+		},
+		{
+			name: "hide by name",
+			rules: []config.AnnotationRule{
+				{CompiledNameRegex: regexp.MustCompile("^(dashboard|help)$"), Visible: boolPtr(false)},
+			},
+			expected: models.Annotations{
+				{Name: "dashboard", Value: "https://grafana.example.com/d/abc", Visible: false, IsLink: true},
+				{Name: "help", Value: "http://wiki", Visible: false, IsLink: true},
+				{Name: "runbook", Value: "wiki/runbooks/disk", Visible: true, IsLink: false},
+				{Name: "summary", Value: "disk is full", Visible: true, IsLink: false},
+			},
+		},
+		{
+			name: "mark relative links by value",
+			rules: []config.AnnotationRule{
+				{CompiledValueRegex: regexp.MustCompile("^wiki/"), IsLink: boolPtr(true)},
+			},
+			expected: models.Annotations{
+				{Name: "dashboard", Value: "https://grafana.example.com/d/abc", Visible: true, IsLink: true},
+				{Name: "help", Value: "http://wiki", Visible: true, IsLink: true},
+				{Name: "runbook", Value: "wiki/runbooks/disk", Visible: true, IsLink: true},
+				{Name: "summary", Value: "disk is full", Visible: true, IsLink: false},
+			},
+		},
+		{
+			name: "name and value must both match",
+			rules: []config.AnnotationRule{
+				{CompiledNameRegex: regexp.MustCompile("^help$"), CompiledValueRegex: regexp.MustCompile("grafana"), IsLink: boolPtr(false)},
+				{CompiledNameRegex: regexp.MustCompile("^dashboard$"), CompiledValueRegex: regexp.MustCompile("grafana"), IsLink: boolPtr(false)},
+			},
+			expected: models.Annotations{
+				{Name: "dashboard", Value: "https://grafana.example.com/d/abc", Visible: true, IsLink: false},
+				{Name: "help", Value: "http://wiki", Visible: true, IsLink: true},
+				{Name: "runbook", Value: "wiki/runbooks/disk", Visible: true, IsLink: false},
+				{Name: "summary", Value: "disk is full", Visible: true, IsLink: false},
+			},
+		},
+		{
+			name: "last matching rule wins",
+			rules: []config.AnnotationRule{
+				{CompiledNameRegex: regexp.MustCompile(".*"), Visible: boolPtr(false)},
+				{CompiledNameRegex: regexp.MustCompile("^summary$"), Visible: boolPtr(true)},
+			},
+			expected: models.Annotations{
+				{Name: "dashboard", Value: "https://grafana.example.com/d/abc", Visible: false, IsLink: true},
+				{Name: "help", Value: "http://wiki", Visible: false, IsLink: true},
+				{Name: "runbook", Value: "wiki/runbooks/disk", Visible: false, IsLink: false},
+				{Name: "summary", Value: "disk is full", Visible: true, IsLink: false},
+			},
+		},
+	} {
+		before := make(models.Annotations, len(source))
+		copy(before, source)
+
+		result := transform.ApplyAnnotationRules(testCase.rules, source)
+		if diff := cmp.Diff(testCase.expected, result); diff != "" {
+			t.Errorf("[%s] Wrong annotations (-want +got):\n%s", testCase.name, diff)
+		}
+		if diff := cmp.Diff(before, source); diff != "" {
+			t.Errorf("[%s] Source annotations were modified (-want +got):\n%s", testCase.name, diff)
+		}
+	}
+}
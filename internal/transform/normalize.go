@@ -0,0 +1,25 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/prymitive/karma/internal/slices"
+)
+
+// NormalizeLabelValues returns a copy of the label map with canonical values,
+// values of labels on the lowercase list are converted to lower case first,
+// then values found in the mapping for given label name are replaced with the
+// mapped value
+func NormalizeLabelValues(lowercase []string, mapping map[string]map[string]string, sourceLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(sourceLabels))
+	for label, value := range sourceLabels {
+		if slices.StringInSlice(lowercase, label) {
+			value = strings.ToLower(value)
+		}
+		if mapped, found := mapping[label][value]; found {
+			value = mapped
+		}
+		labels[label] = value
+	}
+	return labels
+}
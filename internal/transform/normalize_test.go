@@ -0,0 +1,57 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/prymitive/karma/internal/transform"
+)
+
+func TestNormalizeLabelValues(t *testing.T) {
+	mapping := map[string]map[string]string{
+		"severity": {"crit": "critical", "warn": "warning"},
+		"env":      {"Prod": "production"},
+	}
+	for _, testCase := range []struct {
+		lowercase []string
+		before    map[string]string
+		after     map[string]string
+	}{
+		{
+			lowercase: []string{},
+			before:    map[string]string{"alertname": "Foo", "severity": "Critical"},
+			after:     map[string]string{"alertname": "Foo", "severity": "Critical"},
+		},
+		{
+			lowercase: []string{"severity"},
+			before:    map[string]string{"alertname": "Foo", "severity": "Critical"},
+			after:     map[string]string{"alertname": "Foo", "severity": "critical"},
+		},
+		{
+			lowercase: []string{"severity"},
+			before:    map[string]string{"alertname": "Foo", "severity": "CRIT"},
+			after:     map[string]string{"alertname": "Foo", "severity": "critical"},
+		},
+		{
+			lowercase: []string{},
+			before:    map[string]string{"severity": "warn", "env": "Prod"},
+			after:     map[string]string{"severity": "warning", "env": "production"},
+		},
+		{
+			lowercase: []string{"env"},
+			before:    map[string]string{"severity": "WARN", "env": "Prod"},
+			after:     map[string]string{"severity": "WARN", "env": "prod"},
+		},
+		{
+			lowercase: []string{"severity"},
+			before:    map[string]string{},
+			after:     map[string]string{},
+		},
+	} {
+		labels := transform.NormalizeLabelValues(testCase.lowercase, mapping, testCase.before)
+		if diff := cmp.Diff(testCase.after, labels); diff != "" {
+			t.Errorf("Wrong labels for %v with lowercase=%v (-want +got):\n%s", testCase.before, testCase.lowercase, diff)
+		}
+	}
+}
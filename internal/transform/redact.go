@@ -0,0 +1,41 @@
+package transform
+
+import (
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/slices"
+)
+
+// RedactLabels is used to hide sensitive label values from API responses
+// it takes the list of label keys to strip, the list of label keys to redact
+// and alert label map, it will return a new label map without stripped labels
+// and with redacted label values replaced with the placeholder
+func RedactLabels(strippedLabels, redactedLabels []string, placeholder string, sourceLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(sourceLabels))
+	for label, value := range sourceLabels {
+		if slices.StringInSlice(strippedLabels, label) {
+			continue
+		}
+		if slices.StringInSlice(redactedLabels, label) {
+			value = placeholder
+		}
+		labels[label] = value
+	}
+	return labels
+}
+
+// RedactAnnotations works like RedactLabels but for annotations, redacted
+// annotations are never rendered as links since the placeholder isn't one
+func RedactAnnotations(strippedAnnotations, redactedAnnotations []string, placeholder string, sourceAnnotations models.Annotations) models.Annotations {
+	annotations := models.Annotations{}
+	for _, annotation := range sourceAnnotations {
+		if slices.StringInSlice(strippedAnnotations, annotation.Name) {
+			continue
+		}
+		if slices.StringInSlice(redactedAnnotations, annotation.Name) {
+			annotation.Value = placeholder
+			annotation.IsLink = false
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
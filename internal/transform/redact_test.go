@@ -0,0 +1,110 @@
+package transform_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/transform"
+)
+
+var redactLabelsTests = []struct {
+	strip  []string
+	redact []string
+	before map[string]string
+	after  map[string]string
+}{
+	{
+		strip:  []string{},
+		redact: []string{},
+		before: map[string]string{"alertname": "Foo", "url": "http://internal"},
+		after:  map[string]string{"alertname": "Foo", "url": "http://internal"},
+	},
+	{
+		strip:  []string{"url"},
+		redact: []string{},
+		before: map[string]string{"alertname": "Foo", "url": "http://internal"},
+		after:  map[string]string{"alertname": "Foo"},
+	},
+	{
+		strip:  []string{},
+		redact: []string{"url", "missing"},
+		before: map[string]string{"alertname": "Foo", "url": "http://internal"},
+		after:  map[string]string{"alertname": "Foo", "url": "xxx"},
+	},
+	{
+		strip:  []string{"url"},
+		redact: []string{"url", "instance"},
+		before: map[string]string{"alertname": "Foo", "url": "http://internal", "instance": "server1"},
+		after:  map[string]string{"alertname": "Foo", "instance": "xxx"},
+	},
+}
+
+func TestRedactLabels(t *testing.T) {
+	for _, testCase := range redactLabelsTests {
+		before := map[string]string{}
+		for k, v := range testCase.before {
+			before[k] = v
+		}
+		labels := transform.RedactLabels(testCase.strip, testCase.redact, "xxx", testCase.before)
+		if diff := cmp.Diff(testCase.after, labels); diff != "" {
+			t.Errorf("RedactLabels(%v, %v) returned wrong labels (-want +got):\n%s", testCase.strip, testCase.redact, diff)
+		}
+		if diff := cmp.Diff(before, testCase.before); diff != "" {
+			t.Errorf("RedactLabels(%v, %v) modified source labels (-want +got):\n%s", testCase.strip, testCase.redact, diff)
+		}
+	}
+}
+
+var redactAnnotationsTests = []struct {
+	strip  []string
+	redact []string
+	before models.Annotations
+	after  models.Annotations
+}{
+	{
+		strip:  []string{},
+		redact: []string{},
+		before: models.Annotations{
+			{Name: "help", Value: "http://internal", Visible: true, IsLink: true},
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+		after: models.Annotations{
+			{Name: "help", Value: "http://internal", Visible: true, IsLink: true},
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+	},
+	{
+		strip:  []string{"help"},
+		redact: []string{},
+		before: models.Annotations{
+			{Name: "help", Value: "http://internal", Visible: true, IsLink: true},
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+		after: models.Annotations{
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+	},
+	{
+		strip:  []string{},
+		redact: []string{"help"},
+		before: models.Annotations{
+			{Name: "help", Value: "http://internal", Visible: true, IsLink: true},
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+		after: models.Annotations{
+			{Name: "help", Value: "xxx", Visible: true, IsLink: false},
+			{Name: "summary", Value: "foo", Visible: true},
+		},
+	},
+}
+
+func TestRedactAnnotations(t *testing.T) {
+	for _, testCase := range redactAnnotationsTests {
+		annotations := transform.RedactAnnotations(testCase.strip, testCase.redact, "xxx", testCase.before)
+		if diff := cmp.Diff(testCase.after, annotations); diff != "" {
+			t.Errorf("RedactAnnotations(%v, %v) returned wrong annotations (-want +got):\n%s", testCase.strip, testCase.redact, diff)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package uri
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func basicAuthHeaders(username, password string) map[string]string {
+	auth := username + ":" + password
+	return map[string]string{
+		"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(auth)),
+	}
+}
+
+// BasicAuthFile provides Basic Auth credentials with the password stored in
+// a file, which is re-read every time it's modified, so rotated passwords are
+// used without restarting karma
+type BasicAuthFile struct {
+	Username string
+	Path     string
+	lock     sync.Mutex
+	modTime  time.Time
+	size     int64
+	password string
+}
+
+// NewBasicAuthFile creates a BasicAuthFile instance for given username and
+// password file path
+func NewBasicAuthFile(username, path string) *BasicAuthFile {
+	return &BasicAuthFile{Username: username, Path: path}
+}
+
+// Password returns the current password, file is only read again if it was
+// modified since the last read
+func (b *BasicAuthFile) Password() (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	info, err := os.Stat(b.Path)
+	if err != nil {
+		return "", err
+	}
+	if info.ModTime().Equal(b.modTime) && info.Size() == b.size {
+		return b.password, nil
+	}
+
+	content, err := ioutil.ReadFile(b.Path)
+	if err != nil {
+		return "", err
+	}
+	log.Infof("Reading basic auth password from '%s'", b.Path)
+	b.password = strings.TrimSpace(string(content))
+	b.modTime = info.ModTime()
+	b.size = info.Size()
+	return b.password, nil
+}
+
+// Headers returns headers for Basic Auth using current credentials, if the
+// password file can't be read no headers are returned
+func (b *BasicAuthFile) Headers() map[string]string {
+	password, err := b.Password()
+	if err != nil {
+		log.Errorf("Failed to read basic auth password from '%s': %s", b.Path, err)
+		return map[string]string{}
+	}
+	return basicAuthHeaders(b.Username, password)
+}
@@ -0,0 +1,57 @@
+package uri_test
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/uri"
+)
+
+func writePasswordFile(t *testing.T, filename, password string, modTime time.Time) {
+	err := ioutil.WriteFile(filename, []byte(password+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chtimes(filename, modTime, modTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBasicAuthFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "karma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := path.Join(dir, "password")
+
+	expectedHeader := func(password string) string {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte("user:"+password))
+	}
+
+	now := time.Now()
+	writePasswordFile(t, filename, "secret", now)
+	b := uri.NewBasicAuthFile("user", filename)
+	if h := b.Headers()["Authorization"]; h != expectedHeader("secret") {
+		t.Errorf("Got Authorization header %q, expected %q", h, expectedHeader("secret"))
+	}
+
+	// simulate password rotation
+	writePasswordFile(t, filename, "rotated", now.Add(time.Minute))
+	if h := b.Headers()["Authorization"]; h != expectedHeader("rotated") {
+		t.Errorf("Got Authorization header %q after rotation, expected %q", h, expectedHeader("rotated"))
+	}
+
+	err = os.Remove(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := b.Headers(); len(h) != 0 {
+		t.Errorf("Got headers %v for missing password file, expected none", h)
+	}
+}
@@ -22,6 +22,17 @@ func (fr *fileReader) Close() error {
 	return fr.fd.Close()
 }
 
+// IsStaticFile returns true if given URI points to a static JSON file with
+// alerts and silences, rather than a directory with Alertmanager API responses
+func IsStaticFile(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	// relative paths like file://alerts.json are parsed as host
+	return u.Scheme == "file" && strings.HasSuffix(u.Host+u.Path, ".json")
+}
+
 // FileURIReader can read data from file:// URIs
 type FileURIReader struct {
 }
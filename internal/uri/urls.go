@@ -1,7 +1,6 @@
 package uri
 
 import (
-	"encoding/base64"
 	"net/url"
 	"path"
 )
@@ -45,8 +44,7 @@ func HeadersForBasicAuth(s string) map[string]string {
 
 	if u.User != nil {
 		if password, pwdSet := u.User.Password(); pwdSet {
-			auth := u.User.Username() + ":" + password
-			headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(auth))
+			headers = basicAuthHeaders(u.User.Username(), password)
 		}
 	}
 
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Alert is a single new alert included in the notification payload
+type Alert struct {
+	Labels        map[string]string `json:"labels"`
+	StartsAt      time.Time         `json:"startsAt"`
+	Alertmanagers []string          `json:"alertmanagers"`
+}
+
+// Payload is the JSON body sent to the webhook receiver, there's only one
+// request sent per collection cycle with all new alerts
+type Payload struct {
+	Alerts []Alert `json:"alerts"`
+}
+
+// Notifier sends a POST request to the webhook URI every time a collection
+// cycle returns alerts that weren't seen before
+type Notifier struct {
+	URI     string
+	Headers map[string]string
+	// number of attempts for every notification and the delay before the first
+	// retry, it's doubled after every failed attempt
+	Retries int
+	Backoff time.Duration
+	// how long alerts are remembered after they were last collected, alerts
+	// missing from a few collection cycles, for example because an upstream
+	// failed, won't trigger another notification when they are collected again
+	TTL time.Duration
+
+	client *http.Client
+	lock   sync.Mutex
+	// alert fingerprint -> last time it was collected
+	seen   map[string]time.Time
+	seeded bool
+	wg     sync.WaitGroup
+}
+
+// NewNotifier creates a new Notifier for given URI
+func NewNotifier(uri string, headers map[string]string, timeout time.Duration) *Notifier {
+	return &Notifier{
+		URI:     uri,
+		Headers: headers,
+		Retries: 5,
+		Backoff: time.Second,
+		TTL:     time.Minute * 15,
+		client:  &http.Client{Timeout: timeout},
+		seen:    map[string]time.Time{},
+	}
+}
+
+// Update takes all alert groups from the last collection cycle and sends a
+// notification for every alert that wasn't seen within the TTL, alerts
+// collected by the first cycle are only recorded so that restarting karma
+// doesn't notify about all alerts again
+func (n *Notifier) Update(groups []models.AlertGroup) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	now := time.Now()
+	current := map[string]bool{}
+	newAlerts := []Alert{}
+	for _, ag := range groups {
+		for _, alert := range ag.Alerts {
+			fp := alert.LabelsFingerprint()
+			if current[fp] {
+				continue
+			}
+			current[fp] = true
+			if lastSeen, found := n.seen[fp]; n.seeded && (!found || now.Sub(lastSeen) > n.TTL) {
+				newAlerts = append(newAlerts, newAlert(alert))
+			}
+			n.seen[fp] = now
+		}
+	}
+	for fp, lastSeen := range n.seen {
+		if now.Sub(lastSeen) > n.TTL {
+			delete(n.seen, fp)
+		}
+	}
+
+	if !n.seeded {
+		n.seeded = true
+		return
+	}
+	if len(newAlerts) == 0 {
+		return
+	}
+
+	log.Infof("Sending webhook notification for %d new alert(s)", len(newAlerts))
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.send(Payload{Alerts: newAlerts})
+	}()
+}
+
+// Wait blocks until all pending notifications are sent
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+func newAlert(alert models.Alert) Alert {
+	a := Alert{
+		Labels:        alert.Labels,
+		StartsAt:      alert.StartsAt,
+		Alertmanagers: []string{},
+	}
+	for _, am := range alert.Alertmanager {
+		a.Alertmanagers = append(a.Alertmanagers, am.Name)
+	}
+	sort.Strings(a.Alertmanagers)
+	return a
+}
+
+// send will try to deliver the payload, failed requests are retried with an
+// exponential backoff and errors are only logged
+func (n *Notifier) send(payload Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Errorf("Failed to encode webhook payload: %s", err)
+		return
+	}
+
+	backoff := n.Backoff
+	for attempt := 1; attempt <= n.Retries; attempt++ {
+		err = n.post(body)
+		if err == nil {
+			return
+		}
+		log.Errorf("Webhook request %d/%d to %s failed: %s", attempt, n.Retries, n.URI, err)
+		if attempt < n.Retries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	log.Errorf("Giving up on webhook notification for %d alert(s)", len(payload.Alerts))
+}
+
+func (n *Notifier) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, n.URI, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("request failed with status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prymitive/karma/internal/models"
+	"github.com/prymitive/karma/internal/webhook"
+)
+
+type mockReceiver struct {
+	lock     sync.Mutex
+	failures int
+	requests int
+	payloads []webhook.Payload
+	headers  []http.Header
+}
+
+func (m *mockReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.requests++
+	if m.failures > 0 {
+		m.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	payload := webhook.Payload{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	m.payloads = append(m.payloads, payload)
+	m.headers = append(m.headers, r.Header)
+}
+
+func mockGroups(names ...string) []models.AlertGroup {
+	ag := models.AlertGroup{Alerts: models.AlertList{}}
+	for _, name := range names {
+		alert := models.Alert{
+			Labels: map[string]string{"alertname": name},
+			Alertmanager: []models.AlertmanagerInstance{
+				{Name: "am2"},
+				{Name: "am1"},
+			},
+		}
+		alert.UpdateFingerprints()
+		ag.Alerts = append(ag.Alerts, alert)
+	}
+	return []models.AlertGroup{ag}
+}
+
+func newTestNotifier(uri string) *webhook.Notifier {
+	n := webhook.NewNotifier(uri, map[string]string{"X-Auth": "secret"}, time.Second)
+	n.Retries = 3
+	n.Backoff = time.Millisecond
+	return n
+}
+
+func TestNotifierUpdate(t *testing.T) {
+	receiver := &mockReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestNotifier(server.URL)
+
+	// first update only records alerts
+	n.Update(mockGroups("foo", "bar"))
+	n.Wait()
+	if receiver.requests != 0 {
+		t.Errorf("Got %d request(s) after the first update, expected 0", receiver.requests)
+	}
+
+	// no new alerts
+	n.Update(mockGroups("foo", "bar"))
+	n.Wait()
+	if receiver.requests != 0 {
+		t.Errorf("Got %d request(s) without new alerts, expected 0", receiver.requests)
+	}
+
+	// one new alert, all new alerts from a single cycle are sent together
+	n.Update(mockGroups("foo", "bar", "baz"))
+	n.Wait()
+	if len(receiver.payloads) != 1 {
+		t.Fatalf("Got %d payload(s), expected 1", len(receiver.payloads))
+	}
+	if len(receiver.payloads[0].Alerts) != 1 {
+		t.Fatalf("Got %d alert(s) in the payload, expected 1", len(receiver.payloads[0].Alerts))
+	}
+	alert := receiver.payloads[0].Alerts[0]
+	if alert.Labels["alertname"] != "baz" {
+		t.Errorf("Got alert with labels %v, expected alertname=baz", alert.Labels)
+	}
+	if len(alert.Alertmanagers) != 2 || alert.Alertmanagers[0] != "am1" || alert.Alertmanagers[1] != "am2" {
+		t.Errorf("Got alertmanagers %v, expected [am1 am2]", alert.Alertmanagers)
+	}
+	if receiver.headers[0].Get("X-Auth") != "secret" {
+		t.Errorf("X-Auth header is missing from the request: %v", receiver.headers[0])
+	}
+}
+
+func TestNotifierTTL(t *testing.T) {
+	receiver := &mockReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestNotifier(server.URL)
+	n.TTL = time.Millisecond * 200
+
+	n.Update(mockGroups("foo", "bar"))
+	n.Update(mockGroups("foo", "bar", "baz"))
+	n.Wait()
+	if len(receiver.payloads) != 1 {
+		t.Fatalf("Got %d payload(s) after a new alert, expected 1", len(receiver.payloads))
+	}
+
+	// baz is missing from a single cycle, it's still remembered so there's no
+	// notification when it's collected again
+	n.Update(mockGroups("foo", "bar"))
+	n.Update(mockGroups("foo", "bar", "baz"))
+	n.Wait()
+	if len(receiver.payloads) != 1 {
+		t.Errorf("Got %d payload(s) after a gap cycle, expected 1", len(receiver.payloads))
+	}
+
+	// baz is missing for longer than the TTL, it's new once collected again
+	n.Update(mockGroups("foo", "bar"))
+	time.Sleep(time.Millisecond * 300)
+	n.Update(mockGroups("foo", "bar"))
+	n.Update(mockGroups("foo", "bar", "baz"))
+	n.Wait()
+	if len(receiver.payloads) != 2 {
+		t.Fatalf("Got %d payload(s) after alert fired again, expected 2", len(receiver.payloads))
+	}
+	if len(receiver.payloads[1].Alerts) != 1 || receiver.payloads[1].Alerts[0].Labels["alertname"] != "baz" {
+		t.Errorf("Got alerts %v in the last payload, expected only alertname=baz", receiver.payloads[1].Alerts)
+	}
+}
+
+func TestNotifierRetry(t *testing.T) {
+	receiver := &mockReceiver{failures: 2}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestNotifier(server.URL)
+	n.Update(mockGroups())
+	n.Update(mockGroups("foo"))
+	n.Wait()
+
+	if receiver.requests != 3 {
+		t.Errorf("Got %d request(s), expected 3", receiver.requests)
+	}
+	if len(receiver.payloads) != 1 {
+		t.Errorf("Got %d payload(s), expected 1", len(receiver.payloads))
+	}
+}
+
+func TestNotifierGiveUp(t *testing.T) {
+	receiver := &mockReceiver{failures: 10}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	n := newTestNotifier(server.URL)
+	n.Update(mockGroups())
+	n.Update(mockGroups("foo"))
+	n.Wait()
+
+	if receiver.requests != 3 {
+		t.Errorf("Got %d request(s), expected 3", receiver.requests)
+	}
+	if len(receiver.payloads) != 0 {
+		t.Errorf("Got %d payload(s), expected 0", len(receiver.payloads))
+	}
+}
+
+func TestNotifierUnreachable(t *testing.T) {
+	server := httptest.NewServer(&mockReceiver{})
+	uri := server.URL
+	server.Close()
+
+	n := newTestNotifier(uri)
+	n.Update(mockGroups())
+	n.Update(mockGroups("foo"))
+	n.Wait()
+}
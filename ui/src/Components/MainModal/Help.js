@@ -77,6 +77,20 @@ const Help = () => (
             True if compared alert attribute is missing or have a value that is
             not equal to <code>value</code>.
           </FilterOperatorHelp>
+          <FilterOperatorHelp
+            operator="=i="
+            description="Case insensitive exact match"
+          >
+            True if compared alert attribute value is equal to{" "}
+            <code>value</code> ignoring case.
+          </FilterOperatorHelp>
+          <FilterOperatorHelp
+            operator="!i="
+            description="Case insensitive negative match"
+          >
+            True if compared alert attribute is missing or have a value that is
+            not equal to <code>value</code> ignoring case.
+          </FilterOperatorHelp>
           <FilterOperatorHelp
             operator="=~"
             description="Regular expression match"
@@ -99,6 +113,20 @@ const Help = () => (
             True if compared alert attribue value is less than{" "}
             <code>value</code>.
           </FilterOperatorHelp>
+          <FilterOperatorHelp
+            operator="&gt;="
+            description="Greater than or equal match"
+          >
+            True if compared alert attribute value is greater than or equal
+            to <code>value</code>.
+          </FilterOperatorHelp>
+          <FilterOperatorHelp
+            operator="&lt;="
+            description="Less than or equal match"
+          >
+            True if compared alert attribute value is less than or equal to{" "}
+            <code>value</code>.
+          </FilterOperatorHelp>
         </dl>
       }
       extraProps={{ open: true }}
@@ -109,7 +137,7 @@ const Help = () => (
         <dl>
           <QueryHelp
             title="Match alerts based on any label"
-            operators={["=", "!=", "=~", "!~", ">", "<"]}
+            operators={["=", "!=", "=i=", "!i=", "=~", "!~", ">", "<"]}
           >
             <FilterExample example="alertname=UnableToPing">
               Match alerts with label <code>alertname</code> equal to{" "}
@@ -123,6 +151,11 @@ const Help = () => (
               Match alerts with label <code>service</code> missing or not equal
               to <code>apache3</code>.
             </FilterExample>
+            <FilterExample example="severity=i=critical">
+              Match alerts with label <code>severity</code> equal to{" "}
+              <code>critical</code> ignoring case, this will also match{" "}
+              <code>Critical</code> or <code>CRITICAL</code>.
+            </FilterExample>
             <FilterExample example="service=~apache">
               Match alerts with label <code>service</code> matching regular
               expression <code>/.*apache.*/</code>.
@@ -137,6 +170,19 @@ const Help = () => (
               integer if possible, string comparision will be used as fallback.
             </FilterExample>
           </QueryHelp>
+          <QueryHelp
+            title="Match alerts using any of multiple filters"
+            operators={["OR"]}
+          >
+            <FilterExample example="alertname=Foo OR alertname=Bar">
+              Match alerts with label <code>alertname</code> equal to{" "}
+              <code>Foo</code> or <code>Bar</code>.
+            </FilterExample>
+            <FilterExample example="severity=critical OR @age&gt;1h">
+              Match alerts with label <code>severity</code> equal to{" "}
+              <code>critical</code> or alerts older than 1 hour.
+            </FilterExample>
+          </QueryHelp>
         </dl>
       }
     />
@@ -154,8 +200,9 @@ const Help = () => (
               <code>prod</code>.
             </FilterExample>
             <FilterExample example="@alertmanager!=dev">
-              Match alerts collected from Alertmanager instances except for the
-              one named <code>dev</code>.
+              Match alerts not collected from Alertmanager instance named{" "}
+              <code>dev</code>, alerts collected from multiple instances are
+              excluded if any of them is named <code>dev</code>.
             </FilterExample>
             <FilterExample example="@alertmanager=~prod">
               Match alerts collected from Alertmanager instances with names
@@ -163,6 +210,19 @@ const Help = () => (
             </FilterExample>
           </QueryHelp>
 
+          <QueryHelp
+            title="Match alerts based on the host name of the alert source"
+            operators={["=", "!=", "=~", "!~"]}
+          >
+            <FilterExample example="@source_host=prom1">
+              Match alerts generated by Prometheus running on host{" "}
+              <code>prom1</code>.
+            </FilterExample>
+            <FilterExample example="@source_host=~prom-.*">
+              Match alerts generated by Prometheus servers running on hosts
+              with names matching regular expression <code>/prom-.*/</code>.
+            </FilterExample>
+          </QueryHelp>
           <QueryHelp
             title="Match alerts based on the receiver name"
             operators={["=", "!=", "=~", "!~"]}
@@ -198,6 +258,160 @@ const Help = () => (
             </FilterExample>
           </QueryHelp>
 
+          <QueryHelp
+            title="Match alerts based on the inhibition state"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@inhibited=true">
+              Match only alerts suppressed by inhibition rules.
+            </FilterExample>
+            <FilterExample example="@inhibited=false">
+              Match alerts that are not inhibited.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts missing a label"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@label_missing=team">
+              Match alerts without the <code>team</code> label.
+            </FilterExample>
+            <FilterExample example="@label_missing!=team">
+              Match alerts with the <code>team</code> label.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts by a regex against all label values"
+            operators={["=~", "!~"]}
+          >
+            <FilterExample example="@any_label=~web.*">
+              Match alerts with any label value matching the regex.
+            </FilterExample>
+            <FilterExample example="@any_label!~web.*">
+              Match alerts where no label value matches the regex.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts with severity at or above given level"
+            operators={["="]}
+          >
+            <FilterExample example="@severity_at_least=warning">
+              Match alerts with warning or more severe level, like critical.
+              Severity levels are ranked using the filters configuration.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts based on the Alertmanager fingerprint"
+            operators={["=", "!=", "=~", "!~"]}
+          >
+            <FilterExample example="@fingerprint=aae7a1432b5d2f1b">
+              Match the alert with fingerprint <code>aae7a1432b5d2f1b</code>.
+            </FilterExample>
+            <FilterExample example="@fingerprint=~^aae7">
+              Match alerts with fingerprint starting with <code>aae7</code>.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts with any of the listed fingerprints"
+            operators={["="]}
+          >
+            <FilterExample example="@fingerprint_in=aae7a1432b5d2f1b,54c2f185e49cfccb">
+              Match alerts with either of the two listed fingerprints.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts based on the ID of the alert group"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@group=4a5c5b8e4e51e0eb">
+              Match alerts from the alert group with ID 4a5c5b8e4e51e0eb.
+            </FilterExample>
+            <FilterExample example="@group!=4a5c5b8e4e51e0eb">
+              Match alerts from all other alert groups.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts that are flapping"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@flapping=true">
+              Match alerts that changed state too many times recently.
+            </FilterExample>
+            <FilterExample example="@flapping=false">
+              Match alerts that are not flapping.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts that were acknowledged"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@acked=true">
+              Match alerts that someone acknowledged in karma.
+            </FilterExample>
+            <FilterExample example="@acked=false">
+              Match alerts that are not acknowledged.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts with labels that differ across sources"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@inconsistent=true">
+              Match alerts reported with different labels by upstreams they
+              were merged from, usually caused by upstreams with different
+              config.
+            </FilterExample>
+            <FilterExample example="@inconsistent=false">
+              Match alerts reported identically by all upstreams.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts cluster members disagree about"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@cluster_disagreement=true">
+              Match alerts missing from some healthy members of their cluster,
+              usually caused by gossip lag or split-brain.
+            </FilterExample>
+            <FilterExample example="@cluster_disagreement=false">
+              Match alerts reported by all healthy members of their cluster.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts based on the number of Alertmanager clusters reporting them"
+            operators={["=", "!=", ">", "<", ">=", "<="]}
+          >
+            <FilterExample example="@cluster_count&gt;=2">
+              Match alerts reported by at least 2 clusters.
+            </FilterExample>
+            <FilterExample example="@cluster_count=1">
+              Match alerts reported by a single cluster.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the number of Alertmanager instances reporting them"
+            operators={["=", "!=", ">", "<", ">=", "<="]}
+          >
+            <FilterExample example="@sources&gt;=2">
+              Match alerts reported by at least 2 Alertmanager instances, even
+              if they are members of the same cluster.
+            </FilterExample>
+            <FilterExample example="@sources=1">
+              Match alerts reported by only one Alertmanager instance.
+            </FilterExample>
+          </QueryHelp>
+
           <QueryHelp
             title="Match suppressed alerts based on the silence ID"
             operators={["=", "!="]}
@@ -228,6 +442,20 @@ const Help = () => (
             </FilterExample>
           </QueryHelp>
 
+          <QueryHelp
+            title="Match alerts based on the comment of silence"
+            operators={["=~", "!~"]}
+          >
+            <FilterExample example="@silence_comment=~JIRA-1234">
+              Match silenced alerts with silence comment matching regular
+              expression <code>/.*JIRA-1234.*/</code>.
+            </FilterExample>
+            <FilterExample example="@silence_comment!~JIRA-1234">
+              Match silenced alerts with silence comment not matching regular
+              expression <code>/.*JIRA-1234.*/</code>.
+            </FilterExample>
+          </QueryHelp>
+
           <QueryHelp
             title="Match alerts based on the jira linked in the silence"
             operators={["=", "!=", "=~", "!~"]}
@@ -250,6 +478,32 @@ const Help = () => (
             </FilterExample>
           </QueryHelp>
 
+          <QueryHelp
+            title="Match alerts based on the time left until their silence expires"
+            operators={[">", "<"]}
+          >
+            <FilterExample example="@silence_ends_in&lt;1h">
+              Match silenced alerts where the silence expires in less than 1
+              hour.
+            </FilterExample>
+            <FilterExample example="@silence_ends_in&gt;1d">
+              Match silenced alerts where the silence expires in more than 1
+              day.
+            </FilterExample>
+          </QueryHelp>
+
+          <QueryHelp
+            title="Match alerts based on the number of active silences"
+            operators={["=", "!=", ">", "<", ">=", "<="]}
+          >
+            <FilterExample example="@silence_count&gt;1">
+              Match alerts silenced by more than one active silence.
+            </FilterExample>
+            <FilterExample example="@silence_count=0">
+              Match alerts without any active silence.
+            </FilterExample>
+          </QueryHelp>
+
           <QueryHelp title="Limit number of displayed alerts" operators={["="]}>
             <div className="text-warning">Value must be a number &gt;= 1.</div>
             <FilterExample example="@limit=10">
@@ -271,6 +525,106 @@ const Help = () => (
               Match alerts more recent than 10 hours and 30 minutes.
             </FilterExample>
           </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the time karma first collected them"
+            operators={[">", "<"]}
+          >
+            <FilterExample example="@first_seen_age&gt;2h">
+              Match alerts first seen by karma more than 2 hours ago.
+            </FilterExample>
+            <FilterExample example="@first_seen_age&lt;15m">
+              Match alerts first seen by karma less than 15 minutes ago.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the time they last changed state"
+            operators={[">", "<"]}
+          >
+            <FilterExample example="@state_changed&lt;5m">
+              Match alerts that fired, resolved or were suppressed less than 5
+              minutes ago.
+            </FilterExample>
+            <FilterExample example="@state_changed&gt;1h">
+              Match alerts that last changed state more than 1 hour ago, alerts
+              without any state change since karma started never match.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the time of day they started at"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@started_between=09:00-17:00">
+              Match alerts that started between 9:00 and 17:00.
+            </FilterExample>
+            <FilterExample example="@started_between=22:00-06:00">
+              Match alerts that started between 22:00 and 6:00 the next day.
+            </FilterExample>
+            <FilterExample example="@started_between!=09:00-17:00">
+              Match alerts that started outside of 9:00 - 17:00 hours.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the presence of an annotation"
+            operators={["=", "!="]}
+          >
+            <FilterExample example="@annotation_exists=runbook">
+              Match alerts with annotation <code>runbook</code>.
+            </FilterExample>
+            <FilterExample example="@annotation_exists!=runbook">
+              Match alerts without annotation <code>runbook</code>.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the value of an annotation"
+            operators={["=", "!=", "=~", "!~"]}
+          >
+            <FilterExample example="@annotation=summary:Disk full">
+              Match alerts with annotation <code>summary</code> equal to{" "}
+              <code>Disk full</code>.
+            </FilterExample>
+            <FilterExample example="@annotation=~summary:disk">
+              Match alerts with annotation <code>summary</code> matching regular
+              expression <code>/.*disk.*/</code>.
+            </FilterExample>
+            <FilterExample example="@annotation=summary:">
+              Match alerts with missing or empty annotation{" "}
+              <code>summary</code>.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the numeric value of an annotation"
+            operators={["="]}
+          >
+            <FilterExample example="@annotation_num=value&gt;90">
+              Match alerts with annotation <code>value</code> greater than 90.
+            </FilterExample>
+            <FilterExample example="@annotation_num=threshold&lt;=0.5">
+              Match alerts with annotation <code>threshold</code> less than or
+              equal to 0.5.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the number of links in annotations"
+            operators={["=", "!=", ">", "<", ">=", "<="]}
+          >
+            <FilterExample example="@link_count=0">
+              Match alerts without any annotation with a link, like a runbook.
+            </FilterExample>
+            <FilterExample example="@link_count&gt;1">
+              Match alerts with more than one distinct link in annotations.
+            </FilterExample>
+          </QueryHelp>
+          <QueryHelp
+            title="Match alerts based on the number of labels"
+            operators={["=", "!=", ">", "<", ">=", "<="]}
+          >
+            <FilterExample example="@label_count&gt;10">
+              Match alerts with more than 10 labels.
+            </FilterExample>
+            <FilterExample example="@label_count&lt;=3">
+              Match alerts with 3 or less labels.
+            </FilterExample>
+          </QueryHelp>
         </dl>
       }
     />
@@ -24,8 +24,12 @@ const APIAlert = PropTypes.exact({
   labels: PropTypes.object.isRequired,
   startsAt: PropTypes.string.isRequired,
   state: AlertState.isRequired,
+  fingerprint: PropTypes.string.isRequired,
   alertmanager: PropTypes.arrayOf(APIAlertAlertmanagerState).isRequired,
-  receiver: PropTypes.string.isRequired
+  receiver: PropTypes.string.isRequired,
+  flapping: PropTypes.bool.isRequired,
+  karmaFirstSeen: PropTypes.string.isRequired,
+  acknowledged: PropTypes.bool.isRequired
 });
 
 const APIGroup = PropTypes.exact({
@@ -44,7 +48,10 @@ const APIGroup = PropTypes.exact({
     annotations: PropTypes.arrayOf(Annotation).isRequired,
     labels: PropTypes.object.isRequired,
     silences: PropTypes.objectOf(PropTypes.arrayOf(PropTypes.string)).isRequired
-  }).isRequired
+  }).isRequired,
+  flapping: PropTypes.bool.isRequired,
+  truncatedAlerts: PropTypes.number.isRequired,
+  maintenanceActive: PropTypes.bool.isRequired
 });
 
 const APISilenceMatcher = PropTypes.exact({
@@ -10,6 +10,7 @@ const MockAlert = (annotations, labels, state) => ({
   labels: labels,
   startsAt: "2018-08-14T17:36:40.017867056Z",
   state: state,
+  fingerprint: "aae7a1432b5d2f1b",
   alertmanager: [
     {
       name: "default",
@@ -21,7 +22,10 @@ const MockAlert = (annotations, labels, state) => ({
       inhibitedBy: []
     }
   ],
-  receiver: "by-name"
+  receiver: "by-name",
+  flapping: false,
+  karmaFirstSeen: "2018-08-14T17:36:40.017867056Z",
+  acknowledged: false
 });
 
 const MockAlertGroup = (
@@ -48,7 +52,10 @@ const MockAlertGroup = (
     annotations: sharedAnnotations,
     labels: sharedLabels,
     silences: sharedSilences
-  }
+  },
+  flapping: false,
+  truncatedAlerts: 0,
+  maintenanceActive: false
 });
 
 const MockSilence = () => ({